@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/handlers"
+)
+
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	svc := db.NewDynamoDBClient()
+	handlerWithInjectedDeps := auth.Authenticate(auth.NewVerifierFromEnv(), handlers.UpdateEsppLot(
+		db.NewDynamoDBEsppLotRepository(svc),
+		db.NewDynamoDBEsppSaleRepository(svc),
+		db.NewDynamoDBEsppPlanRepository(svc),
+	))
+	return handlerWithInjectedDeps(ctx, request)
+}
+
+func main() {
+	lambda.Start(handler)
+}
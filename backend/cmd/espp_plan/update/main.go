@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/handlers"
+)
+
+func handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	handlerWithInjectedDeps := auth.Authenticate(auth.NewVerifierFromEnv(), handlers.UpdateEsppPlan(db.NewDynamoDBEsppPlanRepository(db.NewDynamoDBClient())))
+	return handlerWithInjectedDeps(ctx, request)
+}
+
+func main() {
+	lambda.Start(handler)
+}
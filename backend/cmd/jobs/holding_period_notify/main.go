@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/jobs"
+	"github.com/ljhurst/fife/pkg/notify"
+)
+
+// handler runs on an EventBridge schedule. Returning an error when some users
+// failed lets Lambda retry the run, which only revisits those users.
+func handler(ctx context.Context, _ events.CloudWatchEvent) (jobs.HoldingPeriodReport, error) {
+	svc := db.NewDynamoDBClient()
+	return jobs.NotifyHoldingPeriods(
+		ctx,
+		db.NewDynamoDBUserRepository(svc),
+		db.NewDynamoDBEsppLotRepository(svc),
+		db.NewDynamoDBEsppSaleRepository(svc),
+		notify.NewNotifierFromEnv(),
+		time.Now(),
+	)
+}
+
+func main() {
+	lambda.Start(handler)
+}
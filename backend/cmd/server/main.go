@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/ljhurst/fife/pkg/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	jwksFile := flag.String("jwks-file", "", "local JWKS file to verify tokens against instead of Cognito")
+	issuer := flag.String("issuer", "local", "expected token issuer when -jwks-file is set")
+	clientID := flag.String("client-id", "", "expected token client ID when -jwks-file is set")
+	quotesFile := flag.String("quotes-file", "", "local JSON file of quotes to serve instead of QUOTES_FILE or QUOTES_BASE_URL")
+	flag.Parse()
+
+	verifier, err := newVerifier(*jwksFile, *issuer, *clientID)
+	if err != nil {
+		slog.Error("Failed to load JWKS file", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	quoteProvider, err := newQuoteProvider(*quotesFile)
+	if err != nil {
+		slog.Error("Failed to load quotes file", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	router := server.NewRouter(server.Deps{
+		Verifier:         verifier,
+		UserRepo:         db.NewMemoryUserRepository(),
+		EsppLotRepo:      db.NewMemoryEsppLotRepository(),
+		EsppSaleRepo:     db.NewMemoryEsppSaleRepository(),
+		QuoteProvider:    quoteProvider,
+		PriceHistoryRepo: db.NewMemoryPriceHistoryRepository(),
+		EsppPlanRepo:     db.NewMemoryEsppPlanRepository(),
+	})
+
+	slog.Info("Starting local API server", slog.String("addr", *addr))
+	if err := http.ListenAndServe(*addr, router); err != nil {
+		slog.Error("Local API server stopped", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func newVerifier(jwksFile string, issuer string, clientID string) (auth.Verifier, error) {
+	if jwksFile == "" {
+		return auth.NewVerifierFromEnv(), nil
+	}
+
+	keys, err := auth.LoadKeySetFile(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewCognitoVerifier(auth.CognitoVerifierConfig{
+		Issuer:   issuer,
+		ClientID: clientID,
+		Keys:     keys,
+	}), nil
+}
+
+func newQuoteProvider(quotesFile string) (quotes.PriceProvider, error) {
+	cache := db.NewMemoryQuoteRepository()
+	if quotesFile == "" {
+		return quotes.NewProviderFromEnv(cache), nil
+	}
+
+	source, err := quotes.LoadFileProvider(quotesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotes.NewCachedProvider(cache, source, quotes.DefaultMaxAge), nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/handlers"
+)
+
+// handler is the user pool's post confirmation trigger. Cognito invokes it
+// directly, so there is no bearer token to check.
+func handler(ctx context.Context, event events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error) {
+	handlerWithInjectedDeps := handlers.CreateUserOnSignUp(db.NewDynamoDBUserRepository(db.NewDynamoDBClient()))
+	return handlerWithInjectedDeps(ctx, event)
+}
+
+func main() {
+	lambda.Start(handler)
+}
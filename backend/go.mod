@@ -3,14 +3,14 @@ module github.com/ljhurst/fife
 go 1.24.2
 
 require (
+	github.com/aws/aws-lambda-go v1.48.0
 	github.com/aws/aws-sdk-go v1.55.7
+	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.7.2
 )
 
 require (
-	github.com/aws/aws-lambda-go v1.48.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.1.0 // indirect
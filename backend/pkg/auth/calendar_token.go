@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// calendarTokenBytes is the amount of randomness in a calendar token.
+const calendarTokenBytes = 32
+
+// NewCalendarToken returns a random token for a calendar feed URL. Calendar
+// apps cannot send a bearer token, so the URL carries this token instead.
+func NewCalendarToken() (string, error) {
+	token := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashCalendarToken returns the hash that is stored in place of the token.
+func HashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CalendarTokenMatches reports whether token hashes to tokenHash. An empty
+// hash means the token was revoked, so nothing matches it.
+func CalendarTokenMatches(tokenHash string, token string) bool {
+	if tokenHash == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(tokenHash), []byte(HashCalendarToken(token))) == 1
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarToken(t *testing.T) {
+	token, err := NewCalendarToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, 43)
+
+	other, err := NewCalendarToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	tokenHash := HashCalendarToken(token)
+	assert.NotEqual(t, token, tokenHash)
+	assert.True(t, CalendarTokenMatches(tokenHash, token))
+	assert.False(t, CalendarTokenMatches(tokenHash, other))
+	assert.False(t, CalendarTokenMatches(tokenHash, ""))
+	assert.False(t, CalendarTokenMatches("", token))
+	assert.False(t, CalendarTokenMatches("", ""))
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeySet(t *testing.T) {
+	key := newTestKey(t)
+
+	data, err := MarshalKeySet(map[string]*rsa.PublicKey{testKid: &key.PublicKey})
+	assert.NoError(t, err)
+
+	keySet, err := ParseKeySet(data)
+	assert.NoError(t, err)
+
+	parsedKey, err := keySet.Key(testKid)
+	assert.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(parsedKey))
+
+	_, err = keySet.Key("missing")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestParseKeySetErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid JSON", data: `{`},
+		{name: "invalid modulus", data: `{"keys":[{"kid":"a","kty":"RSA","n":"!!","e":"AQAB"}]}`},
+		{name: "invalid exponent", data: `{"keys":[{"kid":"a","kty":"RSA","n":"AQAB","e":"AQ"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseKeySet([]byte(tc.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadKeySetFile(t *testing.T) {
+	key := newTestKey(t)
+
+	data, err := MarshalKeySet(map[string]*rsa.PublicKey{testKid: &key.PublicKey})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keySet, err := LoadKeySetFile(path)
+	assert.NoError(t, err)
+
+	_, err = keySet.Key(testKid)
+	assert.NoError(t, err)
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	firstKey := newTestKey(t)
+	secondKey := newTestKey(t)
+
+	keys := map[string]*rsa.PublicKey{"first": &firstKey.PublicKey}
+	var fetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+
+		data, err := MarshalKeySet(keys)
+		assert.NoError(t, err)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	keySet := NewRemoteKeySet(srv.URL, srv.Client())
+
+	_, err := keySet.Key("first")
+	assert.NoError(t, err)
+	_, err = keySet.Key("first")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// A rotated key is picked up once the refresh interval has passed.
+	keys["second"] = &secondKey.PublicKey
+
+	_, err = keySet.Key("second")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	keySet.lastFetched = time.Now().Add(-minRefreshInterval)
+
+	_, err = keySet.Key("second")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestRemoteKeySetFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := NewRemoteKeySet(srv.URL, srv.Client()).Key(testKid)
+	assert.Error(t, err)
+}
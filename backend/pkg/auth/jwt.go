@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	TokenUseID     = "id"
+	TokenUseAccess = "access"
+
+	// AdminGroup is the Cognito group of operators allowed to change data
+	// shared by every user, such as the price history.
+	AdminGroup = "admin"
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrInvalidClaims    = errors.New("invalid token claims")
+)
+
+// Claims holds the subset of Cognito ID and access token claims the API uses.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  string   `json:"aud,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	TokenUse  string   `json:"token_use"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	Email     string   `json:"email,omitempty"`
+	Username  string   `json:"cognito:username,omitempty"`
+	Groups    []string `json:"cognito:groups,omitempty"`
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type Verifier interface {
+	Verify(token string) (*Claims, error)
+}
+
+type CognitoVerifierConfig struct {
+	Issuer string
+	// ClientID is checked against the aud claim of ID tokens and the client_id
+	// claim of access tokens. It is skipped when empty.
+	ClientID string
+	Keys     KeyProvider
+	Now      func() time.Time
+}
+
+type CognitoVerifier struct {
+	config CognitoVerifierConfig
+}
+
+func NewCognitoVerifier(config CognitoVerifierConfig) *CognitoVerifier {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &CognitoVerifier{config: config}
+}
+
+// NewVerifierFromEnv builds a verifier for the Cognito user pool named by
+// COGNITO_USER_POOL_ID in AWS_REGION. COGNITO_ISSUER and COGNITO_JWKS_URL
+// override the derived issuer and key set location.
+func NewVerifierFromEnv() Verifier {
+	issuer := os.Getenv("COGNITO_ISSUER")
+	if issuer == "" {
+		region := os.Getenv("AWS_REGION")
+		poolID := os.Getenv("COGNITO_USER_POOL_ID")
+		if region == "" || poolID == "" {
+			return misconfiguredVerifier{err: errors.New("COGNITO_USER_POOL_ID and AWS_REGION must be set")}
+		}
+		issuer = fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, poolID)
+	}
+
+	jwksURL := os.Getenv("COGNITO_JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = issuer + "/.well-known/jwks.json"
+	}
+
+	return NewCognitoVerifier(CognitoVerifierConfig{
+		Issuer:   issuer,
+		ClientID: os.Getenv("COGNITO_CLIENT_ID"),
+		Keys:     sharedRemoteKeySet(jwksURL),
+	})
+}
+
+func (v *CognitoVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrMalformedToken, header.Alg)
+	}
+
+	key, err := v.config.Keys.Key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func (v *CognitoVerifier) validateClaims(claims *Claims) error {
+	if claims.ExpiresAt == 0 || !v.config.Now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return ErrTokenExpired
+	}
+
+	if claims.Issuer != v.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidClaims)
+	}
+
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidClaims)
+	}
+
+	switch claims.TokenUse {
+	case TokenUseID:
+		if v.config.ClientID != "" && claims.Audience != v.config.ClientID {
+			return fmt.Errorf("%w: unexpected audience", ErrInvalidClaims)
+		}
+	case TokenUseAccess:
+		if v.config.ClientID != "" && claims.ClientID != v.config.ClientID {
+			return fmt.Errorf("%w: unexpected client", ErrInvalidClaims)
+		}
+	default:
+		return fmt.Errorf("%w: unexpected token use %q", ErrInvalidClaims, claims.TokenUse)
+	}
+
+	return nil
+}
+
+// SignToken issues an RS256 token for the given claims. It exists so tests and
+// the local server can mint tokens against a local key set.
+func SignToken(key *rsa.PrivateKey, kid string, claims Claims) (string, error) {
+	header, err := encodeSegment(tokenHeader{Alg: "RS256", Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256([]byte(header + "." + payload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+type misconfiguredVerifier struct {
+	err error
+}
+
+func (v misconfiguredVerifier) Verify(_ string) (*Claims, error) {
+	return nil, v.err
+}
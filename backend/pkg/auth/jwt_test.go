@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIssuer   = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test"
+	testClientID = "client123"
+	testKid      = "kid1"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	return key
+}
+
+func newTestVerifier(key *rsa.PrivateKey) *CognitoVerifier {
+	return NewCognitoVerifier(CognitoVerifierConfig{
+		Issuer:   testIssuer,
+		ClientID: testClientID,
+		Keys:     NewStaticKeySet(map[string]*rsa.PublicKey{testKid: &key.PublicKey}),
+		Now:      func() time.Time { return testNow },
+	})
+}
+
+func validIDClaims() Claims {
+	return Claims{
+		Subject:   "user123",
+		Issuer:    testIssuer,
+		Audience:  testClientID,
+		TokenUse:  TokenUseID,
+		ExpiresAt: testNow.Add(time.Hour).Unix(),
+		IssuedAt:  testNow.Add(-time.Minute).Unix(),
+		Email:     "user@example.com",
+	}
+}
+
+func TestCognitoVerifierVerify(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+
+	accessClaims := validIDClaims()
+	accessClaims.TokenUse = TokenUseAccess
+	accessClaims.Audience = ""
+	accessClaims.ClientID = testClientID
+
+	testCases := []struct {
+		name          string
+		signingKey    *rsa.PrivateKey
+		kid           string
+		claims        func() Claims
+		expectedError error
+	}{
+		{
+			name:       "valid ID token",
+			signingKey: key,
+			kid:        testKid,
+			claims:     validIDClaims,
+		},
+		{
+			name:       "valid access token",
+			signingKey: key,
+			kid:        testKid,
+			claims:     func() Claims { return accessClaims },
+		},
+		{
+			name:       "expired",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := validIDClaims()
+				claims.ExpiresAt = testNow.Add(-time.Second).Unix()
+				return claims
+			},
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name:       "wrong issuer",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := validIDClaims()
+				claims.Issuer = "https://evil.example.com"
+				return claims
+			},
+			expectedError: ErrInvalidClaims,
+		},
+		{
+			name:       "wrong audience",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := validIDClaims()
+				claims.Audience = "other-client"
+				return claims
+			},
+			expectedError: ErrInvalidClaims,
+		},
+		{
+			name:       "wrong access token client",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := accessClaims
+				claims.ClientID = "other-client"
+				return claims
+			},
+			expectedError: ErrInvalidClaims,
+		},
+		{
+			name:       "unexpected token use",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := validIDClaims()
+				claims.TokenUse = "refresh"
+				return claims
+			},
+			expectedError: ErrInvalidClaims,
+		},
+		{
+			name:       "missing subject",
+			signingKey: key,
+			kid:        testKid,
+			claims: func() Claims {
+				claims := validIDClaims()
+				claims.Subject = ""
+				return claims
+			},
+			expectedError: ErrInvalidClaims,
+		},
+		{
+			name:          "signed by another key",
+			signingKey:    otherKey,
+			kid:           testKid,
+			claims:        validIDClaims,
+			expectedError: ErrInvalidSignature,
+		},
+		{
+			name:          "unknown key ID",
+			signingKey:    key,
+			kid:           "kid2",
+			claims:        validIDClaims,
+			expectedError: ErrUnknownKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectedClaims := tc.claims()
+			token, err := SignToken(tc.signingKey, tc.kid, expectedClaims)
+			assert.NoError(t, err)
+
+			claims, err := newTestVerifier(key).Verify(token)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, claims)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &expectedClaims, claims)
+			}
+		})
+	}
+}
+
+func TestCognitoVerifierRejectsMalformedTokens(t *testing.T) {
+	key := newTestKey(t)
+	verifier := newTestVerifier(key)
+
+	token, err := SignToken(key, testKid, validIDClaims())
+	assert.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	unsignedHeader, err := encodeSegment(tokenHeader{Alg: "none", Kid: testKid})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "two segments", token: parts[0] + "." + parts[1]},
+		{name: "garbage header", token: "!!!." + parts[1] + "." + parts[2]},
+		{name: "alg none", token: unsignedHeader + "." + parts[1] + "."},
+		{name: "garbage signature", token: parts[0] + "." + parts[1] + ".!!!"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := verifier.Verify(tc.token)
+
+			assert.ErrorIs(t, err, ErrMalformedToken)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestNewVerifierFromEnv(t *testing.T) {
+	t.Setenv("COGNITO_ISSUER", "")
+	t.Setenv("COGNITO_USER_POOL_ID", "")
+
+	claims, err := NewVerifierFromEnv().Verify("token")
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("COGNITO_USER_POOL_ID", "us-east-1_test")
+	t.Setenv("COGNITO_CLIENT_ID", testClientID)
+
+	verifier, ok := NewVerifierFromEnv().(*CognitoVerifier)
+	assert.True(t, ok)
+	assert.Equal(t, testIssuer, verifier.config.Issuer)
+	assert.Equal(t, testClientID, verifier.config.ClientID)
+	assert.Equal(t, testIssuer+"/.well-known/jwks.json", verifier.config.Keys.(*RemoteKeySet).url)
+}
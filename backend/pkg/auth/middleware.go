@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type contextKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// UserIDFromContext returns the caller's user ID, which is the sub claim of
+// their Cognito token.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims.Subject == "" {
+		return "", false
+	}
+
+	return claims.Subject, true
+}
+
+// IsAdmin reports whether the caller is in the Cognito admin group.
+func IsAdmin(ctx context.Context) bool {
+	claims, ok := ClaimsFromContext(ctx)
+	return ok && slices.Contains(claims.Groups, AdminGroup)
+}
+
+// Authenticate rejects requests without a valid bearer token and makes the
+// verified claims available to next through the context.
+func Authenticate(verifier Verifier, next func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)) func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		token, ok := bearerToken(request.Headers)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			slog.Warn("Rejected token", slog.Any("error", err))
+			return utils.UnauthorizedError()
+		}
+
+		return next(WithClaims(ctx, claims), request)
+	}
+}
+
+func bearerToken(headers map[string]string) (string, bool) {
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Authorization") {
+			continue
+		}
+
+		scheme, token, found := strings.Cut(value, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", false
+		}
+
+		return strings.TrimSpace(token), true
+	}
+
+	return "", false
+}
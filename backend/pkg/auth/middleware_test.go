@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	key := newTestKey(t)
+	verifier := newTestVerifier(key)
+
+	token, err := SignToken(key, testKid, validIDClaims())
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name               string
+		headers            map[string]string
+		expectedStatusCode int
+		expectedUserID     string
+	}{
+		{
+			name:               "valid token",
+			headers:            map[string]string{"Authorization": "Bearer " + token},
+			expectedStatusCode: 200,
+			expectedUserID:     "user123",
+		},
+		{
+			name:               "lowercase header",
+			headers:            map[string]string{"authorization": "bearer " + token},
+			expectedStatusCode: 200,
+			expectedUserID:     "user123",
+		},
+		{
+			name:               "missing header",
+			headers:            map[string]string{},
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "wrong scheme",
+			headers:            map[string]string{"Authorization": "Basic " + token},
+			expectedStatusCode: 401,
+		},
+		{
+			name:               "invalid token",
+			headers:            map[string]string{"Authorization": "Bearer not-a-token"},
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				userID, ok := UserIDFromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedUserID, userID)
+
+				return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+			}
+
+			response, err := Authenticate(verifier, next)(context.Background(), events.APIGatewayProxyRequest{Headers: tc.headers})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedStatusCode == 401 {
+				assert.Equal(t, `{"error":"Unauthorized"}`, response.Body)
+			}
+		})
+	}
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	_, ok := UserIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = UserIDFromContext(WithClaims(context.Background(), &Claims{}))
+	assert.False(t, ok)
+
+	userID, ok := UserIDFromContext(WithClaims(context.Background(), &Claims{Subject: "user123"}))
+	assert.True(t, ok)
+	assert.Equal(t, "user123", userID)
+}
+
+func TestIsAdmin(t *testing.T) {
+	assert.False(t, IsAdmin(context.Background()))
+	assert.False(t, IsAdmin(WithClaims(context.Background(), &Claims{Subject: "user123", Groups: []string{"readers"}})))
+	assert.True(t, IsAdmin(WithClaims(context.Background(), &Claims{Subject: "user123", Groups: []string{"readers", AdminGroup}})))
+}
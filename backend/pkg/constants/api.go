@@ -3,4 +3,43 @@ package constants
 const (
 	PathUserID = "userId"
 	PathLotID  = "lotId"
+	PathSaleID = "saleId"
+	PathTicker = "ticker"
+	PathPlanID = "planId"
+)
+
+const (
+	QueryMarketPrice      = "marketPrice"
+	QueryLimit            = "limit"
+	QueryCursor           = "cursor"
+	QueryPurchaseDateFrom = "purchaseDateFrom"
+	QueryPurchaseDateTo   = "purchaseDateTo"
+	QueryOrder            = "order"
+	QueryDryRun           = "dryRun"
+	QueryFormat           = "format"
+	QueryDate             = "date"
+	QueryTicker           = "ticker"
+	QueryMarketPrices     = "marketPrices"
+	QueryEmployer         = "employer"
+	QueryGrantDate        = "grantDate"
+	QueryPurchaseDate     = "purchaseDate"
+	QueryOfferStartPrice  = "offerStartPrice"
+	QueryToken            = "token"
+	QueryMode             = "mode"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	MaxImportRows = 1000
+
+	// MaxPriceHistoryRows is about forty years of trading days.
+	MaxPriceHistoryRows = 10000
+
+	FormatCSV  = "csv"
+	FormatJSON = "json"
 )
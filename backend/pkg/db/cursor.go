@@ -0,0 +1,58 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor turns a LastEvaluatedKey into an opaque string clients can hand
+// back to fetch the next page. Every key attribute of the tables is a string.
+func encodeCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	values := map[string]string{}
+	for name, value := range key {
+		if value.S == nil {
+			return "", errors.New("cursor key attributes must be strings")
+		}
+		values[name] = *value.S
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	key := map[string]*dynamodb.AttributeValue{}
+	for name, value := range values {
+		key[name] = &dynamodb.AttributeValue{S: aws.String(value)}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{
+		"id":           {S: aws.String("lot123")},
+		"userId":       {S: aws.String("user123")},
+		"purchaseDate": {S: aws.String("2023-06-30")},
+	}
+
+	cursor, err := encodeCursor(key)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cursor)
+
+	decoded, err := decodeCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, key, decoded)
+}
+
+func TestEncodeCursorEmptyKey(t *testing.T) {
+	cursor, err := encodeCursor(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cursor)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cursor        string
+		expectedError error
+	}{
+		{name: "empty cursor", cursor: "", expectedError: nil},
+		{name: "not base64", cursor: "%%%", expectedError: ErrInvalidCursor},
+		{name: "not json", cursor: "bm90IGpzb24", expectedError: ErrInvalidCursor},
+		{name: "empty object", cursor: "e30", expectedError: ErrInvalidCursor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := decodeCursor(tc.cursor)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Nil(t, key)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package db
 
 import (
+	"errors"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
@@ -42,13 +45,73 @@ func GetUser(svc dynamodbiface.DynamoDBAPI, userID string) (*models.User, error)
 	return user, nil
 }
 
-func UpdateUserSettings(svc dynamodbiface.DynamoDBAPI, userID string, settings models.UserSettings) (*models.User, error) {
+// CreateUser stores the user unless one with the same ID already exists, in
+// which case it returns nil.
+func CreateUser(svc dynamodbiface.DynamoDBAPI, user models.User) (*models.User, error) {
+	av, err := dynamodbattribute.MarshalMap(user)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("userId"))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:                aws.String(TableName),
+		Item:                     av,
+		ExpressionAttributeNames: expr.Names(),
+		ConditionExpression:      expr.Condition(),
+	}
+
+	_, err = svc.PutItem(input)
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ReplaceUser writes the user as it is, keeping its timestamps and replacing
+// any user with the same ID.
+func ReplaceUser(svc dynamodbiface.DynamoDBAPI, user models.User) error {
+	av, err := dynamodbattribute.MarshalMap(user)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(TableName),
+		Item:      av,
+	}
+
+	_, err = svc.PutItem(input)
+	return err
+}
+
+// UpdateUserSettings replaces the user's settings, creating the user when they
+// do not exist yet, such as when the update beats the sign-up trigger. With an expectedVersion, the write only happens when the
+// stored user is at that version, and it returns nil when they are not.
+func UpdateUserSettings(svc dynamodbiface.DynamoDBAPI, userID string, settings models.UserSettings, expectedVersion *int64) (*models.User, error) {
 	currentTime := utils.GetCurrentTimeUTC()
 
 	update := expression.Set(expression.Name("settings"), expression.Value(settings))
+	update = update.Set(expression.Name("createdAt"), expression.Name("createdAt").IfNotExists(expression.Value(currentTime)))
 	update = update.Set(expression.Name("updatedAt"), expression.Value(currentTime))
+	update = setNextVersion(update)
+
+	builder := expression.NewBuilder().WithUpdate(update)
+	if expectedVersion != nil {
+		builder = builder.WithCondition(versionCondition("userId", *expectedVersion))
+	}
 
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	expr, err := builder.Build()
 	if err != nil {
 		return nil, err
 	}
@@ -63,11 +126,16 @@ func UpdateUserSettings(svc dynamodbiface.DynamoDBAPI, userID string, settings m
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
 		ReturnValues:              aws.String("ALL_NEW"),
 	}
 
 	result, err := svc.UpdateItem(input)
 	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, nil
+		}
 		return nil, err
 	}
 
@@ -79,3 +147,102 @@ func UpdateUserSettings(svc dynamodbiface.DynamoDBAPI, userID string, settings m
 
 	return updatedUser, nil
 }
+
+// SetCalendarTokenHash stores the hash of the user's calendar token. An empty
+// hash removes it, which revokes the token. It returns false when the user
+// does not exist, rather than creating them.
+func SetCalendarTokenHash(svc dynamodbiface.DynamoDBAPI, userID string, tokenHash string) (bool, error) {
+	update := expression.Set(expression.Name("updatedAt"), expression.Value(utils.GetCurrentTimeUTC()))
+	if tokenHash == "" {
+		update = update.Remove(expression.Name("calendarTokenHash"))
+	} else {
+		update = update.Set(expression.Name("calendarTokenHash"), expression.Value(tokenHash))
+	}
+
+	return updateExistingUser(svc, userID, update)
+}
+
+// ListUsers scans every user. It is meant for scheduled jobs, not requests.
+func ListUsers(svc dynamodbiface.DynamoDBAPI) ([]*models.User, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(TableName),
+	}
+
+	users := []*models.User{}
+	for {
+		result, err := svc.Scan(input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []*models.User{}
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return users, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// SetNotificationCheckpoints records how far the notification jobs got for the
+// user. updatedAt is left alone because it tracks changes the user makes. It
+// returns false when the user has been deleted since they were listed.
+func SetNotificationCheckpoints(svc dynamodbiface.DynamoDBAPI, userID string, checkpoints models.NotificationCheckpoints) (bool, error) {
+	update := expression.Set(expression.Name("notificationCheckpoints"), expression.Value(checkpoints))
+
+	return updateExistingUser(svc, userID, update)
+}
+
+// updateExistingUser applies the update only when the user exists, so a write
+// racing a delete does not leave behind a user with no settings.
+func updateExistingUser(svc dynamodbiface.DynamoDBAPI, userID string, update expression.UpdateBuilder) (bool, error) {
+	condition := expression.AttributeExists(expression.Name("userId"))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"userId": {
+				S: aws.String(userID),
+			},
+		},
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	}
+
+	_, err = svc.UpdateItem(input)
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func DeleteUser(svc dynamodbiface.DynamoDBAPI, userID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"userId": {
+				S: aws.String(userID),
+			},
+		},
+	}
+
+	_, err := svc.DeleteItem(input)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/ljhurst/fife/pkg/models"
@@ -17,6 +18,14 @@ type mockDynamoDBClient struct {
 	getItemError     error
 	updateItemOutput *dynamodb.UpdateItemOutput
 	updateItemError  error
+	updateItemInput  *dynamodb.UpdateItemInput
+	scanOutputs      []*dynamodb.ScanOutput
+	scanError        error
+	scanInputs       []*dynamodb.ScanInput
+	putItemError     error
+	putItemInput     *dynamodb.PutItemInput
+	deleteItemError  error
+	deleteItemInput  *dynamodb.DeleteItemInput
 }
 
 func (m *mockDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
@@ -42,9 +51,48 @@ func (m *mockDynamoDBClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynam
 		return nil, errors.New("missing or invalid userId key")
 	}
 
+	m.updateItemInput = input
+
 	return m.updateItemOutput, m.updateItemError
 }
 
+func (m *mockDynamoDBClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	if *input.TableName != TableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	copied := *input
+	m.scanInputs = append(m.scanInputs, &copied)
+
+	if m.scanError != nil {
+		return nil, m.scanError
+	}
+
+	output := m.scanOutputs[0]
+	m.scanOutputs = m.scanOutputs[1:]
+	return output, nil
+}
+
+func (m *mockDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName != TableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.putItemInput = input
+
+	return &dynamodb.PutItemOutput{}, m.putItemError
+}
+
+func (m *mockDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	if *input.TableName != TableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.deleteItemInput = input
+
+	return &dynamodb.DeleteItemOutput{}, m.deleteItemError
+}
+
 func TestGetUser(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -140,14 +188,17 @@ func TestGetUser(t *testing.T) {
 }
 
 func TestUpdateUserSettings(t *testing.T) {
+	version := int64(3)
+
 	testCases := []struct {
-		name          string
-		userID        string
-		settings      models.UserSettings
-		mockOutput    *dynamodb.UpdateItemOutput
-		mockError     error
-		expectedUser  *models.User
-		expectedError bool
+		name            string
+		userID          string
+		settings        models.UserSettings
+		expectedVersion *int64
+		mockOutput      *dynamodb.UpdateItemOutput
+		mockError       error
+		expectedUser    *models.User
+		expectedError   bool
 	}{
 		{
 			name:   "Success",
@@ -167,6 +218,7 @@ func TestUpdateUserSettings(t *testing.T) {
 							"paychecksPerYear": {N: aws.String("24")},
 						}},
 					}},
+					"version":   {N: aws.String("4")},
 					"createdAt": {S: aws.String("2023-01-01T00:00:00Z")},
 					"updatedAt": {S: aws.String("2023-07-03T12:00:00Z")},
 				},
@@ -180,11 +232,33 @@ func TestUpdateUserSettings(t *testing.T) {
 						PaychecksPerYear: 24,
 					},
 				},
+				Version:   4,
 				CreatedAt: "2023-01-01T00:00:00Z",
 				UpdatedAt: "2023-07-03T12:00:00Z",
 			},
 			expectedError: false,
 		},
+		{
+			name:            "Expected Version",
+			userID:          "user123",
+			settings:        models.UserSettings{Finance: models.UserFinanceSettings{PaychecksPerYear: 24}},
+			expectedVersion: &version,
+			mockOutput: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"userId":  {S: aws.String("user123")},
+					"version": {N: aws.String("4")},
+				},
+			},
+			expectedUser: &models.User{UserID: "user123", Version: 4},
+		},
+		{
+			name:            "Version Mismatch",
+			userID:          "user123",
+			settings:        models.UserSettings{Finance: models.UserFinanceSettings{PaychecksPerYear: 24}},
+			expectedVersion: &version,
+			mockError:       awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+			expectedUser:    nil,
+		},
 		{
 			name:   "DynamoDB Error",
 			userID: "user123",
@@ -232,7 +306,7 @@ func TestUpdateUserSettings(t *testing.T) {
 				updateItemError:  tc.mockError,
 			}
 
-			user, err := UpdateUserSettings(mockSvc, tc.userID, tc.settings)
+			user, err := UpdateUserSettings(mockSvc, tc.userID, tc.settings, tc.expectedVersion)
 
 			if tc.expectedError {
 				assert.Error(t, err)
@@ -240,6 +314,253 @@ func TestUpdateUserSettings(t *testing.T) {
 				assert.NoError(t, err)
 			}
 			assert.Equal(t, tc.expectedUser, user)
+
+			names := []string{}
+			for _, name := range mockSvc.updateItemInput.ExpressionAttributeNames {
+				names = append(names, *name)
+			}
+			assert.Contains(t, names, "version")
+			assert.Contains(t, names, "createdAt")
+			assert.Contains(t, *mockSvc.updateItemInput.UpdateExpression, "if_not_exists")
+			if tc.expectedVersion == nil {
+				assert.Nil(t, mockSvc.updateItemInput.ConditionExpression)
+			} else {
+				assert.Contains(t, names, "userId")
+				assert.NotNil(t, mockSvc.updateItemInput.ConditionExpression)
+			}
+		})
+	}
+}
+
+func TestSetCalendarTokenHash(t *testing.T) {
+	testCases := []struct {
+		name            string
+		tokenHash       string
+		mockError       error
+		expectedClause  string
+		expectedValues  int
+		expectedError   bool
+		expectedMissing bool
+	}{
+		{
+			name:           "Set",
+			tokenHash:      "abc123",
+			expectedClause: "SET",
+			expectedValues: 2,
+		},
+		{
+			name:           "Revoke",
+			tokenHash:      "",
+			expectedClause: "REMOVE",
+			expectedValues: 1,
+		},
+		{
+			name:            "Deleted User",
+			tokenHash:       "abc123",
+			mockError:       awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+			expectedClause:  "SET",
+			expectedValues:  2,
+			expectedMissing: true,
+		},
+		{
+			name:          "DynamoDB Error",
+			tokenHash:     "abc123",
+			mockError:     errors.New("dynamodb error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockDynamoDBClient{
+				updateItemOutput: &dynamodb.UpdateItemOutput{},
+				updateItemError:  tc.mockError,
+			}
+
+			found, err := SetCalendarTokenHash(mockSvc, "user123", tc.tokenHash)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, !tc.expectedMissing, found)
+			assert.Equal(t, "attribute_exists (#0)", *mockSvc.updateItemInput.ConditionExpression)
+
+			names := []string{}
+			for _, name := range mockSvc.updateItemInput.ExpressionAttributeNames {
+				names = append(names, *name)
+			}
+			assert.ElementsMatch(t, []string{"calendarTokenHash", "updatedAt", "userId"}, names)
+			assert.Contains(t, *mockSvc.updateItemInput.UpdateExpression, tc.expectedClause)
+			assert.Len(t, mockSvc.updateItemInput.ExpressionAttributeValues, tc.expectedValues)
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	lastKey := map[string]*dynamodb.AttributeValue{"userId": {S: aws.String("user123")}}
+
+	testCases := []struct {
+		name          string
+		mockOutputs   []*dynamodb.ScanOutput
+		mockError     error
+		expectedUsers []*models.User
+		expectedScans int
+		expectedError bool
+	}{
+		{
+			name: "Success Across Pages",
+			mockOutputs: []*dynamodb.ScanOutput{
+				{
+					Items: []map[string]*dynamodb.AttributeValue{
+						{"userId": {S: aws.String("user123")}},
+					},
+					LastEvaluatedKey: lastKey,
+				},
+				{
+					Items: []map[string]*dynamodb.AttributeValue{
+						{
+							"userId": {S: aws.String("user456")},
+							"notificationCheckpoints": {M: map[string]*dynamodb.AttributeValue{
+								"holdingPeriod": {S: aws.String("2024-06-30")},
+							}},
+						},
+					},
+				},
+			},
+			expectedUsers: []*models.User{
+				{UserID: "user123"},
+				{UserID: "user456", NotificationCheckpoints: &models.NotificationCheckpoints{HoldingPeriod: "2024-06-30"}},
+			},
+			expectedScans: 2,
+		},
+		{
+			name:          "DynamoDB Error",
+			mockError:     errors.New("dynamodb error"),
+			expectedScans: 1,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockDynamoDBClient{
+				scanOutputs: tc.mockOutputs,
+				scanError:   tc.mockError,
+			}
+
+			users, err := ListUsers(mockSvc)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, users)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedUsers, users)
+				assert.Equal(t, lastKey, mockSvc.scanInputs[1].ExclusiveStartKey)
+			}
+			assert.Len(t, mockSvc.scanInputs, tc.expectedScans)
+		})
+	}
+}
+
+func TestSetNotificationCheckpoints(t *testing.T) {
+	mockSvc := &mockDynamoDBClient{updateItemOutput: &dynamodb.UpdateItemOutput{}}
+
+	found, err := SetNotificationCheckpoints(mockSvc, "user123", models.NotificationCheckpoints{HoldingPeriod: "2024-06-30"})
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "SET #1 = :0\n", *mockSvc.updateItemInput.UpdateExpression)
+	assert.Equal(t, "attribute_exists (#0)", *mockSvc.updateItemInput.ConditionExpression)
+	assert.Equal(t, "userId", *mockSvc.updateItemInput.ExpressionAttributeNames["#0"])
+	assert.Equal(t, "notificationCheckpoints", *mockSvc.updateItemInput.ExpressionAttributeNames["#1"])
+	assert.Equal(t, "2024-06-30", *mockSvc.updateItemInput.ExpressionAttributeValues[":0"].M["holdingPeriod"].S)
+
+	mockSvc = &mockDynamoDBClient{updateItemError: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)}
+	found, err = SetNotificationCheckpoints(mockSvc, "user123", models.NotificationCheckpoints{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	mockSvc = &mockDynamoDBClient{updateItemError: errors.New("dynamodb error")}
+	_, err = SetNotificationCheckpoints(mockSvc, "user123", models.NotificationCheckpoints{})
+	assert.Error(t, err)
+}
+
+func TestCreateUser(t *testing.T) {
+	user := models.User{
+		UserID:    "user123",
+		Settings:  models.UserSettings{Finance: models.UserFinanceSettings{PaychecksPerYear: 26}},
+		CreatedAt: "2023-01-01T00:00:00Z",
+		UpdatedAt: "2023-01-01T00:00:00Z",
+	}
+
+	testCases := []struct {
+		name          string
+		mockError     error
+		expectedUser  *models.User
+		expectedError bool
+	}{
+		{
+			name:         "Success",
+			expectedUser: &user,
+		},
+		{
+			name:         "Already Exists",
+			mockError:    awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+			expectedUser: nil,
+		},
+		{
+			name:          "DynamoDB Error",
+			mockError:     errors.New("dynamodb error"),
+			expectedUser:  nil,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockDynamoDBClient{putItemError: tc.mockError}
+
+			createdUser, err := CreateUser(mockSvc, user)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedUser, createdUser)
+			assert.Equal(t, "attribute_not_exists (#0)", *mockSvc.putItemInput.ConditionExpression)
+			assert.Equal(t, "userId", *mockSvc.putItemInput.ExpressionAttributeNames["#0"])
+			assert.Equal(t, "user123", *mockSvc.putItemInput.Item["userId"].S)
 		})
 	}
 }
+
+func TestDeleteUser(t *testing.T) {
+	mockSvc := &mockDynamoDBClient{}
+	assert.NoError(t, DeleteUser(mockSvc, "user123"))
+	assert.Equal(t, "user123", *mockSvc.deleteItemInput.Key["userId"].S)
+
+	mockSvc = &mockDynamoDBClient{deleteItemError: errors.New("dynamodb error")}
+	assert.Error(t, DeleteUser(mockSvc, "user123"))
+}
+
+func TestReplaceUser(t *testing.T) {
+	user := models.User{
+		UserID:            "user123",
+		CalendarTokenHash: "hash",
+		CreatedAt:         "2023-01-01T00:00:00Z",
+		UpdatedAt:         "2023-02-01T00:00:00Z",
+	}
+
+	mockSvc := &mockDynamoDBClient{}
+	assert.NoError(t, ReplaceUser(mockSvc, user))
+	assert.Nil(t, mockSvc.putItemInput.ConditionExpression)
+	assert.Equal(t, "hash", *mockSvc.putItemInput.Item["calendarTokenHash"].S)
+	assert.Equal(t, "2023-01-01T00:00:00Z", *mockSvc.putItemInput.Item["createdAt"].S)
+
+	mockSvc = &mockDynamoDBClient{putItemError: errors.New("dynamodb error")}
+	assert.Error(t, ReplaceUser(mockSvc, user))
+}
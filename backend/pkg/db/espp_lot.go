@@ -1,17 +1,39 @@
 package db
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
 )
 
 const (
 	EsppLotsTableName = "fife-espp-lots"
+
+	// EsppLotsByPurchaseDateIndex is keyed on userId with purchaseDate as the
+	// sort key, so listings can be ranged and ordered by purchase date.
+	EsppLotsByPurchaseDateIndex = "userId-purchaseDate-index"
+
+	// batchWriteSize is the most items DynamoDB accepts in one BatchWriteItem.
+	batchWriteSize        = 25
+	maxBatchWriteAttempts = 5
 )
 
+// batchWriteBackoff is how long to wait before retrying unprocessed items.
+// Tests replace it to avoid sleeping.
+var batchWriteBackoff = func(attempt int) time.Duration {
+	return time.Duration(50<<attempt) * time.Millisecond
+}
+
 func CreateEsppLot(svc dynamodbiface.DynamoDBAPI, lotInput models.EsppLotInput) (*models.EsppLot, error) {
 	lot := models.NewEsppLot(lotInput)
 
@@ -33,6 +55,166 @@ func CreateEsppLot(svc dynamodbiface.DynamoDBAPI, lotInput models.EsppLotInput)
 	return lot, nil
 }
 
+// BatchCreateEsppLots writes the lots in chunks with BatchWriteItem, retrying
+// unprocessed items. The returned slice lines up with lotInputs; when an error
+// stops the import, lots that were not written are left nil.
+func BatchCreateEsppLots(svc dynamodbiface.DynamoDBAPI, lotInputs []models.EsppLotInput) ([]*models.EsppLot, error) {
+	created := make([]*models.EsppLot, len(lotInputs))
+
+	for start := 0; start < len(lotInputs); start += batchWriteSize {
+		end := min(start+batchWriteSize, len(lotInputs))
+
+		chunk := make([]*models.EsppLot, 0, end-start)
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, lotInput := range lotInputs[start:end] {
+			lot := models.NewEsppLot(lotInput)
+
+			av, err := dynamodbattribute.MarshalMap(lot)
+			if err != nil {
+				return created, err
+			}
+
+			chunk = append(chunk, lot)
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: av}})
+		}
+
+		if err := batchWrite(svc, EsppLotsTableName, requests); err != nil {
+			return created, err
+		}
+
+		copy(created[start:end], chunk)
+	}
+
+	return created, nil
+}
+
+// batchWrite sends one BatchWriteItem chunk to tableName, retrying
+// unprocessed items with backoff.
+func batchWrite(svc dynamodbiface.DynamoDBAPI, tableName string, requests []*dynamodb.WriteRequest) error {
+	pending := map[string][]*dynamodb.WriteRequest{tableName: requests}
+
+	for attempt := 0; attempt < maxBatchWriteAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(batchWriteBackoff(attempt))
+		}
+
+		result, err := svc.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: pending})
+		if err != nil {
+			return err
+		}
+
+		if len(result.UnprocessedItems[tableName]) == 0 {
+			return nil
+		}
+		pending = result.UnprocessedItems
+	}
+
+	return fmt.Errorf("%d items in %s still unprocessed after %d attempts", len(pending[tableName]), tableName, maxBatchWriteAttempts)
+}
+
+// batchPutItems writes marshalled items in chunks with BatchWriteItem,
+// replacing any item with the same key.
+func batchPutItems(svc dynamodbiface.DynamoDBAPI, tableName string, items []map[string]*dynamodb.AttributeValue) error {
+	for start := 0; start < len(items); start += batchWriteSize {
+		end := min(start+batchWriteSize, len(items))
+
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, item := range items[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+		}
+
+		if err := batchWrite(svc, tableName, requests); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchDelete deletes the items with the given ids from a table keyed by id.
+func batchDelete(svc dynamodbiface.DynamoDBAPI, tableName string, ids []string) error {
+	for start := 0; start < len(ids); start += batchWriteSize {
+		end := min(start+batchWriteSize, len(ids))
+
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, id := range ids[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{
+				Key: map[string]*dynamodb.AttributeValue{
+					"id": {
+						S: aws.String(id),
+					},
+				},
+			}})
+		}
+
+		if err := batchWrite(svc, tableName, requests); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteAllByUserID pages through the userId-index of a table keyed by id and
+// deletes each page as it goes. It returns how many items were deleted.
+func deleteAllByUserID(svc dynamodbiface.DynamoDBAPI, tableName string, userID string) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(tableName),
+		IndexName: aws.String("userId-index"),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"userId": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(userID),
+					},
+				},
+			},
+		},
+		ProjectionExpression: aws.String("id"),
+	}
+
+	deleted := 0
+	for {
+		result, err := svc.Query(input)
+		if err != nil {
+			return deleted, err
+		}
+
+		ids := make([]string, 0, len(result.Items))
+		for _, item := range result.Items {
+			if id, ok := item["id"]; ok && id.S != nil {
+				ids = append(ids, *id.S)
+			}
+		}
+
+		if err := batchDelete(svc, tableName, ids); err != nil {
+			return deleted, err
+		}
+		deleted += len(ids)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return deleted, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// BatchPutEsppLots writes the lots as they are, keeping their IDs and
+// timestamps and replacing any lot with the same ID.
+func BatchPutEsppLots(svc dynamodbiface.DynamoDBAPI, lots []models.EsppLot) error {
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(lots))
+	for _, lot := range lots {
+		av, err := dynamodbattribute.MarshalMap(lot)
+		if err != nil {
+			return err
+		}
+		items = append(items, av)
+	}
+
+	return batchPutItems(svc, EsppLotsTableName, items)
+}
+
 func GetEsppLot(svc dynamodbiface.DynamoDBAPI, id string) (*models.EsppLot, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(EsppLotsTableName),
@@ -77,18 +259,225 @@ func GetEsppLotsByUserID(svc dynamodbiface.DynamoDBAPI, userID string) ([]*model
 		},
 	}
 
-	result, err := svc.Query(input)
+	lots := []*models.EsppLot{}
+	for {
+		result, err := svc.Query(input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []*models.EsppLot{}
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return lots, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ListEsppLotsByUserID returns a single page of a user's lots ordered by
+// purchase date. The ticker is not part of the index, so the index is read
+// until the page is full. A full page always comes with a cursor, and the page
+// after it may be empty. ErrInvalidCursor is returned for cursors that are not
+// from an earlier page of the same listing.
+func ListEsppLotsByUserID(svc dynamodbiface.DynamoDBAPI, options models.EsppLotListOptions) (*models.EsppLotPage, error) {
+	startKey, err := decodeEsppLotCursor(options)
 	if err != nil {
 		return nil, err
 	}
 
+	keyConditions := map[string]*dynamodb.Condition{
+		"userId": {
+			ComparisonOperator: aws.String("EQ"),
+			AttributeValueList: []*dynamodb.AttributeValue{
+				{
+					S: aws.String(options.UserID),
+				},
+			},
+		},
+	}
+	if condition := purchaseDateCondition(options.PurchaseDateFrom, options.PurchaseDateTo); condition != nil {
+		keyConditions["purchaseDate"] = condition
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:         aws.String(EsppLotsTableName),
+		IndexName:         aws.String(EsppLotsByPurchaseDateIndex),
+		KeyConditions:     keyConditions,
+		ScanIndexForward:  aws.Bool(!options.Descending),
+		ExclusiveStartKey: startKey,
+	}
+	if options.Ticker != "" {
+		input.QueryFilter = map[string]*dynamodb.Condition{
+			"ticker": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String(models.NormalizeTicker(options.Ticker))}},
+			},
+		}
+	}
+
+	// The limit counts lots read before the ticker filter, so each query only
+	// reads as many lots as the page has room for.
 	lots := []*models.EsppLot{}
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &lots)
+	for {
+		if options.Limit > 0 {
+			input.Limit = aws.Int64(int64(options.Limit - len(lots)))
+		}
+
+		result, err := svc.Query(input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []*models.EsppLot{}
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, page...)
+
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+		if len(result.LastEvaluatedKey) == 0 || (options.Limit > 0 && len(lots) >= options.Limit) {
+			break
+		}
+	}
+
+	nextCursor, err := encodeCursor(input.ExclusiveStartKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return lots, nil
+	return &models.EsppLotPage{Items: lots, NextCursor: nextCursor}, nil
+}
+
+// decodeEsppLotCursor decodes the cursor of a lot listing. The cursor must hold
+// a key of the purchase date index inside the listing's user and date range,
+// as DynamoDB rejects any other start key.
+func decodeEsppLotCursor(options models.EsppLotListOptions) (map[string]*dynamodb.AttributeValue, error) {
+	key, err := decodeCursor(options.Cursor)
+	if err != nil || key == nil {
+		return key, err
+	}
+
+	if len(key) != 3 || key["id"] == nil || key["userId"] == nil || key["purchaseDate"] == nil {
+		return nil, ErrInvalidCursor
+	}
+
+	purchaseDate := aws.StringValue(key["purchaseDate"].S)
+	if aws.StringValue(key["userId"].S) != options.UserID ||
+		(options.PurchaseDateFrom != "" && purchaseDate < options.PurchaseDateFrom) ||
+		(options.PurchaseDateTo != "" && purchaseDate > options.PurchaseDateTo) {
+		return nil, ErrInvalidCursor
+	}
+
+	return key, nil
+}
+
+func purchaseDateCondition(from string, to string) *dynamodb.Condition {
+	switch {
+	case from != "" && to != "":
+		return &dynamodb.Condition{
+			ComparisonOperator: aws.String("BETWEEN"),
+			AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String(from)}, {S: aws.String(to)}},
+		}
+	case from != "":
+		return &dynamodb.Condition{
+			ComparisonOperator: aws.String("GE"),
+			AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String(from)}},
+		}
+	case to != "":
+		return &dynamodb.Condition{
+			ComparisonOperator: aws.String("LE"),
+			AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String(to)}},
+		}
+	default:
+		return nil
+	}
+}
+
+// UpdateEsppLot sets the fields present in the update and bumps updatedAt and
+// the version. It returns nil when no lot with the given ID exists. With an
+// expectedVersion, it also returns nil when the stored lot is at another
+// version.
+func UpdateEsppLot(svc dynamodbiface.DynamoDBAPI, id string, lotUpdate models.EsppLotUpdate, expectedVersion *int64) (*models.EsppLot, error) {
+	currentTime := utils.GetCurrentTimeUTC()
+
+	update := expression.Set(expression.Name("updatedAt"), expression.Value(currentTime))
+	if lotUpdate.GrantDate != nil {
+		update = update.Set(expression.Name("grantDate"), expression.Value(*lotUpdate.GrantDate))
+	}
+	if lotUpdate.PurchaseDate != nil {
+		update = update.Set(expression.Name("purchaseDate"), expression.Value(*lotUpdate.PurchaseDate))
+	}
+	if lotUpdate.OfferStartPrice != nil {
+		update = update.Set(expression.Name("offerStartPrice"), expression.Value(*lotUpdate.OfferStartPrice))
+	}
+	if lotUpdate.OfferEndPrice != nil {
+		update = update.Set(expression.Name("offerEndPrice"), expression.Value(*lotUpdate.OfferEndPrice))
+	}
+	if lotUpdate.PurchasePrice != nil {
+		update = update.Set(expression.Name("purchasePrice"), expression.Value(*lotUpdate.PurchasePrice))
+	}
+	if lotUpdate.Shares != nil {
+		update = update.Set(expression.Name("shares"), expression.Value(*lotUpdate.Shares))
+	}
+	if lotUpdate.Ticker != nil {
+		update = update.Set(expression.Name("ticker"), expression.Value(models.NormalizeTicker(*lotUpdate.Ticker)))
+	}
+	if lotUpdate.Employer != nil {
+		update = update.Set(expression.Name("employer"), expression.Value(strings.TrimSpace(*lotUpdate.Employer)))
+	}
+	if lotUpdate.PlanID != nil {
+		update = update.Set(expression.Name("planId"), expression.Value(*lotUpdate.PlanID))
+	}
+
+	update = setNextVersion(update)
+
+	condition := expression.AttributeExists(expression.Name("id"))
+	if expectedVersion != nil {
+		condition = versionCondition("id", *expectedVersion)
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(EsppLotsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              aws.String("ALL_NEW"),
+	}
+
+	result, err := svc.UpdateItem(input)
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	lot := &models.EsppLot{}
+	err = dynamodbattribute.UnmarshalMap(result.Attributes, lot)
+	if err != nil {
+		return nil, err
+	}
+
+	return lot, nil
 }
 
 func DeleteEsppLot(svc dynamodbiface.DynamoDBAPI, id string) error {
@@ -104,3 +493,9 @@ func DeleteEsppLot(svc dynamodbiface.DynamoDBAPI, id string) error {
 	_, err := svc.DeleteItem(input)
 	return err
 }
+
+// DeleteEsppLotsByUserID deletes every lot the user owns and returns how many
+// there were. Sales of the lots are left for the caller to delete.
+func DeleteEsppLotsByUserID(svc dynamodbiface.DynamoDBAPI, userID string) (int, error) {
+	return deleteAllByUserID(svc, EsppLotsTableName, userID)
+}
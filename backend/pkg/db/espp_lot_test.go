@@ -2,9 +2,12 @@ package db
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/ljhurst/fife/pkg/models"
@@ -13,14 +16,22 @@ import (
 
 type mockEsppDynamoDBClient struct {
 	dynamodbiface.DynamoDBAPI
-	getItemOutput    *dynamodb.GetItemOutput
-	getItemError     error
-	putItemOutput    *dynamodb.PutItemOutput
-	putItemError     error
-	queryOutput      *dynamodb.QueryOutput
-	queryError       error
-	deleteItemOutput *dynamodb.DeleteItemOutput
-	deleteItemError  error
+	getItemOutput     *dynamodb.GetItemOutput
+	getItemError      error
+	putItemOutput     *dynamodb.PutItemOutput
+	putItemError      error
+	queryOutput       *dynamodb.QueryOutput
+	queryOutputs      []*dynamodb.QueryOutput
+	queryError        error
+	queryInputs       []*dynamodb.QueryInput
+	batchWriteOutputs []*dynamodb.BatchWriteItemOutput
+	batchWriteError   error
+	batchWriteInputs  []*dynamodb.BatchWriteItemInput
+	updateItemOutput  *dynamodb.UpdateItemOutput
+	updateItemError   error
+	updateItemInput   *dynamodb.UpdateItemInput
+	deleteItemOutput  *dynamodb.DeleteItemOutput
+	deleteItemError   error
 }
 
 func (m *mockEsppDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
@@ -44,6 +55,27 @@ func (m *mockEsppDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamod
 	return m.putItemOutput, m.putItemError
 }
 
+func (m *mockEsppDynamoDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	if _, ok := input.RequestItems[EsppLotsTableName]; !ok || len(input.RequestItems) != 1 {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.batchWriteInputs = append(m.batchWriteInputs, input)
+
+	if m.batchWriteError != nil {
+		return nil, m.batchWriteError
+	}
+
+	if len(m.batchWriteOutputs) == 0 {
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}
+
+	output := m.batchWriteOutputs[0]
+	m.batchWriteOutputs = m.batchWriteOutputs[1:]
+
+	return output, nil
+}
+
 func (m *mockEsppDynamoDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	if *input.TableName != EsppLotsTableName {
 		return nil, errors.New("incorrect table name")
@@ -54,9 +86,33 @@ func (m *mockEsppDynamoDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.Qu
 		return nil, errors.New("missing or invalid userId key condition")
 	}
 
+	copied := *input
+	m.queryInputs = append(m.queryInputs, &copied)
+
+	if len(m.queryOutputs) > 0 {
+		output := m.queryOutputs[0]
+		m.queryOutputs = m.queryOutputs[1:]
+		return output, m.queryError
+	}
+
 	return m.queryOutput, m.queryError
 }
 
+func (m *mockEsppDynamoDBClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	if *input.TableName != EsppLotsTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	id, ok := input.Key["id"]
+	if !ok || id.S == nil {
+		return nil, errors.New("missing or invalid id key")
+	}
+
+	m.updateItemInput = input
+
+	return m.updateItemOutput, m.updateItemError
+}
+
 func (m *mockEsppDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 	if *input.TableName != EsppLotsTableName {
 		return nil, errors.New("incorrect table name")
@@ -156,6 +212,99 @@ func TestCreateEsppLot(t *testing.T) {
 	}
 }
 
+func TestBatchCreateEsppLots(t *testing.T) {
+	batchWriteBackoff = func(int) time.Duration { return 0 }
+
+	lotInputs := make([]models.EsppLotInput, 30)
+	for i := range lotInputs {
+		lotInputs[i] = models.EsppLotInput{
+			UserID:       "user123",
+			GrantDate:    "2023-01-01",
+			PurchaseDate: "2023-06-30",
+			Shares:       float64(i + 1),
+		}
+	}
+
+	unprocessed := func(count int) *dynamodb.BatchWriteItemOutput {
+		return &dynamodb.BatchWriteItemOutput{
+			UnprocessedItems: map[string][]*dynamodb.WriteRequest{
+				EsppLotsTableName: make([]*dynamodb.WriteRequest, count),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		mockOutputs        []*dynamodb.BatchWriteItemOutput
+		mockError          error
+		expectedBatchSizes []int
+		expectedCreated    int
+		expectedError      bool
+	}{
+		{
+			name:               "all processed",
+			expectedBatchSizes: []int{25, 5},
+			expectedCreated:    30,
+		},
+		{
+			name:               "unprocessed items retried",
+			mockOutputs:        []*dynamodb.BatchWriteItemOutput{unprocessed(3), {}, {}},
+			expectedBatchSizes: []int{25, 3, 5},
+			expectedCreated:    30,
+		},
+		{
+			name: "unprocessed items exhausted",
+			mockOutputs: []*dynamodb.BatchWriteItemOutput{
+				unprocessed(3), unprocessed(3), unprocessed(3), unprocessed(3), unprocessed(3),
+			},
+			expectedBatchSizes: []int{25, 3, 3, 3, 3},
+			expectedCreated:    0,
+			expectedError:      true,
+		},
+		{
+			name:               "dynamodb error",
+			mockError:          errors.New("dynamodb error"),
+			expectedBatchSizes: []int{25},
+			expectedCreated:    0,
+			expectedError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockEsppDynamoDBClient{
+				batchWriteOutputs: tc.mockOutputs,
+				batchWriteError:   tc.mockError,
+			}
+
+			created, err := BatchCreateEsppLots(mockSvc, lotInputs)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			batchSizes := []int{}
+			for _, input := range mockSvc.batchWriteInputs {
+				batchSizes = append(batchSizes, len(input.RequestItems[EsppLotsTableName]))
+			}
+			assert.Equal(t, tc.expectedBatchSizes, batchSizes)
+
+			assert.Len(t, created, len(lotInputs))
+			createdCount := 0
+			for i, lot := range created {
+				if lot != nil {
+					createdCount++
+					assert.Equal(t, lotInputs[i].Shares, lot.Shares)
+					assert.NotEmpty(t, lot.ID)
+				}
+			}
+			assert.Equal(t, tc.expectedCreated, createdCount)
+		})
+	}
+}
+
 func TestGetEsppLot(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -385,6 +534,373 @@ func TestGetEsppLotsByUserID(t *testing.T) {
 	}
 }
 
+func TestGetEsppLotsByUserIDPaginates(t *testing.T) {
+	lastKey := map[string]*dynamodb.AttributeValue{
+		"id":     {S: aws.String("lot123")},
+		"userId": {S: aws.String("user123")},
+	}
+
+	mockSvc := &mockEsppDynamoDBClient{
+		queryOutputs: []*dynamodb.QueryOutput{
+			{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"id": {S: aws.String("lot123")}, "userId": {S: aws.String("user123")}},
+				},
+				LastEvaluatedKey: lastKey,
+			},
+			{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"id": {S: aws.String("lot456")}, "userId": {S: aws.String("user123")}},
+				},
+			},
+		},
+	}
+
+	lots, err := GetEsppLotsByUserID(mockSvc, "user123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppLot{
+		{ID: "lot123", UserID: "user123"},
+		{ID: "lot456", UserID: "user123"},
+	}, lots)
+	assert.Len(t, mockSvc.queryInputs, 2)
+	assert.Nil(t, mockSvc.queryInputs[0].ExclusiveStartKey)
+	assert.Equal(t, lastKey, mockSvc.queryInputs[1].ExclusiveStartKey)
+}
+
+func TestListEsppLotsByUserID(t *testing.T) {
+	lastKey := map[string]*dynamodb.AttributeValue{
+		"id":           {S: aws.String("lot123")},
+		"userId":       {S: aws.String("user123")},
+		"purchaseDate": {S: aws.String("2023-06-30")},
+	}
+	cursor, err := encodeCursor(lastKey)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name                  string
+		options               models.EsppLotListOptions
+		mockOutput            *dynamodb.QueryOutput
+		mockError             error
+		expectedPage          *models.EsppLotPage
+		expectedError         error
+		expectedDateCondition *dynamodb.Condition
+		expectedQueryFilter   map[string]*dynamodb.Condition
+		expectedForward       bool
+		expectedStartKey      map[string]*dynamodb.AttributeValue
+	}{
+		{
+			name:    "first page with more results",
+			options: models.EsppLotListOptions{UserID: "user123", Limit: 1},
+			mockOutput: &dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"id": {S: aws.String("lot123")}, "userId": {S: aws.String("user123")}, "purchaseDate": {S: aws.String("2023-06-30")}},
+				},
+				LastEvaluatedKey: lastKey,
+			},
+			expectedPage: &models.EsppLotPage{
+				Items:      []*models.EsppLot{{ID: "lot123", UserID: "user123", PurchaseDate: "2023-06-30"}},
+				NextCursor: cursor,
+			},
+			expectedForward: true,
+		},
+		{
+			name: "last page in descending date range",
+			options: models.EsppLotListOptions{
+				UserID:           "user123",
+				Limit:            1,
+				Cursor:           cursor,
+				PurchaseDateFrom: "2023-01-01",
+				PurchaseDateTo:   "2023-12-31",
+				Descending:       true,
+			},
+			mockOutput: &dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{},
+			},
+			expectedPage: &models.EsppLotPage{
+				Items: []*models.EsppLot{},
+			},
+			expectedDateCondition: &dynamodb.Condition{
+				ComparisonOperator: aws.String("BETWEEN"),
+				AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String("2023-01-01")}, {S: aws.String("2023-12-31")}},
+			},
+			expectedForward:  false,
+			expectedStartKey: lastKey,
+		},
+		{
+			name:    "filtered by ticker",
+			options: models.EsppLotListOptions{UserID: "user123", Ticker: "nke"},
+			mockOutput: &dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"id": {S: aws.String("lot123")}, "userId": {S: aws.String("user123")}, "ticker": {S: aws.String("NKE")}},
+				},
+			},
+			expectedPage: &models.EsppLotPage{
+				Items: []*models.EsppLot{{ID: "lot123", UserID: "user123", Ticker: "NKE"}},
+			},
+			expectedQueryFilter: map[string]*dynamodb.Condition{
+				"ticker": {
+					ComparisonOperator: aws.String("EQ"),
+					AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String("NKE")}},
+				},
+			},
+			expectedForward: true,
+		},
+		{
+			name:          "invalid cursor",
+			options:       models.EsppLotListOptions{UserID: "user123", Cursor: "%%%"},
+			expectedError: ErrInvalidCursor,
+		},
+		{
+			name:          "cursor from another user",
+			options:       models.EsppLotListOptions{UserID: "user456", Cursor: cursor},
+			expectedError: ErrInvalidCursor,
+		},
+		{
+			name:          "cursor outside date range",
+			options:       models.EsppLotListOptions{UserID: "user123", Cursor: cursor, PurchaseDateFrom: "2024-01-01"},
+			expectedError: ErrInvalidCursor,
+		},
+		{
+			name:          "dynamodb error",
+			options:       models.EsppLotListOptions{UserID: "user123", PurchaseDateFrom: "2023-01-01"},
+			mockError:     errors.New("dynamodb error"),
+			expectedError: errors.New("dynamodb error"),
+			expectedDateCondition: &dynamodb.Condition{
+				ComparisonOperator: aws.String("GE"),
+				AttributeValueList: []*dynamodb.AttributeValue{{S: aws.String("2023-01-01")}},
+			},
+			expectedForward: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockEsppDynamoDBClient{
+				queryOutput: tc.mockOutput,
+				queryError:  tc.mockError,
+			}
+
+			page, err := ListEsppLotsByUserID(mockSvc, tc.options)
+
+			if tc.expectedError != nil {
+				assert.EqualError(t, err, tc.expectedError.Error())
+				assert.Nil(t, page)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPage, page)
+			}
+
+			if tc.expectedError == ErrInvalidCursor {
+				assert.Empty(t, mockSvc.queryInputs)
+				return
+			}
+
+			input := mockSvc.queryInputs[0]
+			assert.Equal(t, EsppLotsByPurchaseDateIndex, *input.IndexName)
+			assert.Equal(t, tc.expectedDateCondition, input.KeyConditions["purchaseDate"])
+			assert.Equal(t, tc.expectedQueryFilter, input.QueryFilter)
+			assert.Equal(t, tc.expectedForward, *input.ScanIndexForward)
+			assert.Equal(t, tc.expectedStartKey, input.ExclusiveStartKey)
+		})
+	}
+}
+
+func TestListEsppLotsByUserIDFillsFilteredPage(t *testing.T) {
+	firstKey := map[string]*dynamodb.AttributeValue{
+		"id":           {S: aws.String("lot123")},
+		"userId":       {S: aws.String("user123")},
+		"purchaseDate": {S: aws.String("2023-06-30")},
+	}
+	secondKey := map[string]*dynamodb.AttributeValue{
+		"id":           {S: aws.String("lot789")},
+		"userId":       {S: aws.String("user123")},
+		"purchaseDate": {S: aws.String("2024-06-30")},
+	}
+	cursor, err := encodeCursor(secondKey)
+	assert.NoError(t, err)
+
+	mockSvc := &mockEsppDynamoDBClient{
+		queryOutputs: []*dynamodb.QueryOutput{
+			{
+				Items:            []map[string]*dynamodb.AttributeValue{},
+				LastEvaluatedKey: firstKey,
+			},
+			{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{"id": {S: aws.String("lot456")}, "userId": {S: aws.String("user123")}, "ticker": {S: aws.String("NKE")}},
+					{"id": {S: aws.String("lot789")}, "userId": {S: aws.String("user123")}, "ticker": {S: aws.String("NKE")}},
+				},
+				LastEvaluatedKey: secondKey,
+			},
+		},
+	}
+
+	page, err := ListEsppLotsByUserID(mockSvc, models.EsppLotListOptions{UserID: "user123", Ticker: "NKE", Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.EsppLotPage{
+		Items: []*models.EsppLot{
+			{ID: "lot456", UserID: "user123", Ticker: "NKE"},
+			{ID: "lot789", UserID: "user123", Ticker: "NKE"},
+		},
+		NextCursor: cursor,
+	}, page)
+	assert.Len(t, mockSvc.queryInputs, 2)
+	assert.Equal(t, int64(2), *mockSvc.queryInputs[0].Limit)
+	assert.Equal(t, int64(2), *mockSvc.queryInputs[1].Limit)
+	assert.Equal(t, firstKey, mockSvc.queryInputs[1].ExclusiveStartKey)
+}
+
+func TestUpdateEsppLot(t *testing.T) {
+	purchasePrice := 80.0
+	shares := 12.5
+	ticker := "nke"
+	planID := "plan123"
+	version := int64(2)
+
+	testCases := []struct {
+		name            string
+		id              string
+		update          models.EsppLotUpdate
+		expectedVersion *int64
+		mockOutput      *dynamodb.UpdateItemOutput
+		mockError       error
+		expectedSet     []string
+		expectedLot     *models.EsppLot
+		expectedError   bool
+	}{
+		{
+			name:   "successful update",
+			id:     "lot123",
+			update: models.EsppLotUpdate{PurchasePrice: &purchasePrice, Shares: &shares},
+			mockOutput: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"id":              {S: aws.String("lot123")},
+					"userId":          {S: aws.String("user123")},
+					"grantDate":       {S: aws.String("2023-01-01")},
+					"purchaseDate":    {S: aws.String("2023-06-30")},
+					"offerStartPrice": {N: aws.String("100")},
+					"offerEndPrice":   {N: aws.String("120")},
+					"purchasePrice":   {N: aws.String("80")},
+					"shares":          {N: aws.String("12.5")},
+					"createdAt":       {S: aws.String("2023-01-01T00:00:00Z")},
+					"updatedAt":       {S: aws.String("2023-01-02T00:00:00Z")},
+				},
+			},
+			mockError:   nil,
+			expectedSet: []string{"purchasePrice", "shares", "updatedAt", "version"},
+			expectedLot: &models.EsppLot{
+				ID:              "lot123",
+				UserID:          "user123",
+				GrantDate:       "2023-01-01",
+				PurchaseDate:    "2023-06-30",
+				OfferStartPrice: 100.0,
+				OfferEndPrice:   120.0,
+				PurchasePrice:   80.0,
+				Shares:          12.5,
+				CreatedAt:       "2023-01-01T00:00:00Z",
+				UpdatedAt:       "2023-01-02T00:00:00Z",
+			},
+			expectedError: false,
+		},
+		{
+			name:   "ticker update",
+			id:     "lot123",
+			update: models.EsppLotUpdate{Ticker: &ticker},
+			mockOutput: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"id":     {S: aws.String("lot123")},
+					"ticker": {S: aws.String("NKE")},
+				},
+			},
+			expectedSet: []string{"ticker", "updatedAt", "version"},
+			expectedLot: &models.EsppLot{ID: "lot123", Ticker: "NKE"},
+		},
+		{
+			name:   "plan update",
+			id:     "lot123",
+			update: models.EsppLotUpdate{PlanID: &planID},
+			mockOutput: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"id":     {S: aws.String("lot123")},
+					"planId": {S: aws.String("plan123")},
+				},
+			},
+			expectedSet: []string{"planId", "updatedAt", "version"},
+			expectedLot: &models.EsppLot{ID: "lot123", PlanID: "plan123"},
+		},
+		{
+			name:          "lot not found",
+			id:            "lot456",
+			update:        models.EsppLotUpdate{Shares: &shares},
+			mockOutput:    nil,
+			mockError:     awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+			expectedSet:   []string{"shares", "updatedAt", "version"},
+			expectedLot:   nil,
+			expectedError: false,
+		},
+		{
+			name:            "expected version",
+			id:              "lot123",
+			update:          models.EsppLotUpdate{Shares: &shares},
+			expectedVersion: &version,
+			mockOutput: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"id":      {S: aws.String("lot123")},
+					"version": {N: aws.String("3")},
+				},
+			},
+			expectedSet: []string{"shares", "updatedAt", "version"},
+			expectedLot: &models.EsppLot{ID: "lot123", Version: 3},
+		},
+		{
+			name:            "version mismatch",
+			id:              "lot123",
+			update:          models.EsppLotUpdate{Shares: &shares},
+			expectedVersion: &version,
+			mockError:       awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+			expectedSet:     []string{"shares", "updatedAt", "version"},
+			expectedLot:     nil,
+		},
+		{
+			name:          "dynamodb error",
+			id:            "lot123",
+			update:        models.EsppLotUpdate{Shares: &shares},
+			mockOutput:    nil,
+			mockError:     errors.New("dynamodb error"),
+			expectedSet:   []string{"shares", "updatedAt", "version"},
+			expectedLot:   nil,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockEsppDynamoDBClient{
+				updateItemOutput: tc.mockOutput,
+				updateItemError:  tc.mockError,
+			}
+
+			lot, err := UpdateEsppLot(mockSvc, tc.id, tc.update, tc.expectedVersion)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedLot, lot)
+
+			setNames := []string{}
+			for _, name := range mockSvc.updateItemInput.ExpressionAttributeNames {
+				setNames = append(setNames, *name)
+			}
+			assert.ElementsMatch(t, append(tc.expectedSet, "id"), setNames)
+			assert.NotNil(t, mockSvc.updateItemInput.ConditionExpression)
+		})
+	}
+}
+
 func TestDeleteEsppLot(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -426,3 +942,115 @@ func TestDeleteEsppLot(t *testing.T) {
 		})
 	}
 }
+
+func lotIDItems(start int, count int) []map[string]*dynamodb.AttributeValue {
+	items := make([]map[string]*dynamodb.AttributeValue, count)
+	for i := range items {
+		items[i] = map[string]*dynamodb.AttributeValue{"id": {S: aws.String(fmt.Sprintf("lot%d", start+i))}}
+	}
+
+	return items
+}
+
+func TestDeleteEsppLotsByUserID(t *testing.T) {
+	lastKey := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("lot29")}}
+
+	testCases := []struct {
+		name               string
+		queryOutputs       []*dynamodb.QueryOutput
+		queryError         error
+		batchWriteError    error
+		expectedDeleted    int
+		expectedBatchSizes []int
+		expectedQueries    int
+		expectedError      bool
+	}{
+		{
+			name: "deletes every page",
+			queryOutputs: []*dynamodb.QueryOutput{
+				{Items: lotIDItems(0, 30), LastEvaluatedKey: lastKey},
+				{Items: lotIDItems(30, 2)},
+			},
+			expectedDeleted:    32,
+			expectedBatchSizes: []int{25, 5, 2},
+			expectedQueries:    2,
+		},
+		{
+			name:               "no lots",
+			queryOutputs:       []*dynamodb.QueryOutput{{}},
+			expectedDeleted:    0,
+			expectedBatchSizes: []int{},
+			expectedQueries:    1,
+		},
+		{
+			name:               "query error",
+			queryError:         errors.New("dynamodb error"),
+			expectedBatchSizes: []int{},
+			expectedQueries:    1,
+			expectedError:      true,
+		},
+		{
+			name:               "batch write error",
+			queryOutputs:       []*dynamodb.QueryOutput{{Items: lotIDItems(0, 2)}},
+			batchWriteError:    errors.New("dynamodb error"),
+			expectedBatchSizes: []int{2},
+			expectedQueries:    1,
+			expectedError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockEsppDynamoDBClient{
+				queryOutputs:    tc.queryOutputs,
+				queryError:      tc.queryError,
+				batchWriteError: tc.batchWriteError,
+			}
+
+			deleted, err := DeleteEsppLotsByUserID(mockSvc, "user123")
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedDeleted, deleted)
+			}
+
+			batchSizes := []int{}
+			for _, input := range mockSvc.batchWriteInputs {
+				requests := input.RequestItems[EsppLotsTableName]
+				batchSizes = append(batchSizes, len(requests))
+				for _, request := range requests {
+					assert.Nil(t, request.PutRequest)
+					assert.NotNil(t, request.DeleteRequest.Key["id"].S)
+				}
+			}
+			assert.Equal(t, tc.expectedBatchSizes, batchSizes)
+
+			assert.Len(t, mockSvc.queryInputs, tc.expectedQueries)
+			assert.Equal(t, "userId-index", *mockSvc.queryInputs[0].IndexName)
+			assert.Equal(t, "id", *mockSvc.queryInputs[0].ProjectionExpression)
+			if tc.expectedQueries > 1 {
+				assert.Equal(t, lastKey, mockSvc.queryInputs[1].ExclusiveStartKey)
+			}
+		})
+	}
+}
+
+func TestBatchPutEsppLots(t *testing.T) {
+	lots := make([]models.EsppLot, 26)
+	for i := range lots {
+		lots[i] = models.EsppLot{ID: fmt.Sprintf("lot%d", i), UserID: "user123", CreatedAt: "2023-01-01T00:00:00Z"}
+	}
+
+	mockSvc := &mockEsppDynamoDBClient{}
+	assert.NoError(t, BatchPutEsppLots(mockSvc, lots))
+	assert.Len(t, mockSvc.batchWriteInputs, 2)
+	assert.Len(t, mockSvc.batchWriteInputs[0].RequestItems[EsppLotsTableName], 25)
+	item := mockSvc.batchWriteInputs[1].RequestItems[EsppLotsTableName][0].PutRequest.Item
+	assert.Equal(t, "lot25", *item["id"].S)
+	assert.Equal(t, "2023-01-01T00:00:00Z", *item["createdAt"].S)
+
+	mockSvc = &mockEsppDynamoDBClient{batchWriteError: errors.New("dynamodb error")}
+	assert.Error(t, BatchPutEsppLots(mockSvc, lots))
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+const (
+	EsppPlansTableName = "fife-espp-plans"
+)
+
+func CreateEsppPlan(svc dynamodbiface.DynamoDBAPI, planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	plan := models.NewEsppPlan(planInput)
+
+	av, err := dynamodbattribute.MarshalMap(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(EsppPlansTableName),
+		Item:      av,
+	}
+
+	_, err = svc.PutItem(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func GetEsppPlan(svc dynamodbiface.DynamoDBAPI, id string) (*models.EsppPlan, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(EsppPlansTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	result, err := svc.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	plan := &models.EsppPlan{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func GetEsppPlansByUserID(svc dynamodbiface.DynamoDBAPI, userID string) ([]*models.EsppPlan, error) {
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(EsppPlansTableName),
+		IndexName: aws.String("userId-index"),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"userId": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(userID),
+					},
+				},
+			},
+		},
+	}
+
+	plans := []*models.EsppPlan{}
+	for {
+		result, err := svc.Query(input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []*models.EsppPlan{}
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return plans, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// UpdateEsppPlan replaces the rules of an existing plan. It returns nil when
+// the plan does not exist.
+func UpdateEsppPlan(svc dynamodbiface.DynamoDBAPI, id string, planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	var rules models.EsppPlan
+	planInput.Apply(&rules)
+
+	update := expression.Set(expression.Name("updatedAt"), expression.Value(utils.GetCurrentTimeUTC())).
+		Set(expression.Name("name"), expression.Value(rules.Name)).
+		Set(expression.Name("employer"), expression.Value(rules.Employer)).
+		Set(expression.Name("discountPercent"), expression.Value(rules.DiscountPercent)).
+		Set(expression.Name("lookback"), expression.Value(rules.Lookback)).
+		Set(expression.Name("offeringPeriodMonths"), expression.Value(rules.OfferingPeriodMonths)).
+		Set(expression.Name("purchasePeriodMonths"), expression.Value(rules.PurchasePeriodMonths)).
+		Set(expression.Name("resetRule"), expression.Value(rules.ResetRule)).
+		Set(expression.Name("maxSharesPerPurchase"), expression.Value(rules.MaxSharesPerPurchase))
+
+	condition := expression.AttributeExists(expression.Name("id"))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(EsppPlansTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              aws.String("ALL_NEW"),
+	}
+
+	result, err := svc.UpdateItem(input)
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	plan := &models.EsppPlan{}
+	err = dynamodbattribute.UnmarshalMap(result.Attributes, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func DeleteEsppPlan(svc dynamodbiface.DynamoDBAPI, id string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(EsppPlansTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	_, err := svc.DeleteItem(input)
+	return err
+}
+
+// DeleteEsppPlansByUserID deletes every plan the user owns and returns how
+// many there were.
+// BatchPutEsppPlans writes the plans as they are, keeping their IDs and
+// timestamps and replacing any plan with the same ID.
+func BatchPutEsppPlans(svc dynamodbiface.DynamoDBAPI, plans []models.EsppPlan) error {
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(plans))
+	for _, plan := range plans {
+		av, err := dynamodbattribute.MarshalMap(plan)
+		if err != nil {
+			return err
+		}
+		items = append(items, av)
+	}
+
+	return batchPutItems(svc, EsppPlansTableName, items)
+}
+
+func DeleteEsppPlansByUserID(svc dynamodbiface.DynamoDBAPI, userID string) (int, error) {
+	return deleteAllByUserID(svc, EsppPlansTableName, userID)
+}
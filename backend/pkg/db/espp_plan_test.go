@@ -0,0 +1,228 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEsppPlanDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+	getItemOutput    *dynamodb.GetItemOutput
+	getItemError     error
+	putItemError     error
+	queryOutputs     []*dynamodb.QueryOutput
+	queryError       error
+	updateItemOutput *dynamodb.UpdateItemOutput
+	updateItemError  error
+	updateItemInput  *dynamodb.UpdateItemInput
+	deleteItemError  error
+	batchWriteInputs []*dynamodb.BatchWriteItemInput
+}
+
+func (m *mockEsppPlanDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if *input.TableName != EsppPlansTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return m.getItemOutput, m.getItemError
+}
+
+func (m *mockEsppPlanDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName != EsppPlansTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return &dynamodb.PutItemOutput{}, m.putItemError
+}
+
+func (m *mockEsppPlanDynamoDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	if *input.TableName != EsppPlansTableName || *input.IndexName != "userId-index" {
+		return nil, errors.New("incorrect table or index name")
+	}
+
+	if m.queryError != nil {
+		return nil, m.queryError
+	}
+
+	output := m.queryOutputs[0]
+	m.queryOutputs = m.queryOutputs[1:]
+
+	return output, nil
+}
+
+func (m *mockEsppPlanDynamoDBClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	if *input.TableName != EsppPlansTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.updateItemInput = input
+
+	return m.updateItemOutput, m.updateItemError
+}
+
+func (m *mockEsppPlanDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	if *input.TableName != EsppPlansTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return &dynamodb.DeleteItemOutput{}, m.deleteItemError
+}
+
+func (m *mockEsppPlanDynamoDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	if _, ok := input.RequestItems[EsppPlansTableName]; !ok || len(input.RequestItems) != 1 {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.batchWriteInputs = append(m.batchWriteInputs, input)
+
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func planItem(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"id":                   {S: aws.String(id)},
+		"userId":               {S: aws.String("user123")},
+		"name":                 {S: aws.String("Nike ESPP")},
+		"employer":             {S: aws.String("Nike")},
+		"discountPercent":      {N: aws.String("15")},
+		"lookback":             {BOOL: aws.Bool(true)},
+		"offeringPeriodMonths": {N: aws.String("6")},
+		"purchasePeriodMonths": {N: aws.String("6")},
+		"resetRule":            {S: aws.String("none")},
+	}
+}
+
+var nikePlan = models.EsppPlan{
+	ID:                   "plan123",
+	UserID:               "user123",
+	Name:                 "Nike ESPP",
+	Employer:             "Nike",
+	DiscountPercent:      15,
+	Lookback:             true,
+	OfferingPeriodMonths: 6,
+	PurchasePeriodMonths: 6,
+	ResetRule:            models.EsppResetRuleNone,
+}
+
+func TestCreateEsppPlan(t *testing.T) {
+	planInput := models.EsppPlanInput{
+		UserID:               "user123",
+		Name:                 " Nike ESPP ",
+		DiscountPercent:      15,
+		Lookback:             true,
+		OfferingPeriodMonths: 6,
+		PurchasePeriodMonths: 6,
+	}
+
+	plan, err := CreateEsppPlan(&mockEsppPlanDynamoDBClient{}, planInput)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, plan.ID)
+	assert.Equal(t, "Nike ESPP", plan.Name)
+	assert.Equal(t, models.EsppResetRuleNone, plan.ResetRule)
+	assert.NotEmpty(t, plan.CreatedAt)
+
+	plan, err = CreateEsppPlan(&mockEsppPlanDynamoDBClient{putItemError: errors.New("dynamodb error")}, planInput)
+	assert.Error(t, err)
+	assert.Nil(t, plan)
+}
+
+func TestGetEsppPlan(t *testing.T) {
+	plan, err := GetEsppPlan(&mockEsppPlanDynamoDBClient{getItemOutput: &dynamodb.GetItemOutput{Item: planItem("plan123")}}, "plan123")
+	assert.NoError(t, err)
+	assert.Equal(t, &nikePlan, plan)
+
+	plan, err = GetEsppPlan(&mockEsppPlanDynamoDBClient{getItemOutput: &dynamodb.GetItemOutput{}}, "plan456")
+	assert.NoError(t, err)
+	assert.Nil(t, plan)
+
+	_, err = GetEsppPlan(&mockEsppPlanDynamoDBClient{getItemError: errors.New("dynamodb error")}, "plan123")
+	assert.Error(t, err)
+}
+
+func TestGetEsppPlansByUserID(t *testing.T) {
+	mockSvc := &mockEsppPlanDynamoDBClient{
+		queryOutputs: []*dynamodb.QueryOutput{
+			{
+				Items:            []map[string]*dynamodb.AttributeValue{planItem("plan123")},
+				LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("plan123")}},
+			},
+			{Items: []map[string]*dynamodb.AttributeValue{planItem("plan456")}},
+		},
+	}
+
+	plans, err := GetEsppPlansByUserID(mockSvc, "user123")
+	assert.NoError(t, err)
+	assert.Len(t, plans, 2)
+	assert.Equal(t, "plan456", plans[1].ID)
+
+	_, err = GetEsppPlansByUserID(&mockEsppPlanDynamoDBClient{queryError: errors.New("dynamodb error")}, "user123")
+	assert.Error(t, err)
+}
+
+func TestUpdateEsppPlan(t *testing.T) {
+	planInput := models.EsppPlanInput{Name: "Nike ESPP", Employer: "Nike", DiscountPercent: 15, Lookback: true, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6}
+
+	mockSvc := &mockEsppPlanDynamoDBClient{updateItemOutput: &dynamodb.UpdateItemOutput{Attributes: planItem("plan123")}}
+	plan, err := UpdateEsppPlan(mockSvc, "plan123", planInput)
+	assert.NoError(t, err)
+	assert.Equal(t, &nikePlan, plan)
+
+	setNames := []string{}
+	for _, name := range mockSvc.updateItemInput.ExpressionAttributeNames {
+		setNames = append(setNames, *name)
+	}
+	assert.ElementsMatch(t, []string{
+		"id", "updatedAt", "name", "employer", "discountPercent", "lookback",
+		"offeringPeriodMonths", "purchasePeriodMonths", "resetRule", "maxSharesPerPurchase",
+	}, setNames)
+	assert.NotNil(t, mockSvc.updateItemInput.ConditionExpression)
+
+	plan, err = UpdateEsppPlan(&mockEsppPlanDynamoDBClient{
+		updateItemError: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil),
+	}, "plan456", planInput)
+	assert.NoError(t, err)
+	assert.Nil(t, plan)
+
+	_, err = UpdateEsppPlan(&mockEsppPlanDynamoDBClient{updateItemError: errors.New("dynamodb error")}, "plan123", planInput)
+	assert.Error(t, err)
+}
+
+func TestDeleteEsppPlan(t *testing.T) {
+	assert.NoError(t, DeleteEsppPlan(&mockEsppPlanDynamoDBClient{}, "plan123"))
+	assert.Error(t, DeleteEsppPlan(&mockEsppPlanDynamoDBClient{deleteItemError: errors.New("dynamodb error")}, "plan123"))
+}
+
+func TestDeleteEsppPlansByUserID(t *testing.T) {
+	mockSvc := &mockEsppPlanDynamoDBClient{queryOutputs: []*dynamodb.QueryOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{{"id": {S: aws.String("plan123")}}, {"id": {S: aws.String("plan456")}}}},
+	}}
+
+	deleted, err := DeleteEsppPlansByUserID(mockSvc, "user123")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Len(t, mockSvc.batchWriteInputs, 1)
+	assert.Equal(t, "plan456", *mockSvc.batchWriteInputs[0].RequestItems[EsppPlansTableName][1].DeleteRequest.Key["id"].S)
+
+	mockSvc = &mockEsppPlanDynamoDBClient{queryError: errors.New("dynamodb error")}
+	_, err = DeleteEsppPlansByUserID(mockSvc, "user123")
+	assert.Error(t, err)
+}
+
+func TestBatchPutEsppPlans(t *testing.T) {
+	mockSvc := &mockEsppPlanDynamoDBClient{}
+	err := BatchPutEsppPlans(mockSvc, []models.EsppPlan{
+		{ID: "plan123", UserID: "user123", Name: "Acme ESPP", CreatedAt: "2023-01-01T00:00:00Z"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mockSvc.batchWriteInputs, 1)
+	item := mockSvc.batchWriteInputs[0].RequestItems[EsppPlansTableName][0].PutRequest.Item
+	assert.Equal(t, "plan123", *item["id"].S)
+	assert.Equal(t, "2023-01-01T00:00:00Z", *item["createdAt"].S)
+}
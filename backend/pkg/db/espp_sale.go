@@ -0,0 +1,132 @@
+package db
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+const (
+	EsppSalesTableName = "fife-espp-sales"
+)
+
+func CreateEsppSale(svc dynamodbiface.DynamoDBAPI, saleInput models.EsppSaleInput) (*models.EsppSale, error) {
+	sale := models.NewEsppSale(saleInput)
+
+	av, err := dynamodbattribute.MarshalMap(sale)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(EsppSalesTableName),
+		Item:      av,
+	}
+
+	_, err = svc.PutItem(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+func GetEsppSale(svc dynamodbiface.DynamoDBAPI, id string) (*models.EsppSale, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(EsppSalesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	result, err := svc.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	sale := &models.EsppSale{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, sale)
+	if err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+func GetEsppSalesByLotID(svc dynamodbiface.DynamoDBAPI, lotID string) ([]*models.EsppSale, error) {
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(EsppSalesTableName),
+		IndexName: aws.String("lotId-index"),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"lotId": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(lotID),
+					},
+				},
+			},
+		},
+	}
+
+	sales := []*models.EsppSale{}
+	for {
+		result, err := svc.Query(input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []*models.EsppSale{}
+		err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return sales, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func DeleteEsppSale(svc dynamodbiface.DynamoDBAPI, id string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(EsppSalesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	_, err := svc.DeleteItem(input)
+	return err
+}
+
+// BatchPutEsppSales writes the sales as they are, keeping their IDs and
+// timestamps and replacing any sale with the same ID.
+func BatchPutEsppSales(svc dynamodbiface.DynamoDBAPI, sales []models.EsppSale) error {
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(sales))
+	for _, sale := range sales {
+		av, err := dynamodbattribute.MarshalMap(sale)
+		if err != nil {
+			return err
+		}
+		items = append(items, av)
+	}
+
+	return batchPutItems(svc, EsppSalesTableName, items)
+}
+
+func BatchDeleteEsppSales(svc dynamodbiface.DynamoDBAPI, ids []string) error {
+	return batchDelete(svc, EsppSalesTableName, ids)
+}
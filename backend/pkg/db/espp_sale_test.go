@@ -0,0 +1,240 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEsppSaleDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+	getItemOutput    *dynamodb.GetItemOutput
+	getItemError     error
+	putItemError     error
+	queryOutputs     []*dynamodb.QueryOutput
+	queryError       error
+	queryInputs      []*dynamodb.QueryInput
+	deleteItemError  error
+	deleteItemInputs []*dynamodb.DeleteItemInput
+	batchWriteError  error
+	batchWriteInputs []*dynamodb.BatchWriteItemInput
+}
+
+func (m *mockEsppSaleDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if *input.TableName != EsppSalesTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return m.getItemOutput, m.getItemError
+}
+
+func (m *mockEsppSaleDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName != EsppSalesTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return &dynamodb.PutItemOutput{}, m.putItemError
+}
+
+func (m *mockEsppSaleDynamoDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	if *input.TableName != EsppSalesTableName || *input.IndexName != "lotId-index" {
+		return nil, errors.New("incorrect table or index name")
+	}
+
+	copied := *input
+	m.queryInputs = append(m.queryInputs, &copied)
+
+	if m.queryError != nil {
+		return nil, m.queryError
+	}
+
+	output := m.queryOutputs[0]
+	m.queryOutputs = m.queryOutputs[1:]
+
+	return output, nil
+}
+
+func (m *mockEsppSaleDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	if *input.TableName != EsppSalesTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.deleteItemInputs = append(m.deleteItemInputs, input)
+
+	return &dynamodb.DeleteItemOutput{}, m.deleteItemError
+}
+
+func (m *mockEsppSaleDynamoDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	if _, ok := input.RequestItems[EsppSalesTableName]; !ok || len(input.RequestItems) != 1 {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.batchWriteInputs = append(m.batchWriteInputs, input)
+
+	return &dynamodb.BatchWriteItemOutput{}, m.batchWriteError
+}
+
+func saleItem(id string, shares string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"id":     {S: aws.String(id)},
+		"lotId":  {S: aws.String("lot123")},
+		"userId": {S: aws.String("user123")},
+		"date":   {S: aws.String("2024-07-01")},
+		"price":  {N: aws.String("150")},
+		"shares": {N: aws.String(shares)},
+	}
+}
+
+func TestCreateEsppSale(t *testing.T) {
+	saleInput := models.EsppSaleInput{
+		LotID:  "lot123",
+		UserID: "user123",
+		Date:   "2024-07-01",
+		Price:  150.0,
+		Shares: 4.0,
+	}
+
+	sale, err := CreateEsppSale(&mockEsppSaleDynamoDBClient{}, saleInput)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sale.ID)
+	assert.Equal(t, "lot123", sale.LotID)
+	assert.Equal(t, 4.0, sale.Shares)
+	assert.NotEmpty(t, sale.CreatedAt)
+
+	sale, err = CreateEsppSale(&mockEsppSaleDynamoDBClient{putItemError: errors.New("dynamodb error")}, saleInput)
+	assert.Error(t, err)
+	assert.Nil(t, sale)
+}
+
+func TestGetEsppSale(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mockOutput    *dynamodb.GetItemOutput
+		mockError     error
+		expectedSale  *models.EsppSale
+		expectedError bool
+	}{
+		{
+			name:       "successful retrieval",
+			mockOutput: &dynamodb.GetItemOutput{Item: saleItem("sale123", "4")},
+			expectedSale: &models.EsppSale{
+				ID:     "sale123",
+				LotID:  "lot123",
+				UserID: "user123",
+				Date:   "2024-07-01",
+				Price:  150.0,
+				Shares: 4.0,
+			},
+		},
+		{
+			name:         "sale not found",
+			mockOutput:   &dynamodb.GetItemOutput{},
+			expectedSale: nil,
+		},
+		{
+			name:          "dynamodb error",
+			mockError:     errors.New("dynamodb error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockEsppSaleDynamoDBClient{
+				getItemOutput: tc.mockOutput,
+				getItemError:  tc.mockError,
+			}
+
+			sale, err := GetEsppSale(mockSvc, "sale123")
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedSale, sale)
+		})
+	}
+}
+
+func TestGetEsppSalesByLotID(t *testing.T) {
+	lastKey := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("sale1")}}
+
+	mockSvc := &mockEsppSaleDynamoDBClient{
+		queryOutputs: []*dynamodb.QueryOutput{
+			{Items: []map[string]*dynamodb.AttributeValue{saleItem("sale1", "1")}, LastEvaluatedKey: lastKey},
+			{Items: []map[string]*dynamodb.AttributeValue{saleItem("sale2", "2")}},
+		},
+	}
+
+	sales, err := GetEsppSalesByLotID(mockSvc, "lot123")
+
+	assert.NoError(t, err)
+	assert.Len(t, sales, 2)
+	assert.Equal(t, "sale1", sales[0].ID)
+	assert.Equal(t, "sale2", sales[1].ID)
+	assert.Len(t, mockSvc.queryInputs, 2)
+	assert.Nil(t, mockSvc.queryInputs[0].ExclusiveStartKey)
+	assert.Equal(t, lastKey, mockSvc.queryInputs[1].ExclusiveStartKey)
+
+	sales, err = GetEsppSalesByLotID(&mockEsppSaleDynamoDBClient{queryError: errors.New("dynamodb error")}, "lot123")
+	assert.Error(t, err)
+	assert.Nil(t, sales)
+}
+
+func TestDeleteEsppSale(t *testing.T) {
+	mockSvc := &mockEsppSaleDynamoDBClient{}
+
+	err := DeleteEsppSale(mockSvc, "sale123")
+	assert.NoError(t, err)
+	assert.Equal(t, "sale123", *mockSvc.deleteItemInputs[0].Key["id"].S)
+
+	err = DeleteEsppSale(&mockEsppSaleDynamoDBClient{deleteItemError: errors.New("dynamodb error")}, "sale123")
+	assert.Error(t, err)
+}
+
+func TestBatchDeleteEsppSales(t *testing.T) {
+	ids := make([]string, 26)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("sale%d", i)
+	}
+
+	mockSvc := &mockEsppSaleDynamoDBClient{}
+	assert.NoError(t, BatchDeleteEsppSales(mockSvc, ids))
+	assert.Len(t, mockSvc.batchWriteInputs, 2)
+	assert.Len(t, mockSvc.batchWriteInputs[0].RequestItems[EsppSalesTableName], 25)
+	assert.Equal(t, "sale25", *mockSvc.batchWriteInputs[1].RequestItems[EsppSalesTableName][0].DeleteRequest.Key["id"].S)
+
+	mockSvc = &mockEsppSaleDynamoDBClient{}
+	assert.NoError(t, BatchDeleteEsppSales(mockSvc, nil))
+	assert.Empty(t, mockSvc.batchWriteInputs)
+
+	mockSvc = &mockEsppSaleDynamoDBClient{batchWriteError: errors.New("dynamodb error")}
+	assert.Error(t, BatchDeleteEsppSales(mockSvc, ids))
+}
+
+func TestBatchPutEsppSales(t *testing.T) {
+	sales := []models.EsppSale{
+		{ID: "sale123", LotID: "lot123", UserID: "user123", CreatedAt: "2023-01-01T00:00:00Z"},
+	}
+
+	mockSvc := &mockEsppSaleDynamoDBClient{}
+	assert.NoError(t, BatchPutEsppSales(mockSvc, sales))
+	assert.Len(t, mockSvc.batchWriteInputs, 1)
+	item := mockSvc.batchWriteInputs[0].RequestItems[EsppSalesTableName][0].PutRequest.Item
+	assert.Equal(t, "sale123", *item["id"].S)
+	assert.Equal(t, "2023-01-01T00:00:00Z", *item["createdAt"].S)
+
+	mockSvc = &mockEsppSaleDynamoDBClient{}
+	assert.NoError(t, BatchPutEsppSales(mockSvc, nil))
+	assert.Empty(t, mockSvc.batchWriteInputs)
+
+	mockSvc = &mockEsppSaleDynamoDBClient{batchWriteError: errors.New("dynamodb error")}
+	assert.Error(t, BatchPutEsppSales(mockSvc, sales))
+}
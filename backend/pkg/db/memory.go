@@ -0,0 +1,596 @@
+package db
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type MemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]models.User
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: map[string]models.User{}}
+}
+
+func (r *MemoryUserRepository) GetUser(userID string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+// PutUser stores a copy of the user as-is, which is useful for seeding state.
+func (r *MemoryUserRepository) PutUser(user models.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[user.UserID] = user
+}
+
+// UpdateUserSettings mirrors the DynamoDB UpdateItem behavior, which creates
+// the item when it does not exist yet unless a version is expected.
+func (r *MemoryUserRepository) UpdateUserSettings(userID string, settings models.UserSettings, expectedVersion *int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if expectedVersion != nil && (!ok || user.Version != *expectedVersion) {
+		return nil, nil
+	}
+
+	now := utils.GetCurrentTimeUTC()
+	if !ok {
+		user.CreatedAt = now
+	}
+	user.UserID = userID
+	user.Settings = settings
+	user.Version++
+	user.UpdatedAt = now
+	r.users[userID] = user
+
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) SetCalendarTokenHash(userID string, tokenHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return false, nil
+	}
+
+	user.CalendarTokenHash = tokenHash
+	user.UpdatedAt = utils.GetCurrentTimeUTC()
+	r.users[userID] = user
+
+	return true, nil
+}
+
+// ListUsers returns the users ordered by ID so scans are deterministic.
+func (r *MemoryUserRepository) ListUsers() ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		user := user
+		users = append(users, &user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+
+	return users, nil
+}
+
+// CreateUser stores the user unless one with the same ID already exists, in
+// which case it returns nil like the DynamoDB conditional put.
+func (r *MemoryUserRepository) CreateUser(user models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.UserID]; ok {
+		return nil, nil
+	}
+	r.users[user.UserID] = user
+
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) DeleteUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, userID)
+
+	return nil
+}
+
+func (r *MemoryUserRepository) ReplaceUser(user models.User) error {
+	r.PutUser(user)
+
+	return nil
+}
+
+func (r *MemoryUserRepository) SetNotificationCheckpoints(userID string, checkpoints models.NotificationCheckpoints) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return false, nil
+	}
+
+	user.NotificationCheckpoints = &checkpoints
+	r.users[userID] = user
+
+	return true, nil
+}
+
+type MemoryEsppLotRepository struct {
+	mu   sync.RWMutex
+	lots map[string]models.EsppLot
+	// order keeps lots in insertion order so listings are deterministic.
+	order []string
+}
+
+func NewMemoryEsppLotRepository() *MemoryEsppLotRepository {
+	return &MemoryEsppLotRepository{lots: map[string]models.EsppLot{}}
+}
+
+func (r *MemoryEsppLotRepository) CreateEsppLot(lotInput models.EsppLotInput) (*models.EsppLot, error) {
+	lot := models.NewEsppLot(lotInput)
+
+	r.PutEsppLot(*lot)
+
+	return lot, nil
+}
+
+func (r *MemoryEsppLotRepository) BatchCreateEsppLots(lotInputs []models.EsppLotInput) ([]*models.EsppLot, error) {
+	created := make([]*models.EsppLot, len(lotInputs))
+	for i, lotInput := range lotInputs {
+		created[i], _ = r.CreateEsppLot(lotInput)
+	}
+
+	return created, nil
+}
+
+// PutEsppLot stores a copy of the lot as-is, which is useful for seeding state.
+func (r *MemoryEsppLotRepository) PutEsppLot(lot models.EsppLot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.lots[lot.ID]; !ok {
+		r.order = append(r.order, lot.ID)
+	}
+	r.lots[lot.ID] = lot
+}
+
+func (r *MemoryEsppLotRepository) GetEsppLot(id string) (*models.EsppLot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lot, ok := r.lots[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &lot, nil
+}
+
+func (r *MemoryEsppLotRepository) GetEsppLotsByUserID(userID string) ([]*models.EsppLot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lots := []*models.EsppLot{}
+	for _, id := range r.order {
+		lot := r.lots[id]
+		if lot.UserID == userID {
+			lots = append(lots, &lot)
+		}
+	}
+
+	return lots, nil
+}
+
+// ListEsppLotsByUserID mirrors the purchase date index: lots are ordered by
+// purchaseDate, then id, and the cursor holds the key of the last lot returned.
+func (r *MemoryEsppLotRepository) ListEsppLotsByUserID(options models.EsppLotListOptions) (*models.EsppLotPage, error) {
+	startKey, err := decodeEsppLotCursor(options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lots := []*models.EsppLot{}
+	for _, id := range r.order {
+		lot := r.lots[id]
+		if lot.UserID != options.UserID {
+			continue
+		}
+		if options.PurchaseDateFrom != "" && lot.PurchaseDate < options.PurchaseDateFrom {
+			continue
+		}
+		if options.PurchaseDateTo != "" && lot.PurchaseDate > options.PurchaseDateTo {
+			continue
+		}
+		if options.Ticker != "" && lot.Ticker != models.NormalizeTicker(options.Ticker) {
+			continue
+		}
+		lots = append(lots, &lot)
+	}
+
+	less := func(a, b *models.EsppLot) bool {
+		if a.PurchaseDate != b.PurchaseDate {
+			return a.PurchaseDate < b.PurchaseDate
+		}
+		return a.ID < b.ID
+	}
+	if options.Descending {
+		ascending := less
+		less = func(a, b *models.EsppLot) bool { return ascending(b, a) }
+	}
+	sort.SliceStable(lots, func(i, j int) bool { return less(lots[i], lots[j]) })
+
+	if startKey != nil {
+		last := &models.EsppLot{ID: aws.StringValue(startKey["id"].S), PurchaseDate: aws.StringValue(startKey["purchaseDate"].S)}
+		start := sort.Search(len(lots), func(i int) bool { return less(last, lots[i]) })
+		lots = lots[start:]
+	}
+
+	page := &models.EsppLotPage{Items: lots}
+	if options.Limit > 0 && len(lots) > options.Limit {
+		page.Items = lots[:options.Limit]
+
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor, err = encodeCursor(map[string]*dynamodb.AttributeValue{
+			"id":           {S: aws.String(last.ID)},
+			"userId":       {S: aws.String(last.UserID)},
+			"purchaseDate": {S: aws.String(last.PurchaseDate)},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (r *MemoryEsppLotRepository) UpdateEsppLot(id string, lotUpdate models.EsppLotUpdate, expectedVersion *int64) (*models.EsppLot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lot, ok := r.lots[id]
+	if !ok || (expectedVersion != nil && lot.Version != *expectedVersion) {
+		return nil, nil
+	}
+
+	lotUpdate.Apply(&lot)
+	lot.Version++
+	lot.UpdatedAt = utils.GetCurrentTimeUTC()
+	r.lots[id] = lot
+
+	return &lot, nil
+}
+
+func (r *MemoryEsppLotRepository) DeleteEsppLot(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.lots[id]; !ok {
+		return nil
+	}
+
+	delete(r.lots, id)
+	for i, lotID := range r.order {
+		if lotID == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryEsppLotRepository) DeleteEsppLotsByUserID(userID string) (int, error) {
+	lots, _ := r.GetEsppLotsByUserID(userID)
+	for _, lot := range lots {
+		_ = r.DeleteEsppLot(lot.ID)
+	}
+
+	return len(lots), nil
+}
+
+func (r *MemoryEsppLotRepository) BatchPutEsppLots(lots []models.EsppLot) error {
+	for _, lot := range lots {
+		r.PutEsppLot(lot)
+	}
+
+	return nil
+}
+
+type MemoryEsppSaleRepository struct {
+	mu    sync.RWMutex
+	sales map[string]models.EsppSale
+	// order keeps sales in insertion order so listings are deterministic.
+	order []string
+}
+
+func NewMemoryEsppSaleRepository() *MemoryEsppSaleRepository {
+	return &MemoryEsppSaleRepository{sales: map[string]models.EsppSale{}}
+}
+
+func (r *MemoryEsppSaleRepository) CreateEsppSale(saleInput models.EsppSaleInput) (*models.EsppSale, error) {
+	sale := models.NewEsppSale(saleInput)
+
+	r.PutEsppSale(*sale)
+
+	return sale, nil
+}
+
+// PutEsppSale stores a copy of the sale as-is, which is useful for seeding state.
+func (r *MemoryEsppSaleRepository) PutEsppSale(sale models.EsppSale) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sales[sale.ID]; !ok {
+		r.order = append(r.order, sale.ID)
+	}
+	r.sales[sale.ID] = sale
+}
+
+func (r *MemoryEsppSaleRepository) GetEsppSale(id string) (*models.EsppSale, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sale, ok := r.sales[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &sale, nil
+}
+
+func (r *MemoryEsppSaleRepository) GetEsppSalesByLotID(lotID string) ([]*models.EsppSale, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sales := []*models.EsppSale{}
+	for _, id := range r.order {
+		sale := r.sales[id]
+		if sale.LotID == lotID {
+			sales = append(sales, &sale)
+		}
+	}
+
+	return sales, nil
+}
+
+func (r *MemoryEsppSaleRepository) DeleteEsppSale(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sales[id]; !ok {
+		return nil
+	}
+
+	delete(r.sales, id)
+	for i, saleID := range r.order {
+		if saleID == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryEsppSaleRepository) BatchDeleteEsppSales(ids []string) error {
+	for _, id := range ids {
+		_ = r.DeleteEsppSale(id)
+	}
+
+	return nil
+}
+
+func (r *MemoryEsppSaleRepository) BatchPutEsppSales(sales []models.EsppSale) error {
+	for _, sale := range sales {
+		r.PutEsppSale(sale)
+	}
+
+	return nil
+}
+
+type MemoryEsppPlanRepository struct {
+	mu    sync.RWMutex
+	plans map[string]models.EsppPlan
+	// order keeps plans in insertion order so listings are deterministic.
+	order []string
+}
+
+func NewMemoryEsppPlanRepository() *MemoryEsppPlanRepository {
+	return &MemoryEsppPlanRepository{plans: map[string]models.EsppPlan{}}
+}
+
+func (r *MemoryEsppPlanRepository) CreateEsppPlan(planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	plan := models.NewEsppPlan(planInput)
+
+	r.PutEsppPlan(*plan)
+
+	return plan, nil
+}
+
+// PutEsppPlan stores a copy of the plan as-is, which is useful for seeding state.
+func (r *MemoryEsppPlanRepository) PutEsppPlan(plan models.EsppPlan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.plans[plan.ID]; !ok {
+		r.order = append(r.order, plan.ID)
+	}
+	r.plans[plan.ID] = plan
+}
+
+func (r *MemoryEsppPlanRepository) GetEsppPlan(id string) (*models.EsppPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plan, ok := r.plans[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &plan, nil
+}
+
+func (r *MemoryEsppPlanRepository) GetEsppPlansByUserID(userID string) ([]*models.EsppPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plans := []*models.EsppPlan{}
+	for _, id := range r.order {
+		plan := r.plans[id]
+		if plan.UserID == userID {
+			plans = append(plans, &plan)
+		}
+	}
+
+	return plans, nil
+}
+
+func (r *MemoryEsppPlanRepository) UpdateEsppPlan(id string, planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan, ok := r.plans[id]
+	if !ok {
+		return nil, nil
+	}
+
+	planInput.Apply(&plan)
+	plan.UpdatedAt = utils.GetCurrentTimeUTC()
+	r.plans[id] = plan
+
+	return &plan, nil
+}
+
+func (r *MemoryEsppPlanRepository) DeleteEsppPlan(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.plans[id]; !ok {
+		return nil
+	}
+
+	delete(r.plans, id)
+	for i, planID := range r.order {
+		if planID == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryEsppPlanRepository) DeleteEsppPlansByUserID(userID string) (int, error) {
+	plans, _ := r.GetEsppPlansByUserID(userID)
+	for _, plan := range plans {
+		_ = r.DeleteEsppPlan(plan.ID)
+	}
+
+	return len(plans), nil
+}
+
+func (r *MemoryEsppPlanRepository) BatchPutEsppPlans(plans []models.EsppPlan) error {
+	for _, plan := range plans {
+		r.PutEsppPlan(plan)
+	}
+
+	return nil
+}
+
+type MemoryQuoteRepository struct {
+	mu     sync.RWMutex
+	quotes map[string]models.Quote
+}
+
+func NewMemoryQuoteRepository() *MemoryQuoteRepository {
+	return &MemoryQuoteRepository{quotes: map[string]models.Quote{}}
+}
+
+func (r *MemoryQuoteRepository) GetQuote(ticker string) (*models.Quote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quote, ok := r.quotes[ticker]
+	if !ok {
+		return nil, nil
+	}
+
+	return &quote, nil
+}
+
+func (r *MemoryQuoteRepository) PutQuote(quote models.Quote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.quotes[quote.Ticker] = quote
+
+	return nil
+}
+
+type MemoryPriceHistoryRepository struct {
+	mu sync.RWMutex
+	// closes maps each ticker to its closes keyed by date.
+	closes map[string]map[string]float64
+}
+
+func NewMemoryPriceHistoryRepository() *MemoryPriceHistoryRepository {
+	return &MemoryPriceHistoryRepository{closes: map[string]map[string]float64{}}
+}
+
+func (r *MemoryPriceHistoryRepository) GetClosingPriceOnOrBefore(ticker string, date string) (*models.ClosingPrice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *models.ClosingPrice
+	for closeDate, closePrice := range r.closes[ticker] {
+		if closeDate <= date && (latest == nil || closeDate > latest.Date) {
+			latest = &models.ClosingPrice{Ticker: ticker, Date: closeDate, Close: closePrice}
+		}
+	}
+
+	return latest, nil
+}
+
+func (r *MemoryPriceHistoryRepository) BatchPutClosingPrices(prices []models.ClosingPrice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, price := range prices {
+		if r.closes[price.Ticker] == nil {
+			r.closes[price.Ticker] = map[string]float64{}
+		}
+		r.closes[price.Ticker][price.Date] = price.Close
+	}
+
+	return nil
+}
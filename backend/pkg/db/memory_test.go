@@ -0,0 +1,359 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repo := NewMemoryUserRepository()
+
+	user, err := repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+
+	settings := models.UserSettings{
+		Finance: models.UserFinanceSettings{
+			AnnualSalary:     120000,
+			PaychecksPerYear: 24,
+		},
+	}
+
+	updatedUser, err := repo.UpdateUserSettings("user123", settings, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", updatedUser.UserID)
+	assert.Equal(t, settings, updatedUser.Settings)
+	assert.Equal(t, int64(1), updatedUser.Version)
+	assert.NotEmpty(t, updatedUser.CreatedAt)
+	assert.NotEmpty(t, updatedUser.UpdatedAt)
+
+	stale := int64(0)
+	staleUser, err := repo.UpdateUserSettings("user123", settings, &stale)
+	assert.NoError(t, err)
+	assert.Nil(t, staleUser)
+
+	staleUser, err = repo.UpdateUserSettings("user456", settings, &stale)
+	assert.NoError(t, err)
+	assert.Nil(t, staleUser)
+
+	user, err = repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, updatedUser, user)
+
+	user.Settings.Finance.AnnualSalary = 1
+	storedUser, err := repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, 120000.0, storedUser.Settings.Finance.AnnualSalary)
+
+	found, err := repo.SetCalendarTokenHash("user123", "abc123")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	storedUser, err = repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", storedUser.CalendarTokenHash)
+	assert.Equal(t, settings, storedUser.Settings)
+
+	found, err = repo.SetCalendarTokenHash("user123", "")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	storedUser, err = repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Empty(t, storedUser.CalendarTokenHash)
+
+	checkpoints := models.NotificationCheckpoints{HoldingPeriod: "2024-06-30"}
+	found, err = repo.SetNotificationCheckpoints("user123", checkpoints)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	// Writes for users who do not exist do not create them.
+	found, err = repo.SetCalendarTokenHash("user456", "abc123")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	found, err = repo.SetNotificationCheckpoints("user456", checkpoints)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	repo.PutUser(models.User{UserID: "user000"})
+
+	users, err := repo.ListUsers()
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "user000", users[0].UserID)
+	assert.Equal(t, "user123", users[1].UserID)
+	assert.Equal(t, &checkpoints, users[1].NotificationCheckpoints)
+	assert.Equal(t, settings, users[1].Settings)
+}
+
+func TestMemoryEsppLotRepository(t *testing.T) {
+	repo := NewMemoryEsppLotRepository()
+
+	lotInput := models.EsppLotInput{
+		UserID:          "user123",
+		GrantDate:       "2023-01-01",
+		PurchaseDate:    "2023-06-30",
+		OfferStartPrice: 100.0,
+		OfferEndPrice:   120.0,
+		PurchasePrice:   85.0,
+		Shares:          10.0,
+	}
+
+	first, err := repo.CreateEsppLot(lotInput)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.ID)
+
+	second, err := repo.CreateEsppLot(lotInput)
+	assert.NoError(t, err)
+
+	otherInput := lotInput
+	otherInput.UserID = "user456"
+	_, err = repo.CreateEsppLot(otherInput)
+	assert.NoError(t, err)
+
+	lot, err := repo.GetEsppLot(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first, lot)
+
+	lots, err := repo.GetEsppLotsByUserID("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppLot{first, second}, lots)
+
+	shares := 12.5
+	updated, err := repo.UpdateEsppLot(second.ID, models.EsppLotUpdate{Shares: &shares}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, updated.Shares)
+	assert.Equal(t, second.PurchasePrice, updated.PurchasePrice)
+	assert.Equal(t, second.CreatedAt, updated.CreatedAt)
+	second = updated
+
+	ticker := "nke"
+	updated, err = repo.UpdateEsppLot(second.ID, models.EsppLotUpdate{Ticker: &ticker}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "NKE", updated.Ticker)
+	assert.Equal(t, int64(3), updated.Version)
+	second = updated
+
+	stale := int64(2)
+	updated, err = repo.UpdateEsppLot(second.ID, models.EsppLotUpdate{Ticker: &ticker}, &stale)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+
+	page, err := repo.ListEsppLotsByUserID(models.EsppLotListOptions{UserID: "user123", Ticker: "NKE"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppLot{second}, page.Items)
+
+	updated, err = repo.UpdateEsppLot("nonexistent", models.EsppLotUpdate{Shares: &shares}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, updated)
+
+	err = repo.DeleteEsppLot(first.ID)
+	assert.NoError(t, err)
+
+	lot, err = repo.GetEsppLot(first.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, lot)
+
+	lots, err = repo.GetEsppLotsByUserID("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppLot{second}, lots)
+
+	err = repo.DeleteEsppLot("nonexistent")
+	assert.NoError(t, err)
+
+	lots, err = repo.GetEsppLotsByUserID("nobody")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppLot{}, lots)
+}
+
+func TestMemoryEsppSaleRepository(t *testing.T) {
+	repo := NewMemoryEsppSaleRepository()
+
+	saleInput := models.EsppSaleInput{
+		LotID:  "lot123",
+		UserID: "user123",
+		Date:   "2024-07-01",
+		Price:  150.0,
+		Shares: 4.0,
+	}
+
+	first, err := repo.CreateEsppSale(saleInput)
+	assert.NoError(t, err)
+
+	otherInput := saleInput
+	otherInput.LotID = "lot456"
+	other, err := repo.CreateEsppSale(otherInput)
+	assert.NoError(t, err)
+
+	sale, err := repo.GetEsppSale(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first, sale)
+
+	sales, err := repo.GetEsppSalesByLotID("lot123")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppSale{first}, sales)
+
+	err = repo.DeleteEsppSale(first.ID)
+	assert.NoError(t, err)
+
+	sales, err = repo.GetEsppSalesByLotID("lot123")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppSale{}, sales)
+
+	sale, err = repo.GetEsppSale(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, other, sale)
+}
+
+func TestMemoryQuoteRepository(t *testing.T) {
+	repo := NewMemoryQuoteRepository()
+
+	quote, err := repo.GetQuote("NKE")
+	assert.NoError(t, err)
+	assert.Nil(t, quote)
+
+	assert.NoError(t, repo.PutQuote(models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"}))
+	assert.NoError(t, repo.PutQuote(models.Quote{Ticker: "NKE", Price: 98.25, Date: "2026-10-17"}))
+
+	quote, err = repo.GetQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Quote{Ticker: "NKE", Price: 98.25, Date: "2026-10-17"}, quote)
+}
+
+func TestMemoryPriceHistoryRepository(t *testing.T) {
+	repo := NewMemoryPriceHistoryRepository()
+
+	assert.NoError(t, repo.BatchPutClosingPrices([]models.ClosingPrice{
+		{Ticker: "NKE", Date: "2023-03-30", Close: 119.5},
+		{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+		{Ticker: "AAPL", Date: "2023-04-03", Close: 166.17},
+	}))
+
+	price, err := repo.GetClosingPriceOnOrBefore("NKE", "2023-03-31")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClosingPrice{Ticker: "NKE", Date: "2023-03-31", Close: 120.1}, price)
+
+	price, err = repo.GetClosingPriceOnOrBefore("NKE", "2023-04-02")
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-03-31", price.Date)
+
+	price, err = repo.GetClosingPriceOnOrBefore("NKE", "2023-03-29")
+	assert.NoError(t, err)
+	assert.Nil(t, price)
+}
+
+func TestMemoryEsppPlanRepository(t *testing.T) {
+	repo := NewMemoryEsppPlanRepository()
+
+	planInput := models.EsppPlanInput{
+		UserID:               "user123",
+		Name:                 "Nike ESPP",
+		DiscountPercent:      15,
+		Lookback:             true,
+		OfferingPeriodMonths: 6,
+		PurchasePeriodMonths: 6,
+	}
+
+	first, err := repo.CreateEsppPlan(planInput)
+	assert.NoError(t, err)
+
+	otherInput := planInput
+	otherInput.UserID = "user456"
+	_, err = repo.CreateEsppPlan(otherInput)
+	assert.NoError(t, err)
+
+	plans, err := repo.GetEsppPlansByUserID("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.EsppPlan{first}, plans)
+
+	planInput.DiscountPercent = 10
+	updated, err := repo.UpdateEsppPlan(first.ID, planInput)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, updated.DiscountPercent)
+	assert.Equal(t, first.CreatedAt, updated.CreatedAt)
+
+	missing, err := repo.UpdateEsppPlan("plan456", planInput)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, repo.DeleteEsppPlan(first.ID))
+	plan, err := repo.GetEsppPlan(first.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, plan)
+}
+
+func TestMemoryUserRepositoryLifecycle(t *testing.T) {
+	repo := NewMemoryUserRepository()
+
+	created, err := repo.CreateUser(*models.NewUser("user123", "user@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", created.UserID)
+
+	duplicate, err := repo.CreateUser(models.User{UserID: "user123"})
+	assert.NoError(t, err)
+	assert.Nil(t, duplicate)
+
+	user, err := repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, created, user)
+
+	assert.NoError(t, repo.DeleteUser("user123"))
+	user, err = repo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+func TestMemoryRepositoriesDeleteByUserID(t *testing.T) {
+	lotRepo := NewMemoryEsppLotRepository()
+	lotRepo.PutEsppLot(models.EsppLot{ID: "lot123", UserID: "user123"})
+	lotRepo.PutEsppLot(models.EsppLot{ID: "lot456", UserID: "user123"})
+	lotRepo.PutEsppLot(models.EsppLot{ID: "lot789", UserID: "user456"})
+
+	deleted, err := lotRepo.DeleteEsppLotsByUserID("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	lots, _ := lotRepo.GetEsppLotsByUserID("user456")
+	assert.Len(t, lots, 1)
+
+	planRepo := NewMemoryEsppPlanRepository()
+	planRepo.PutEsppPlan(models.EsppPlan{ID: "plan123", UserID: "user123"})
+	planRepo.PutEsppPlan(models.EsppPlan{ID: "plan456", UserID: "user456"})
+
+	deleted, err = planRepo.DeleteEsppPlansByUserID("user123")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	plans, _ := planRepo.GetEsppPlansByUserID("user456")
+	assert.Len(t, plans, 1)
+
+	saleRepo := NewMemoryEsppSaleRepository()
+	saleRepo.PutEsppSale(models.EsppSale{ID: "sale123", LotID: "lot123"})
+	saleRepo.PutEsppSale(models.EsppSale{ID: "sale456", LotID: "lot123"})
+
+	assert.NoError(t, saleRepo.BatchDeleteEsppSales([]string{"sale123", "missing"}))
+	sales, _ := saleRepo.GetEsppSalesByLotID("lot123")
+	assert.Len(t, sales, 1)
+	assert.Equal(t, "sale456", sales[0].ID)
+}
+
+func TestMemoryRepositoriesRestore(t *testing.T) {
+	userRepo := NewMemoryUserRepository()
+	user := models.User{UserID: "user123", CreatedAt: "2023-01-01T00:00:00Z", UpdatedAt: "2023-02-01T00:00:00Z"}
+	assert.NoError(t, userRepo.ReplaceUser(user))
+	stored, _ := userRepo.GetUser("user123")
+	assert.Equal(t, &user, stored)
+
+	lotRepo := NewMemoryEsppLotRepository()
+	assert.NoError(t, lotRepo.BatchPutEsppLots([]models.EsppLot{{ID: "lot123", UserID: "user123", CreatedAt: "2023-01-01T00:00:00Z"}}))
+	lot, _ := lotRepo.GetEsppLot("lot123")
+	assert.Equal(t, "2023-01-01T00:00:00Z", lot.CreatedAt)
+
+	saleRepo := NewMemoryEsppSaleRepository()
+	assert.NoError(t, saleRepo.BatchPutEsppSales([]models.EsppSale{{ID: "sale123", LotID: "lot123", UserID: "user123"}}))
+	sale, _ := saleRepo.GetEsppSale("sale123")
+	assert.Equal(t, "lot123", sale.LotID)
+
+	planRepo := NewMemoryEsppPlanRepository()
+	assert.NoError(t, planRepo.BatchPutEsppPlans([]models.EsppPlan{{ID: "plan123", UserID: "user123"}}))
+	plan, _ := planRepo.GetEsppPlan("plan123")
+	assert.Equal(t, "user123", plan.UserID)
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+const (
+	// PriceHistoryTableName is keyed on ticker with date as the sort key.
+	PriceHistoryTableName = "fife-price-history"
+)
+
+// GetClosingPriceOnOrBefore returns the latest close of the ticker on or
+// before date, which covers dates that fall on weekends and market holidays.
+func GetClosingPriceOnOrBefore(svc dynamodbiface.DynamoDBAPI, ticker string, date string) (*models.ClosingPrice, error) {
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(PriceHistoryTableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"ticker": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(ticker),
+					},
+				},
+			},
+			"date": {
+				ComparisonOperator: aws.String("LE"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(date),
+					},
+				},
+			},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(1),
+	}
+
+	result, err := svc.Query(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	price := &models.ClosingPrice{}
+	err = dynamodbattribute.UnmarshalMap(result.Items[0], price)
+	if err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+// BatchPutClosingPrices writes the closes in chunks with BatchWriteItem,
+// replacing any close already recorded for the same ticker and date.
+func BatchPutClosingPrices(svc dynamodbiface.DynamoDBAPI, prices []models.ClosingPrice) error {
+	for start := 0; start < len(prices); start += batchWriteSize {
+		end := min(start+batchWriteSize, len(prices))
+
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, price := range prices[start:end] {
+			av, err := dynamodbattribute.MarshalMap(price)
+			if err != nil {
+				return err
+			}
+
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: av}})
+		}
+
+		if err := batchWrite(svc, PriceHistoryTableName, requests); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
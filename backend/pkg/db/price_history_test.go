@@ -0,0 +1,118 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPriceHistoryDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+	queryOutput      *dynamodb.QueryOutput
+	queryError       error
+	queryInputs      []*dynamodb.QueryInput
+	batchWriteError  error
+	batchWriteInputs []*dynamodb.BatchWriteItemInput
+}
+
+func (m *mockPriceHistoryDynamoDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	if *input.TableName != PriceHistoryTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.queryInputs = append(m.queryInputs, input)
+
+	return m.queryOutput, m.queryError
+}
+
+func (m *mockPriceHistoryDynamoDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	if _, ok := input.RequestItems[PriceHistoryTableName]; !ok {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.batchWriteInputs = append(m.batchWriteInputs, input)
+
+	return &dynamodb.BatchWriteItemOutput{}, m.batchWriteError
+}
+
+func TestGetClosingPriceOnOrBefore(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mockOutput    *dynamodb.QueryOutput
+		mockError     error
+		expectedPrice *models.ClosingPrice
+		expectedError bool
+	}{
+		{
+			name: "close found",
+			mockOutput: &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{{
+				"ticker": {S: aws.String("NKE")},
+				"date":   {S: aws.String("2023-03-31")},
+				"close":  {N: aws.String("120.1")},
+			}}},
+			expectedPrice: &models.ClosingPrice{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+		},
+		{
+			name:          "no close recorded",
+			mockOutput:    &dynamodb.QueryOutput{},
+			expectedPrice: nil,
+		},
+		{
+			name:          "dynamodb error",
+			mockError:     errors.New("dynamodb error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockPriceHistoryDynamoDBClient{
+				queryOutput: tc.mockOutput,
+				queryError:  tc.mockError,
+			}
+
+			price, err := GetClosingPriceOnOrBefore(mockSvc, "NKE", "2023-04-02")
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrice, price)
+
+			input := mockSvc.queryInputs[0]
+			assert.Equal(t, "LE", *input.KeyConditions["date"].ComparisonOperator)
+			assert.Equal(t, "2023-04-02", *input.KeyConditions["date"].AttributeValueList[0].S)
+			assert.False(t, *input.ScanIndexForward)
+			assert.Equal(t, int64(1), *input.Limit)
+		})
+	}
+}
+
+func TestBatchPutClosingPrices(t *testing.T) {
+	batchWriteBackoff = func(int) time.Duration { return 0 }
+
+	prices := make([]models.ClosingPrice, 30)
+	for i := range prices {
+		prices[i] = models.ClosingPrice{Ticker: "NKE", Date: time.Date(2023, 1, i+1, 0, 0, 0, 0, time.UTC).Format(models.DateLayout), Close: 100}
+	}
+
+	mockSvc := &mockPriceHistoryDynamoDBClient{}
+	assert.NoError(t, BatchPutClosingPrices(mockSvc, prices))
+
+	batchSizes := []int{}
+	for _, input := range mockSvc.batchWriteInputs {
+		batchSizes = append(batchSizes, len(input.RequestItems[PriceHistoryTableName]))
+	}
+	assert.Equal(t, []int{25, 5}, batchSizes)
+
+	err := BatchPutClosingPrices(&mockPriceHistoryDynamoDBClient{batchWriteError: errors.New("dynamodb error")}, prices)
+	assert.Error(t, err)
+}
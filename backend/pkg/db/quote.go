@@ -0,0 +1,57 @@
+package db
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+const (
+	QuotesTableName = "fife-quotes"
+)
+
+func GetQuote(svc dynamodbiface.DynamoDBAPI, ticker string) (*models.Quote, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(QuotesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"ticker": {
+				S: aws.String(ticker),
+			},
+		},
+	}
+
+	result, err := svc.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	quote := &models.Quote{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// PutQuote replaces the cached quote for the ticker.
+func PutQuote(svc dynamodbiface.DynamoDBAPI, quote models.Quote) error {
+	av, err := dynamodbattribute.MarshalMap(quote)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(QuotesTableName),
+		Item:      av,
+	}
+
+	_, err = svc.PutItem(input)
+	return err
+}
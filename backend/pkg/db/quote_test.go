@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockQuoteDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+	getItemOutput *dynamodb.GetItemOutput
+	getItemError  error
+	putItemError  error
+	putItemInputs []*dynamodb.PutItemInput
+}
+
+func (m *mockQuoteDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if *input.TableName != QuotesTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	return m.getItemOutput, m.getItemError
+}
+
+func (m *mockQuoteDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName != QuotesTableName {
+		return nil, errors.New("incorrect table name")
+	}
+
+	m.putItemInputs = append(m.putItemInputs, input)
+
+	return &dynamodb.PutItemOutput{}, m.putItemError
+}
+
+func TestGetQuote(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mockOutput    *dynamodb.GetItemOutput
+		mockError     error
+		expectedQuote *models.Quote
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockOutput: &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"ticker":    {S: aws.String("NKE")},
+				"price":     {N: aws.String("97.5")},
+				"date":      {S: aws.String("2026-10-16")},
+				"updatedAt": {S: aws.String("2026-10-16T21:00:00Z")},
+			}},
+			expectedQuote: &models.Quote{
+				Ticker:    "NKE",
+				Price:     97.5,
+				Date:      "2026-10-16",
+				UpdatedAt: "2026-10-16T21:00:00Z",
+			},
+		},
+		{
+			name:          "quote not found",
+			mockOutput:    &dynamodb.GetItemOutput{},
+			expectedQuote: nil,
+		},
+		{
+			name:          "dynamodb error",
+			mockError:     errors.New("dynamodb error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mockQuoteDynamoDBClient{
+				getItemOutput: tc.mockOutput,
+				getItemError:  tc.mockError,
+			}
+
+			quote, err := GetQuote(mockSvc, "NKE")
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedQuote, quote)
+			}
+		})
+	}
+}
+
+func TestPutQuote(t *testing.T) {
+	mockSvc := &mockQuoteDynamoDBClient{}
+
+	err := PutQuote(mockSvc, models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"})
+	assert.NoError(t, err)
+	assert.Len(t, mockSvc.putItemInputs, 1)
+	assert.Equal(t, "NKE", *mockSvc.putItemInputs[0].Item["ticker"].S)
+	assert.Equal(t, "97.5", *mockSvc.putItemInputs[0].Item["price"].N)
+
+	err = PutQuote(&mockQuoteDynamoDBClient{putItemError: errors.New("dynamodb error")}, models.Quote{Ticker: "NKE"})
+	assert.Error(t, err)
+}
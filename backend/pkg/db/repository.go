@@ -0,0 +1,252 @@
+package db
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+type UserRepository interface {
+	GetUser(userID string) (*models.User, error)
+	UpdateUserSettings(userID string, settings models.UserSettings, expectedVersion *int64) (*models.User, error)
+	SetCalendarTokenHash(userID string, tokenHash string) (bool, error)
+	ListUsers() ([]*models.User, error)
+	SetNotificationCheckpoints(userID string, checkpoints models.NotificationCheckpoints) (bool, error)
+	CreateUser(user models.User) (*models.User, error)
+	DeleteUser(userID string) error
+	ReplaceUser(user models.User) error
+}
+
+type EsppLotRepository interface {
+	CreateEsppLot(lotInput models.EsppLotInput) (*models.EsppLot, error)
+	BatchCreateEsppLots(lotInputs []models.EsppLotInput) ([]*models.EsppLot, error)
+	GetEsppLot(id string) (*models.EsppLot, error)
+	GetEsppLotsByUserID(userID string) ([]*models.EsppLot, error)
+	ListEsppLotsByUserID(options models.EsppLotListOptions) (*models.EsppLotPage, error)
+	UpdateEsppLot(id string, lotUpdate models.EsppLotUpdate, expectedVersion *int64) (*models.EsppLot, error)
+	DeleteEsppLot(id string) error
+	DeleteEsppLotsByUserID(userID string) (int, error)
+	BatchPutEsppLots(lots []models.EsppLot) error
+}
+
+type EsppSaleRepository interface {
+	CreateEsppSale(saleInput models.EsppSaleInput) (*models.EsppSale, error)
+	GetEsppSale(id string) (*models.EsppSale, error)
+	GetEsppSalesByLotID(lotID string) ([]*models.EsppSale, error)
+	DeleteEsppSale(id string) error
+	BatchDeleteEsppSales(ids []string) error
+	BatchPutEsppSales(sales []models.EsppSale) error
+}
+
+type EsppPlanRepository interface {
+	CreateEsppPlan(planInput models.EsppPlanInput) (*models.EsppPlan, error)
+	GetEsppPlan(id string) (*models.EsppPlan, error)
+	GetEsppPlansByUserID(userID string) ([]*models.EsppPlan, error)
+	UpdateEsppPlan(id string, planInput models.EsppPlanInput) (*models.EsppPlan, error)
+	DeleteEsppPlan(id string) error
+	DeleteEsppPlansByUserID(userID string) (int, error)
+	BatchPutEsppPlans(plans []models.EsppPlan) error
+}
+
+type QuoteRepository interface {
+	GetQuote(ticker string) (*models.Quote, error)
+	PutQuote(quote models.Quote) error
+}
+
+type PriceHistoryRepository interface {
+	GetClosingPriceOnOrBefore(ticker string, date string) (*models.ClosingPrice, error)
+	BatchPutClosingPrices(prices []models.ClosingPrice) error
+}
+
+func NewDynamoDBClient() dynamodbiface.DynamoDBAPI {
+	sess := session.Must(session.NewSession())
+	return dynamodb.New(sess, aws.NewConfig().WithRegion(os.Getenv("AWS_REGION")))
+}
+
+type DynamoDBUserRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBUserRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBUserRepository {
+	return &DynamoDBUserRepository{svc: svc}
+}
+
+func (r *DynamoDBUserRepository) GetUser(userID string) (*models.User, error) {
+	return GetUser(r.svc, userID)
+}
+
+func (r *DynamoDBUserRepository) UpdateUserSettings(userID string, settings models.UserSettings, expectedVersion *int64) (*models.User, error) {
+	return UpdateUserSettings(r.svc, userID, settings, expectedVersion)
+}
+
+func (r *DynamoDBUserRepository) SetCalendarTokenHash(userID string, tokenHash string) (bool, error) {
+	return SetCalendarTokenHash(r.svc, userID, tokenHash)
+}
+
+func (r *DynamoDBUserRepository) ListUsers() ([]*models.User, error) {
+	return ListUsers(r.svc)
+}
+
+func (r *DynamoDBUserRepository) SetNotificationCheckpoints(userID string, checkpoints models.NotificationCheckpoints) (bool, error) {
+	return SetNotificationCheckpoints(r.svc, userID, checkpoints)
+}
+
+func (r *DynamoDBUserRepository) CreateUser(user models.User) (*models.User, error) {
+	return CreateUser(r.svc, user)
+}
+
+func (r *DynamoDBUserRepository) DeleteUser(userID string) error {
+	return DeleteUser(r.svc, userID)
+}
+
+func (r *DynamoDBUserRepository) ReplaceUser(user models.User) error {
+	return ReplaceUser(r.svc, user)
+}
+
+type DynamoDBEsppLotRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBEsppLotRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBEsppLotRepository {
+	return &DynamoDBEsppLotRepository{svc: svc}
+}
+
+func (r *DynamoDBEsppLotRepository) CreateEsppLot(lotInput models.EsppLotInput) (*models.EsppLot, error) {
+	return CreateEsppLot(r.svc, lotInput)
+}
+
+func (r *DynamoDBEsppLotRepository) BatchCreateEsppLots(lotInputs []models.EsppLotInput) ([]*models.EsppLot, error) {
+	return BatchCreateEsppLots(r.svc, lotInputs)
+}
+
+func (r *DynamoDBEsppLotRepository) GetEsppLot(id string) (*models.EsppLot, error) {
+	return GetEsppLot(r.svc, id)
+}
+
+func (r *DynamoDBEsppLotRepository) GetEsppLotsByUserID(userID string) ([]*models.EsppLot, error) {
+	return GetEsppLotsByUserID(r.svc, userID)
+}
+
+func (r *DynamoDBEsppLotRepository) ListEsppLotsByUserID(options models.EsppLotListOptions) (*models.EsppLotPage, error) {
+	return ListEsppLotsByUserID(r.svc, options)
+}
+
+func (r *DynamoDBEsppLotRepository) UpdateEsppLot(id string, lotUpdate models.EsppLotUpdate, expectedVersion *int64) (*models.EsppLot, error) {
+	return UpdateEsppLot(r.svc, id, lotUpdate, expectedVersion)
+}
+
+func (r *DynamoDBEsppLotRepository) DeleteEsppLot(id string) error {
+	return DeleteEsppLot(r.svc, id)
+}
+
+func (r *DynamoDBEsppLotRepository) DeleteEsppLotsByUserID(userID string) (int, error) {
+	return DeleteEsppLotsByUserID(r.svc, userID)
+}
+
+func (r *DynamoDBEsppLotRepository) BatchPutEsppLots(lots []models.EsppLot) error {
+	return BatchPutEsppLots(r.svc, lots)
+}
+
+type DynamoDBEsppSaleRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBEsppSaleRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBEsppSaleRepository {
+	return &DynamoDBEsppSaleRepository{svc: svc}
+}
+
+func (r *DynamoDBEsppSaleRepository) CreateEsppSale(saleInput models.EsppSaleInput) (*models.EsppSale, error) {
+	return CreateEsppSale(r.svc, saleInput)
+}
+
+func (r *DynamoDBEsppSaleRepository) GetEsppSale(id string) (*models.EsppSale, error) {
+	return GetEsppSale(r.svc, id)
+}
+
+func (r *DynamoDBEsppSaleRepository) GetEsppSalesByLotID(lotID string) ([]*models.EsppSale, error) {
+	return GetEsppSalesByLotID(r.svc, lotID)
+}
+
+func (r *DynamoDBEsppSaleRepository) DeleteEsppSale(id string) error {
+	return DeleteEsppSale(r.svc, id)
+}
+
+func (r *DynamoDBEsppSaleRepository) BatchDeleteEsppSales(ids []string) error {
+	return BatchDeleteEsppSales(r.svc, ids)
+}
+
+func (r *DynamoDBEsppSaleRepository) BatchPutEsppSales(sales []models.EsppSale) error {
+	return BatchPutEsppSales(r.svc, sales)
+}
+
+type DynamoDBEsppPlanRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBEsppPlanRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBEsppPlanRepository {
+	return &DynamoDBEsppPlanRepository{svc: svc}
+}
+
+func (r *DynamoDBEsppPlanRepository) CreateEsppPlan(planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	return CreateEsppPlan(r.svc, planInput)
+}
+
+func (r *DynamoDBEsppPlanRepository) GetEsppPlan(id string) (*models.EsppPlan, error) {
+	return GetEsppPlan(r.svc, id)
+}
+
+func (r *DynamoDBEsppPlanRepository) GetEsppPlansByUserID(userID string) ([]*models.EsppPlan, error) {
+	return GetEsppPlansByUserID(r.svc, userID)
+}
+
+func (r *DynamoDBEsppPlanRepository) UpdateEsppPlan(id string, planInput models.EsppPlanInput) (*models.EsppPlan, error) {
+	return UpdateEsppPlan(r.svc, id, planInput)
+}
+
+func (r *DynamoDBEsppPlanRepository) DeleteEsppPlan(id string) error {
+	return DeleteEsppPlan(r.svc, id)
+}
+
+func (r *DynamoDBEsppPlanRepository) DeleteEsppPlansByUserID(userID string) (int, error) {
+	return DeleteEsppPlansByUserID(r.svc, userID)
+}
+
+func (r *DynamoDBEsppPlanRepository) BatchPutEsppPlans(plans []models.EsppPlan) error {
+	return BatchPutEsppPlans(r.svc, plans)
+}
+
+type DynamoDBQuoteRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBQuoteRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBQuoteRepository {
+	return &DynamoDBQuoteRepository{svc: svc}
+}
+
+func (r *DynamoDBQuoteRepository) GetQuote(ticker string) (*models.Quote, error) {
+	return GetQuote(r.svc, ticker)
+}
+
+func (r *DynamoDBQuoteRepository) PutQuote(quote models.Quote) error {
+	return PutQuote(r.svc, quote)
+}
+
+type DynamoDBPriceHistoryRepository struct {
+	svc dynamodbiface.DynamoDBAPI
+}
+
+func NewDynamoDBPriceHistoryRepository(svc dynamodbiface.DynamoDBAPI) *DynamoDBPriceHistoryRepository {
+	return &DynamoDBPriceHistoryRepository{svc: svc}
+}
+
+func (r *DynamoDBPriceHistoryRepository) GetClosingPriceOnOrBefore(ticker string, date string) (*models.ClosingPrice, error) {
+	return GetClosingPriceOnOrBefore(r.svc, ticker, date)
+}
+
+func (r *DynamoDBPriceHistoryRepository) BatchPutClosingPrices(prices []models.ClosingPrice) error {
+	return BatchPutClosingPrices(r.svc, prices)
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// setNextVersion adds one to the item's version. Items saved before versions
+// were added have none and count as version 0.
+func setNextVersion(update expression.UpdateBuilder) expression.UpdateBuilder {
+	return update.Set(expression.Name("version"), expression.Value(1).Plus(expression.Name("version").IfNotExists(expression.Value(0))))
+}
+
+// versionCondition matches an item whose key attribute exists and whose
+// version is expectedVersion, counting a missing version as 0.
+func versionCondition(keyName string, expectedVersion int64) expression.ConditionBuilder {
+	version := expression.Name("version").Equal(expression.Value(expectedVersion))
+	if expectedVersion == 0 {
+		version = version.Or(expression.AttributeNotExists(expression.Name("version")))
+	}
+
+	return expression.AttributeExists(expression.Name(keyName)).And(version)
+}
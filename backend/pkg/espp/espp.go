@@ -0,0 +1,241 @@
+package espp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/tax"
+)
+
+const (
+	NIITRate = tax.NIITRate
+
+	DateLayout = models.DateLayout
+)
+
+// Calculator prices the tax on ESPP income added on top of the user's other
+// income. Rates and tax.Calculator both satisfy it.
+type Calculator interface {
+	IncrementalTax(added tax.Income) tax.Breakdown
+}
+
+// Rates are the tax rates, as fractions, applied to each kind of ESPP income.
+// The discount is compensation and pays the ordinary rate, while gains from the
+// market also owe the net investment income tax when it applies.
+type Rates struct {
+	OrdinaryIncome        float64 `json:"ordinaryIncome"`
+	ShortTermCapitalGains float64 `json:"shortTermCapitalGains"`
+	LongTermCapitalGains  float64 `json:"longTermCapitalGains"`
+}
+
+// DefaultRates are used for users who have not saved a tax profile.
+var DefaultRates = Rates{
+	OrdinaryIncome:        0.24,
+	ShortTermCapitalGains: 0.24,
+	LongTermCapitalGains:  0.15,
+}
+
+// IncrementalTax applies the flat rates. Losses offset ordinary income, so they
+// are valued at the ordinary rate.
+func (r Rates) IncrementalTax(added tax.Income) tax.Breakdown {
+	breakdown := tax.Breakdown{
+		Ordinary:              added.Ordinary * r.OrdinaryIncome,
+		ShortTermCapitalGains: added.ShortTermCapitalGains * r.OrdinaryIncome,
+		LongTermCapitalGains:  added.LongTermCapitalGains * r.OrdinaryIncome,
+	}
+
+	if added.ShortTermCapitalGains >= 0 {
+		breakdown.ShortTermCapitalGains = added.ShortTermCapitalGains * r.ShortTermCapitalGains
+	}
+	if added.LongTermCapitalGains >= 0 {
+		breakdown.LongTermCapitalGains = added.LongTermCapitalGains * r.LongTermCapitalGains
+	}
+
+	breakdown.Total = breakdown.Ordinary + breakdown.ShortTermCapitalGains + breakdown.LongTermCapitalGains
+
+	return breakdown
+}
+
+// CalculatorForSettings picks how to price a user's ESPP taxes for a tax year.
+// Users with a tax profile and a salary get the bracket tables stacked on top
+// of their salary. Without a salary the profile's flat rates are used, and
+// without a profile the defaults are.
+func CalculatorForSettings(settings models.UserSettings, year int) (Calculator, error) {
+	profile := settings.TaxProfile
+	if profile == nil || settings.Finance.AnnualSalary <= 0 {
+		return RatesForProfile(profile), nil
+	}
+
+	table, err := tax.TableFor(year, profile.FilingStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return tax.Calculator{
+		Table:     table,
+		Base:      tax.Income{Ordinary: settings.Finance.AnnualSalary},
+		StateRate: profile.StateRate / 100,
+		NIIT:      profile.NIIT,
+	}, nil
+}
+
+// RatesForProfile combines the federal, state, and NIIT rates of a tax profile.
+// A nil profile gets DefaultRates.
+func RatesForProfile(profile *models.TaxProfile) Rates {
+	if profile == nil {
+		return DefaultRates
+	}
+
+	state := profile.StateRate / 100
+	niit := 0.0
+	if profile.NIIT {
+		niit = NIITRate
+	}
+
+	ordinary := profile.OrdinaryIncomeRate/100 + state
+
+	return Rates{
+		OrdinaryIncome:        ordinary,
+		ShortTermCapitalGains: ordinary + niit,
+		LongTermCapitalGains:  profile.LongTermCapitalGainsRate/100 + state + niit,
+	}
+}
+
+type Outcome string
+
+const (
+	OutcomeGood   Outcome = "GOOD"
+	OutcomeBetter Outcome = "BETTER"
+	OutcomeBest   Outcome = "BEST"
+)
+
+type DispositionName string
+
+const (
+	DispositionDisqualifyingSTCG DispositionName = "Disqualifying Disposition w/ STCG"
+	DispositionDisqualifyingLTCG DispositionName = "Disqualifying Disposition w/ LTCG"
+	DispositionQualifying        DispositionName = "Qualifying Disposition"
+)
+
+type Gains struct {
+	DiscountAmount float64 `json:"discountAmount"`
+	Market         float64 `json:"market"`
+	Total          float64 `json:"total"`
+}
+
+type Taxes struct {
+	OrdinaryIncome float64 `json:"ordinaryIncome"`
+	STCG           float64 `json:"stcg"`
+	LTCG           float64 `json:"ltcg"`
+	Total          float64 `json:"total"`
+}
+
+// Disposition describes the taxes owed when selling under one holding period.
+// EndDate is the last day the disposition applies, and is empty for the
+// qualifying disposition because it never ends.
+type Disposition struct {
+	Name    DispositionName `json:"name"`
+	Taxes   Taxes           `json:"taxes"`
+	Outcome Outcome         `json:"outcome"`
+	EndDate string          `json:"endDate,omitempty"`
+}
+
+type Dispositions struct {
+	DisqualifyingSTCG Disposition `json:"disqualifyingSTCG"`
+	DisqualifyingLTCG Disposition `json:"disqualifyingLTCG"`
+	Qualifying        Disposition `json:"qualifying"`
+}
+
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse(DateLayout, date)
+}
+
+func FormatDate(date time.Time) string {
+	return date.Format(DateLayout)
+}
+
+// LongTermDate is when shares bought on purchaseDate start to receive long-term
+// capital gains treatment.
+func LongTermDate(purchaseDate time.Time) time.Time {
+	return purchaseDate.AddDate(1, 0, 0)
+}
+
+// QualifyingDate is when a sale becomes a qualifying disposition, which is the
+// later of two years after the grant and one year after the purchase.
+func QualifyingDate(grantDate time.Time, purchaseDate time.Time) time.Time {
+	twoYearsAfterGrantDate := grantDate.AddDate(2, 0, 0)
+	oneYearAfterPurchaseDate := LongTermDate(purchaseDate)
+
+	if twoYearsAfterGrantDate.After(oneYearAfterPurchaseDate) {
+		return twoYearsAfterGrantDate
+	}
+
+	return oneYearAfterPurchaseDate
+}
+
+// DefaultPlan is the common plan design of a 15% discount with a lookback to
+// the offering start price. Lots without a known plan are priced with it.
+var DefaultPlan = models.EsppPlan{
+	Name:                 "Default",
+	DiscountPercent:      15,
+	Lookback:             true,
+	OfferingPeriodMonths: 6,
+	PurchasePeriodMonths: 6,
+	ResetRule:            models.EsppResetRuleNone,
+}
+
+// PlanFor returns the plan a lot was bought under: the plan it references,
+// else the user's plan for the lot's employer, else DefaultPlan.
+func PlanFor(lot *models.EsppLot, plans []*models.EsppPlan) *models.EsppPlan {
+	if lot.PlanID != "" {
+		for _, plan := range plans {
+			if plan.ID == lot.PlanID {
+				return plan
+			}
+		}
+	}
+
+	if lot.Employer != "" {
+		for _, plan := range plans {
+			if plan.MatchesEmployer(lot.Employer) {
+				return plan
+			}
+		}
+	}
+
+	plan := DefaultPlan
+	return &plan
+}
+
+func discountRate(plan *models.EsppPlan) float64 {
+	return plan.DiscountPercent / 100
+}
+
+var ErrMissingMarketPrice = errors.New("no market price for lot")
+
+// MarketPrices holds the current price of each ticker a user holds. Default
+// prices lots whose ticker is not listed, including lots saved without one.
+type MarketPrices struct {
+	Default  float64
+	ByTicker map[string]float64
+}
+
+// PriceFor returns the market price for the lot's ticker, or
+// ErrMissingMarketPrice when neither the ticker nor a default is priced.
+func (p MarketPrices) PriceFor(lot *models.EsppLot) (float64, error) {
+	if price, ok := p.ByTicker[models.NormalizeTicker(lot.Ticker)]; ok && lot.Ticker != "" {
+		return price, nil
+	}
+
+	if p.Default > 0 {
+		return p.Default, nil
+	}
+
+	if lot.Ticker == "" {
+		return 0, fmt.Errorf("%w %s", ErrMissingMarketPrice, lot.ID)
+	}
+
+	return 0, fmt.Errorf("%w %s: %s", ErrMissingMarketPrice, lot.ID, lot.Ticker)
+}
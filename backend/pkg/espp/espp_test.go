@@ -0,0 +1,147 @@
+package espp
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatesForProfile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		profile       *models.TaxProfile
+		expectedRates Rates
+	}{
+		{
+			name:          "no profile",
+			profile:       nil,
+			expectedRates: DefaultRates,
+		},
+		{
+			name: "federal only",
+			profile: &models.TaxProfile{
+				FilingStatus:             models.FilingStatusSingle,
+				OrdinaryIncomeRate:       22,
+				LongTermCapitalGainsRate: 15,
+			},
+			expectedRates: Rates{OrdinaryIncome: 0.22, ShortTermCapitalGains: 0.22, LongTermCapitalGains: 0.15},
+		},
+		{
+			name: "state and NIIT",
+			profile: &models.TaxProfile{
+				FilingStatus:             models.FilingStatusMarriedFilingJointly,
+				OrdinaryIncomeRate:       32,
+				LongTermCapitalGainsRate: 15,
+				StateRate:                5,
+				NIIT:                     true,
+			},
+			expectedRates: Rates{OrdinaryIncome: 0.37, ShortTermCapitalGains: 0.408, LongTermCapitalGains: 0.238},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rates := RatesForProfile(tc.profile)
+
+			assert.InDelta(t, tc.expectedRates.OrdinaryIncome, rates.OrdinaryIncome, 1e-9)
+			assert.InDelta(t, tc.expectedRates.ShortTermCapitalGains, rates.ShortTermCapitalGains, 1e-9)
+			assert.InDelta(t, tc.expectedRates.LongTermCapitalGains, rates.LongTermCapitalGains, 1e-9)
+		})
+	}
+}
+
+func TestCalculatorForSettings(t *testing.T) {
+	profile := &models.TaxProfile{
+		FilingStatus:             models.FilingStatusSingle,
+		OrdinaryIncomeRate:       22,
+		LongTermCapitalGainsRate: 15,
+	}
+
+	calculator, err := CalculatorForSettings(models.UserSettings{}, 2026)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultRates, calculator)
+
+	calculator, err = CalculatorForSettings(models.UserSettings{TaxProfile: profile}, 2026)
+	assert.NoError(t, err)
+	assert.Equal(t, RatesForProfile(profile), calculator)
+
+	calculator, err = CalculatorForSettings(models.UserSettings{
+		Finance:    models.UserFinanceSettings{AnnualSalary: 100000, PaychecksPerYear: 26},
+		TaxProfile: profile,
+	}, 2026)
+	assert.NoError(t, err)
+
+	// $30,000 on a $100,000 salary runs from the 22% bracket into the 24%.
+	breakdown := calculator.IncrementalTax(tax.Income{Ordinary: 30000})
+	assert.InDelta(t, 6764, breakdown.Total, 0.005)
+
+	_, err = CalculatorForSettings(models.UserSettings{
+		Finance:    models.UserFinanceSettings{AnnualSalary: 100000, PaychecksPerYear: 26},
+		TaxProfile: &models.TaxProfile{FilingStatus: "joint"},
+	}, 2026)
+	assert.ErrorIs(t, err, tax.ErrUnknownFilingStatus)
+}
+
+func TestMarketPricesPriceFor(t *testing.T) {
+	prices := MarketPrices{Default: 100, ByTicker: map[string]float64{"NKE": 125.1}}
+
+	price, err := prices.PriceFor(&models.EsppLot{ID: "lot1", Ticker: "NKE"})
+	assert.NoError(t, err)
+	assert.Equal(t, 125.1, price)
+
+	price, err = prices.PriceFor(&models.EsppLot{ID: "lot2", Ticker: "AAPL"})
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+
+	price, err = prices.PriceFor(&models.EsppLot{ID: "lot3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+
+	_, err = MarketPrices{ByTicker: prices.ByTicker}.PriceFor(&models.EsppLot{ID: "lot2", Ticker: "AAPL"})
+	assert.ErrorIs(t, err, ErrMissingMarketPrice)
+}
+
+func TestPlanFor(t *testing.T) {
+	acme := &models.EsppPlan{ID: "plan1", Name: "Acme", Employer: "Acme", DiscountPercent: 10}
+	other := &models.EsppPlan{ID: "plan2", Name: "Other", DiscountPercent: 5}
+	plans := []*models.EsppPlan{acme, other}
+
+	testCases := []struct {
+		name     string
+		lot      *models.EsppLot
+		expected string
+	}{
+		{
+			name:     "referenced plan",
+			lot:      &models.EsppLot{PlanID: "plan2", Employer: "Acme"},
+			expected: "plan2",
+		},
+		{
+			name:     "employer plan",
+			lot:      &models.EsppLot{Employer: " acme "},
+			expected: "plan1",
+		},
+		{
+			name:     "unknown plan falls back to employer",
+			lot:      &models.EsppLot{PlanID: "missing", Employer: "ACME"},
+			expected: "plan1",
+		},
+		{
+			name:     "default plan",
+			lot:      &models.EsppLot{Employer: "Globex"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := PlanFor(tc.lot, plans)
+			assert.Equal(t, tc.expected, plan.ID)
+		})
+	}
+
+	plan := PlanFor(&models.EsppLot{}, nil)
+	assert.Equal(t, DefaultPlan, *plan)
+}
@@ -0,0 +1,215 @@
+package espp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// Section423AnnualLimit is the value of stock, measured at the fair market
+// value on the grant date, an employee may accrue the right to buy in each
+// calendar year an offering is outstanding.
+const Section423AnnualLimit = 25000.0
+
+// LimitYear is how much of a calendar year's limit the recorded purchases used.
+// Excess is the value bought that year that no unused limit could cover.
+type LimitYear struct {
+	Year      int     `json:"year"`
+	Limit     float64 `json:"limit"`
+	Accrued   float64 `json:"accrued"`
+	Remaining float64 `json:"remaining"`
+	Excess    float64 `json:"excess"`
+}
+
+// PlannedPurchase is an upcoming purchase in the offering granted on
+// GrantDate. OfferStartPrice is zero when the grant price is not known yet,
+// and a ShareCap of zero means the plan has no per-purchase cap.
+type PlannedPurchase struct {
+	GrantDate       string  `json:"grantDate"`
+	PurchaseDate    string  `json:"purchaseDate"`
+	OfferStartPrice float64 `json:"offerStartPrice,omitempty"`
+	ShareCap        float64 `json:"shareCap,omitempty"`
+}
+
+// PurchaseHeadroom is the unused limit a planned purchase may draw on, and the
+// shares it buys when the grant price is known.
+type PurchaseHeadroom struct {
+	PlannedPurchase
+	Headroom  float64  `json:"headroom"`
+	MaxShares *float64 `json:"maxShares,omitempty"`
+}
+
+type Section423Limit struct {
+	Years        []LimitYear       `json:"years"`
+	NextPurchase *PurchaseHeadroom `json:"nextPurchase,omitempty"`
+}
+
+// CalculateSection423Limit charges the grant-date value of each lot against
+// the limit. A purchase first uses the limit of its offering's grant year and
+// carries forward into each later year the offering was outstanding, up to
+// the year of the purchase. Limit never carries from one offering to another,
+// so overlapping offerings share each year's limit in purchase order.
+func CalculateSection423Limit(lots []*models.EsppLot, next *PlannedPurchase) (*Section423Limit, error) {
+	type limitPurchase struct {
+		dates lotDates
+		value float64
+	}
+
+	purchases := make([]limitPurchase, 0, len(lots))
+	for _, lot := range lots {
+		dates, err := parseLotDates(lot)
+		if err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, limitPurchase{dates: dates, value: lot.OfferStartPrice * lot.Shares})
+	}
+
+	sort.SliceStable(purchases, func(i, j int) bool {
+		return purchases[i].dates.purchase.Before(purchases[j].dates.purchase)
+	})
+
+	ledger := newLimitLedger()
+	for _, purchase := range purchases {
+		ledger.charge(purchase.dates.grant.Year(), purchase.dates.purchase.Year(), purchase.value)
+	}
+
+	limit := &Section423Limit{}
+	if next != nil {
+		grantDate, err := ParseDate(next.GrantDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grant date for planned purchase: %w", err)
+		}
+
+		purchaseDate, err := ParseDate(next.PurchaseDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid purchase date for planned purchase: %w", err)
+		}
+
+		if purchaseDate.Before(grantDate) {
+			return nil, fmt.Errorf("planned purchase on %s is before its grant on %s", next.PurchaseDate, next.GrantDate)
+		}
+
+		ledger.extend(grantDate.Year(), purchaseDate.Year())
+		headroom := &PurchaseHeadroom{
+			PlannedPurchase: *next,
+			Headroom:        ledger.available(grantDate.Year(), purchaseDate.Year()),
+		}
+
+		if next.OfferStartPrice > 0 {
+			// Rounded down so the purchase stays under the limit.
+			shares := math.Floor(headroom.Headroom/next.OfferStartPrice*10000) / 10000
+			if next.ShareCap > 0 {
+				shares = math.Min(shares, next.ShareCap)
+			}
+			headroom.MaxShares = &shares
+		}
+
+		limit.NextPurchase = headroom
+	}
+
+	limit.Years = ledger.years()
+
+	return limit, nil
+}
+
+// ProjectNextPurchase predicts the purchase after the latest lot from the
+// plan's purchase period. It stays in the lot's offering while the offering
+// runs, unless the plan resets offerings after a price drop. Otherwise a new
+// offering starts the day after the purchase, at a grant price that is only
+// known for a reset, where it is the lot's purchase-date price.
+func ProjectNextPurchase(latest *models.EsppLot, plan *models.EsppPlan) (*PlannedPurchase, error) {
+	dates, err := parseLotDates(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseDate := dates.purchase.AddDate(0, plan.PurchasePeriodMonths, 0)
+	offeringEnd := dates.grant.AddDate(0, plan.OfferingPeriodMonths, 0)
+	next := &PlannedPurchase{
+		GrantDate:       latest.GrantDate,
+		PurchaseDate:    FormatDate(purchaseDate),
+		OfferStartPrice: latest.OfferStartPrice,
+		ShareCap:        plan.MaxSharesPerPurchase,
+	}
+
+	inOffering := !purchaseDate.After(offeringEnd)
+	reset := plan.ResetRule == models.EsppResetRuleLowerPrice && latest.OfferEndPrice < latest.OfferStartPrice
+
+	if !inOffering || reset {
+		next.GrantDate = FormatDate(dates.purchase.AddDate(0, 0, 1))
+		next.OfferStartPrice = 0
+		if inOffering {
+			next.OfferStartPrice = latest.OfferEndPrice
+		}
+	}
+
+	return next, nil
+}
+
+// limitLedger tracks how much of each calendar year's limit has been used.
+type limitLedger struct {
+	charged map[int]float64
+	excess  map[int]float64
+	first   int
+	last    int
+}
+
+func newLimitLedger() *limitLedger {
+	return &limitLedger{charged: map[int]float64{}, excess: map[int]float64{}}
+}
+
+func (l *limitLedger) extend(firstYear int, lastYear int) {
+	if l.first == 0 || firstYear < l.first {
+		l.first = firstYear
+	}
+	if lastYear > l.last {
+		l.last = lastYear
+	}
+}
+
+func (l *limitLedger) charge(grantYear int, purchaseYear int, value float64) {
+	l.extend(grantYear, purchaseYear)
+
+	for year := grantYear; year <= purchaseYear && value > 0; year++ {
+		used := math.Min(value, Section423AnnualLimit-l.charged[year])
+		if used <= 0 {
+			continue
+		}
+		l.charged[year] += used
+		value -= used
+	}
+
+	if value > 0 {
+		l.excess[purchaseYear] += value
+	}
+}
+
+func (l *limitLedger) available(grantYear int, purchaseYear int) float64 {
+	available := 0.0
+	for year := grantYear; year <= purchaseYear; year++ {
+		available += math.Max(Section423AnnualLimit-l.charged[year], 0)
+	}
+
+	return available
+}
+
+func (l *limitLedger) years() []LimitYear {
+	years := []LimitYear{}
+	if l.first == 0 {
+		return years
+	}
+
+	for year := l.first; year <= l.last; year++ {
+		years = append(years, LimitYear{
+			Year:      year,
+			Limit:     Section423AnnualLimit,
+			Accrued:   l.charged[year],
+			Remaining: Section423AnnualLimit - l.charged[year],
+			Excess:    l.excess[year],
+		})
+	}
+
+	return years
+}
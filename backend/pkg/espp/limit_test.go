@@ -0,0 +1,133 @@
+package espp
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateSection423Limit(t *testing.T) {
+	lots := []*models.EsppLot{
+		// A second offering overlapping the first, bought after it in 2024.
+		{ID: "3", GrantDate: "2024-01-01", PurchaseDate: "2024-06-28", OfferStartPrice: 50, Shares: 300},
+		// A two year offering that carries its unused 2023 limit into 2024.
+		{ID: "1", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 100, Shares: 100},
+		{ID: "2", GrantDate: "2023-07-01", PurchaseDate: "2024-06-28", OfferStartPrice: 100, Shares: 300},
+	}
+
+	limit, err := CalculateSection423Limit(lots, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, limit.NextPurchase)
+	assert.Equal(t, []LimitYear{
+		{Year: 2023, Limit: 25000, Accrued: 25000, Remaining: 0, Excess: 0},
+		{Year: 2024, Limit: 25000, Accrued: 25000, Remaining: 0, Excess: 5000},
+	}, limit.Years)
+}
+
+func TestCalculateSection423LimitNextPurchase(t *testing.T) {
+	lots := []*models.EsppLot{
+		{ID: "1", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 100, Shares: 100},
+		{ID: "2", GrantDate: "2023-07-01", PurchaseDate: "2024-06-28", OfferStartPrice: 100, Shares: 30},
+	}
+
+	testCases := []struct {
+		name              string
+		next              PlannedPurchase
+		expectedHeadroom  float64
+		expectedMaxShares *float64
+		expectedYears     int
+	}{
+		{
+			name:              "carries forward within the offering",
+			next:              PlannedPurchase{GrantDate: "2023-07-01", PurchaseDate: "2024-12-31", OfferStartPrice: 100},
+			expectedHeadroom:  37000,
+			expectedMaxShares: floatPtr(370),
+			expectedYears:     2,
+		},
+		{
+			name:              "new offering only uses its own years",
+			next:              PlannedPurchase{GrantDate: "2025-01-01", PurchaseDate: "2025-06-30", OfferStartPrice: 30},
+			expectedHeadroom:  25000,
+			expectedMaxShares: floatPtr(833.3333),
+			expectedYears:     3,
+		},
+		{
+			name:              "plan share cap",
+			next:              PlannedPurchase{GrantDate: "2025-01-01", PurchaseDate: "2025-06-30", OfferStartPrice: 30, ShareCap: 500},
+			expectedHeadroom:  25000,
+			expectedMaxShares: floatPtr(500),
+			expectedYears:     3,
+		},
+		{
+			name:             "unknown grant price",
+			next:             PlannedPurchase{GrantDate: "2024-06-29", PurchaseDate: "2024-12-31"},
+			expectedHeadroom: 25000,
+			expectedYears:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := CalculateSection423Limit(lots, &tc.next)
+
+			assert.NoError(t, err)
+			assert.Len(t, limit.Years, tc.expectedYears)
+			assert.Equal(t, tc.next, limit.NextPurchase.PlannedPurchase)
+			assert.InDelta(t, tc.expectedHeadroom, limit.NextPurchase.Headroom, 0.005)
+			assert.Equal(t, tc.expectedMaxShares, limit.NextPurchase.MaxShares)
+		})
+	}
+}
+
+func TestCalculateSection423LimitInvalidDates(t *testing.T) {
+	_, err := CalculateSection423Limit([]*models.EsppLot{{ID: "1", GrantDate: "banana", PurchaseDate: "2023-06-30"}}, nil)
+	assert.Error(t, err)
+
+	_, err = CalculateSection423Limit(nil, &PlannedPurchase{GrantDate: "2024-01-01", PurchaseDate: "2023-12-31"})
+	assert.Error(t, err)
+
+	limit, err := CalculateSection423Limit(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []LimitYear{}, limit.Years)
+}
+
+func TestProjectNextPurchase(t *testing.T) {
+	lot := &models.EsppLot{ID: "1", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 80, PurchasePrice: 68, Shares: 10}
+
+	testCases := []struct {
+		name     string
+		plan     models.EsppPlan
+		expected PlannedPurchase
+	}{
+		{
+			name:     "offering ends with the purchase",
+			plan:     DefaultPlan,
+			expected: PlannedPurchase{GrantDate: "2023-07-01", PurchaseDate: "2023-12-30"},
+		},
+		{
+			name:     "offering continues",
+			plan:     models.EsppPlan{OfferingPeriodMonths: 24, PurchasePeriodMonths: 6, ResetRule: models.EsppResetRuleNone, MaxSharesPerPurchase: 50},
+			expected: PlannedPurchase{GrantDate: "2023-01-01", PurchaseDate: "2023-12-30", OfferStartPrice: 100, ShareCap: 50},
+		},
+		{
+			name:     "offering resets at the lower price",
+			plan:     models.EsppPlan{OfferingPeriodMonths: 24, PurchasePeriodMonths: 6, ResetRule: models.EsppResetRuleLowerPrice},
+			expected: PlannedPurchase{GrantDate: "2023-07-01", PurchaseDate: "2023-12-30", OfferStartPrice: 80},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := ProjectNextPurchase(lot, &tc.plan)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &tc.expected, next)
+		})
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
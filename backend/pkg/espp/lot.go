@@ -0,0 +1,200 @@
+package espp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/tax"
+)
+
+type LotTaxes struct {
+	Lot                 *models.EsppLot `json:"lot"`
+	PlanID              string          `json:"planId,omitempty"`
+	MarketPrice         float64         `json:"marketPrice"`
+	PurchaseMarketPrice float64         `json:"purchaseMarketPrice"`
+	Gains               Gains           `json:"gains"`
+	Dispositions        Dispositions    `json:"dispositions"`
+	LongTermDate        string          `json:"longTermDate"`
+	QualifyingDate      string          `json:"qualifyingDate"`
+}
+
+// lotDates holds the parsed dates of a lot so they are only parsed once.
+type lotDates struct {
+	grant      time.Time
+	purchase   time.Time
+	longTerm   time.Time
+	qualifying time.Time
+}
+
+func parseLotDates(lot *models.EsppLot) (lotDates, error) {
+	grantDate, err := ParseDate(lot.GrantDate)
+	if err != nil {
+		return lotDates{}, fmt.Errorf("invalid grant date for lot %s: %w", lot.ID, err)
+	}
+
+	purchaseDate, err := ParseDate(lot.PurchaseDate)
+	if err != nil {
+		return lotDates{}, fmt.Errorf("invalid purchase date for lot %s: %w", lot.ID, err)
+	}
+
+	return lotDates{
+		grant:      grantDate,
+		purchase:   purchaseDate,
+		longTerm:   LongTermDate(purchaseDate),
+		qualifying: QualifyingDate(grantDate, purchaseDate),
+	}, nil
+}
+
+// CalculateLotTaxes computes the gains and the taxes under each disposition if
+// the whole lot were sold at marketPrice, following the discount and lookback
+// of the plan the lot was bought under.
+func CalculateLotTaxes(lot *models.EsppLot, plan *models.EsppPlan, marketPrice float64, calculator Calculator) (*LotTaxes, error) {
+	dates, err := parseLotDates(lot)
+	if err != nil {
+		return nil, err
+	}
+
+	discountAmount := calculateDiscountAmount(lot.OfferEndPrice, lot.PurchasePrice, lot.Shares)
+	marketGain := calculateMarketGain(lot.OfferEndPrice, marketPrice, lot.Shares)
+
+	gains := Gains{
+		DiscountAmount: discountAmount,
+		Market:         marketGain,
+		Total:          discountAmount + marketGain,
+	}
+
+	return &LotTaxes{
+		Lot:                 lot,
+		PlanID:              plan.ID,
+		MarketPrice:         marketPrice,
+		PurchaseMarketPrice: calculatePurchaseMarketPrice(plan, lot.OfferStartPrice, lot.OfferEndPrice),
+		Gains:               gains,
+		Dispositions: Dispositions{
+			DisqualifyingSTCG: disqualifyingSTCGDisposition(lot, plan, dates, gains, marketPrice, calculator),
+			DisqualifyingLTCG: disqualifyingLTCGDisposition(lot, dates, gains, marketPrice, calculator),
+			Qualifying:        qualifyingDisposition(lot, plan, marketPrice, lot.Shares, calculator),
+		},
+		LongTermDate:   FormatDate(dates.longTerm),
+		QualifyingDate: FormatDate(dates.qualifying),
+	}, nil
+}
+
+// calculatePurchaseMarketPrice is the price the plan discount applies to. A
+// lookback uses the lower of the offering start and end prices.
+func calculatePurchaseMarketPrice(plan *models.EsppPlan, offerStartPrice float64, offerEndPrice float64) float64 {
+	if !plan.Lookback {
+		return offerEndPrice
+	}
+
+	return math.Min(offerStartPrice, offerEndPrice)
+}
+
+func calculateDiscountAmount(purchaseMarketPrice float64, purchasePrice float64, shares float64) float64 {
+	return (purchaseMarketPrice - purchasePrice) * shares
+}
+
+func calculateMarketGain(offerEndPrice float64, marketPrice float64, shares float64) float64 {
+	return (marketPrice - offerEndPrice) * shares
+}
+
+func disqualifyingSTCGDisposition(lot *models.EsppLot, plan *models.EsppPlan, dates lotDates, gains Gains, marketPrice float64, calculator Calculator) Disposition {
+	taxes := calculator.IncrementalTax(tax.Income{
+		Ordinary:              gains.DiscountAmount,
+		ShortTermCapitalGains: gains.Market,
+	})
+
+	return Disposition{
+		Name: DispositionDisqualifyingSTCG,
+		Taxes: Taxes{
+			OrdinaryIncome: taxes.Ordinary,
+			STCG:           taxes.ShortTermCapitalGains,
+			Total:          taxes.Total,
+		},
+		Outcome: disqualifyingSTCGOutcome(lot, plan, marketPrice),
+		EndDate: FormatDate(dates.longTerm),
+	}
+}
+
+func disqualifyingSTCGOutcome(lot *models.EsppLot, plan *models.EsppPlan, marketPrice float64) Outcome {
+	if lot.OfferEndPrice > lot.OfferStartPrice {
+		if marketPrice > lot.OfferEndPrice {
+			return OutcomeGood
+		} else if marketPrice < lot.OfferStartPrice {
+			return OutcomeBest
+		}
+
+		return OutcomeBetter
+	} else if lot.OfferEndPrice < lot.OfferStartPrice {
+		if marketPrice > lot.OfferEndPrice {
+			if marketPrice <= lot.OfferStartPrice*discountRate(plan)+lot.PurchasePrice {
+				return OutcomeBetter
+			}
+
+			return OutcomeGood
+		}
+	}
+
+	return OutcomeBest
+}
+
+func disqualifyingLTCGDisposition(lot *models.EsppLot, dates lotDates, gains Gains, marketPrice float64, calculator Calculator) Disposition {
+	taxes := calculator.IncrementalTax(tax.Income{
+		Ordinary:             gains.DiscountAmount,
+		LongTermCapitalGains: gains.Market,
+	})
+
+	return Disposition{
+		Name: DispositionDisqualifyingLTCG,
+		Taxes: Taxes{
+			OrdinaryIncome: taxes.Ordinary,
+			LTCG:           taxes.LongTermCapitalGains,
+			Total:          taxes.Total,
+		},
+		Outcome: disqualifyingLTCGOutcome(lot, marketPrice),
+		EndDate: FormatDate(dates.qualifying),
+	}
+}
+
+func disqualifyingLTCGOutcome(lot *models.EsppLot, marketPrice float64) Outcome {
+	if lot.OfferEndPrice > lot.OfferStartPrice && marketPrice >= lot.OfferStartPrice {
+		return OutcomeBetter
+	}
+
+	return OutcomeBest
+}
+
+// qualifyingDisposition taxes as ordinary income the lesser of the gain and the
+// plan discount measured at the offering start price, as section 423 requires
+// whether or not the plan has a lookback.
+func qualifyingDisposition(lot *models.EsppLot, plan *models.EsppPlan, marketPrice float64, shares float64, calculator Calculator) Disposition {
+	qualifyingGain := (marketPrice - lot.PurchasePrice) * shares
+	qualifyingDiscount := lot.OfferStartPrice * discountRate(plan) * shares
+
+	// A sale at a loss has no ordinary income, only a capital loss.
+	ordinaryIncome := math.Max(math.Min(qualifyingDiscount, qualifyingGain), 0)
+
+	taxes := calculator.IncrementalTax(tax.Income{
+		Ordinary:             ordinaryIncome,
+		LongTermCapitalGains: qualifyingGain - ordinaryIncome,
+	})
+
+	return Disposition{
+		Name: DispositionQualifying,
+		Taxes: Taxes{
+			OrdinaryIncome: taxes.Ordinary,
+			LTCG:           taxes.LongTermCapitalGains,
+			Total:          taxes.Total,
+		},
+		Outcome: qualifyingOutcome(lot, marketPrice),
+	}
+}
+
+func qualifyingOutcome(lot *models.EsppLot, marketPrice float64) Outcome {
+	if lot.OfferEndPrice < lot.OfferStartPrice && marketPrice > lot.OfferEndPrice {
+		return OutcomeBetter
+	}
+
+	return OutcomeBest
+}
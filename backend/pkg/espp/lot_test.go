@@ -0,0 +1,229 @@
+package espp
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// These lots and expected values mirror the frontend espp-calculations tests.
+var (
+	risingLot = &models.EsppLot{
+		ID:              "1",
+		GrantDate:       "2022-10-01",
+		PurchaseDate:    "2023-03-31",
+		OfferStartPrice: 83.12,
+		OfferEndPrice:   120.1,
+		PurchasePrice:   70.65,
+		Shares:          96.5303,
+	}
+	fallingLot = &models.EsppLot{
+		ID:              "2",
+		GrantDate:       "2024-10-01",
+		PurchaseDate:    "2025-03-31",
+		OfferStartPrice: 89.13,
+		OfferEndPrice:   63.48,
+		PurchasePrice:   53.96,
+		Shares:          149.85,
+	}
+)
+
+func TestCalculateLotTaxes(t *testing.T) {
+	testCases := []struct {
+		name                        string
+		lot                         *models.EsppLot
+		marketPrice                 float64
+		expectedPurchaseMarketPrice float64
+		expectedDiscountAmount      float64
+		expectedMarketGain          float64
+		expectedSTCGTotal           float64
+		expectedSTCGOutcome         Outcome
+		expectedLTCGTotal           float64
+		expectedLTCGOutcome         Outcome
+		expectedQualifyingTotal     float64
+		expectedQualifyingOutcome   Outcome
+	}{
+		{
+			name:                        "start below end, market flat",
+			lot:                         risingLot,
+			marketPrice:                 120.1,
+			expectedPurchaseMarketPrice: 83.12,
+			expectedDiscountAmount:      4773.42,
+			expectedMarketGain:          0,
+			expectedSTCGTotal:           1145.62,
+			expectedSTCGOutcome:         OutcomeBetter,
+			expectedLTCGTotal:           1145.62,
+			expectedLTCGOutcome:         OutcomeBetter,
+			expectedQualifyingTotal:     824.33,
+			expectedQualifyingOutcome:   OutcomeBest,
+		},
+		{
+			name:                        "start below end, market up",
+			lot:                         risingLot,
+			marketPrice:                 125.1,
+			expectedPurchaseMarketPrice: 83.12,
+			expectedDiscountAmount:      4773.42,
+			expectedMarketGain:          482.65,
+			expectedSTCGTotal:           1261.46,
+			expectedSTCGOutcome:         OutcomeGood,
+			expectedLTCGTotal:           1218.02,
+			expectedLTCGOutcome:         OutcomeBetter,
+			expectedQualifyingTotal:     896.73,
+			expectedQualifyingOutcome:   OutcomeBest,
+		},
+		{
+			name:                        "start below end, market down",
+			lot:                         risingLot,
+			marketPrice:                 110.1,
+			expectedPurchaseMarketPrice: 83.12,
+			expectedDiscountAmount:      4773.42,
+			expectedMarketGain:          -965.30,
+			expectedSTCGTotal:           913.95,
+			expectedSTCGOutcome:         OutcomeBetter,
+			expectedLTCGTotal:           913.95,
+			expectedLTCGOutcome:         OutcomeBetter,
+			expectedQualifyingTotal:     679.54,
+			expectedQualifyingOutcome:   OutcomeBest,
+		},
+		{
+			name:                        "start above end, market flat",
+			lot:                         fallingLot,
+			marketPrice:                 63.48,
+			expectedPurchaseMarketPrice: 63.48,
+			expectedDiscountAmount:      1426.57,
+			expectedMarketGain:          0,
+			expectedSTCGTotal:           342.38,
+			expectedSTCGOutcome:         OutcomeBest,
+			expectedLTCGTotal:           342.38,
+			expectedLTCGOutcome:         OutcomeBest,
+			expectedQualifyingTotal:     342.38,
+			expectedQualifyingOutcome:   OutcomeBest,
+		},
+		{
+			name:                        "start above end, market up",
+			lot:                         fallingLot,
+			marketPrice:                 70.0,
+			expectedPurchaseMarketPrice: 63.48,
+			expectedDiscountAmount:      1426.57,
+			expectedMarketGain:          977.02,
+			expectedSTCGTotal:           576.86,
+			expectedSTCGOutcome:         OutcomeGood,
+			expectedLTCGTotal:           488.93,
+			expectedLTCGOutcome:         OutcomeBest,
+			expectedQualifyingTotal:     540.85,
+			expectedQualifyingOutcome:   OutcomeBetter,
+		},
+		{
+			name:                        "start above end, market down",
+			lot:                         fallingLot,
+			marketPrice:                 58.48,
+			expectedPurchaseMarketPrice: 63.48,
+			expectedDiscountAmount:      1426.57,
+			expectedMarketGain:          -749.25,
+			expectedSTCGTotal:           162.56,
+			expectedSTCGOutcome:         OutcomeBest,
+			expectedLTCGTotal:           162.56,
+			expectedLTCGOutcome:         OutcomeBest,
+			expectedQualifyingTotal:     162.56,
+			expectedQualifyingOutcome:   OutcomeBest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lotTaxes, err := CalculateLotTaxes(tc.lot, &DefaultPlan, tc.marketPrice, DefaultRates)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPurchaseMarketPrice, lotTaxes.PurchaseMarketPrice)
+			assert.InDelta(t, tc.expectedDiscountAmount, lotTaxes.Gains.DiscountAmount, 0.005)
+			assert.InDelta(t, tc.expectedMarketGain, lotTaxes.Gains.Market, 0.005)
+			assert.InDelta(t, tc.expectedSTCGTotal, lotTaxes.Dispositions.DisqualifyingSTCG.Taxes.Total, 0.005)
+			assert.Equal(t, tc.expectedSTCGOutcome, lotTaxes.Dispositions.DisqualifyingSTCG.Outcome)
+			assert.InDelta(t, tc.expectedLTCGTotal, lotTaxes.Dispositions.DisqualifyingLTCG.Taxes.Total, 0.005)
+			assert.Equal(t, tc.expectedLTCGOutcome, lotTaxes.Dispositions.DisqualifyingLTCG.Outcome)
+			assert.InDelta(t, tc.expectedQualifyingTotal, lotTaxes.Dispositions.Qualifying.Taxes.Total, 0.005)
+			assert.Equal(t, tc.expectedQualifyingOutcome, lotTaxes.Dispositions.Qualifying.Outcome)
+		})
+	}
+}
+
+func TestCalculateLotTaxesDates(t *testing.T) {
+	lotTaxes, err := CalculateLotTaxes(risingLot, &DefaultPlan, 100, DefaultRates)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-31", lotTaxes.LongTermDate)
+	assert.Equal(t, "2024-10-01", lotTaxes.QualifyingDate)
+	assert.Equal(t, "2024-03-31", lotTaxes.Dispositions.DisqualifyingSTCG.EndDate)
+	assert.Equal(t, "2024-10-01", lotTaxes.Dispositions.DisqualifyingLTCG.EndDate)
+	assert.Empty(t, lotTaxes.Dispositions.Qualifying.EndDate)
+}
+
+func TestCalculateLotTaxesInvalidDates(t *testing.T) {
+	lot := *risingLot
+	lot.GrantDate = "banana"
+
+	_, err := CalculateLotTaxes(&lot, &DefaultPlan, 100, DefaultRates)
+	assert.Error(t, err)
+
+	lot = *risingLot
+	lot.PurchaseDate = ""
+
+	_, err = CalculateLotTaxes(&lot, &DefaultPlan, 100, DefaultRates)
+	assert.Error(t, err)
+}
+
+func TestQualifyingDate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		grantDate    string
+		purchaseDate string
+		expected     string
+	}{
+		{
+			name:         "two years after grant is later",
+			grantDate:    "2022-10-01",
+			purchaseDate: "2023-03-31",
+			expected:     "2024-10-01",
+		},
+		{
+			name:         "one year after purchase is later",
+			grantDate:    "2022-01-01",
+			purchaseDate: "2023-06-30",
+			expected:     "2024-06-30",
+		},
+		{
+			name:         "leap day purchase",
+			grantDate:    "2022-03-01",
+			purchaseDate: "2024-02-29",
+			expected:     "2025-03-01",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			grantDate, err := ParseDate(tc.grantDate)
+			assert.NoError(t, err)
+			purchaseDate, err := ParseDate(tc.purchaseDate)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, FormatDate(QualifyingDate(grantDate, purchaseDate)))
+		})
+	}
+}
+
+func TestCalculateLotTaxesWithPlan(t *testing.T) {
+	plan := &models.EsppPlan{ID: "plan1", DiscountPercent: 10, Lookback: false, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6}
+
+	lotTaxes, err := CalculateLotTaxes(risingLot, plan, 120.1, DefaultRates)
+	assert.NoError(t, err)
+	assert.Equal(t, "plan1", lotTaxes.PlanID)
+	assert.Equal(t, 120.1, lotTaxes.PurchaseMarketPrice)
+
+	defaultTaxes, err := CalculateLotTaxes(risingLot, &DefaultPlan, 120.1, DefaultRates)
+	assert.NoError(t, err)
+	assert.Equal(t, 83.12, defaultTaxes.PurchaseMarketPrice)
+
+	// A smaller discount means less of a qualifying gain is ordinary income.
+	assert.Less(t, lotTaxes.Dispositions.Qualifying.Taxes.OrdinaryIncome, defaultTaxes.Dispositions.Qualifying.Taxes.OrdinaryIncome)
+}
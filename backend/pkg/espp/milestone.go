@@ -0,0 +1,37 @@
+package espp
+
+import (
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+type MilestoneKind string
+
+const (
+	MilestoneLongTerm   MilestoneKind = "long_term"
+	MilestoneQualifying MilestoneKind = "qualifying"
+)
+
+// Milestone is a date on which selling a lot is taxed differently.
+type Milestone struct {
+	Kind MilestoneKind
+	Date time.Time
+}
+
+// LotMilestones returns the dates the lot becomes long-term and qualifying, in
+// that order. When both fall on the same day only the qualifying milestone is
+// returned, since it is the one that matters.
+func LotMilestones(lot *models.EsppLot) ([]Milestone, error) {
+	dates, err := parseLotDates(lot)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := []Milestone{}
+	if dates.longTerm.Before(dates.qualifying) {
+		milestones = append(milestones, Milestone{Kind: MilestoneLongTerm, Date: dates.longTerm})
+	}
+
+	return append(milestones, Milestone{Kind: MilestoneQualifying, Date: dates.qualifying}), nil
+}
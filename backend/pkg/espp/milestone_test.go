@@ -0,0 +1,52 @@
+package espp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLotMilestones(t *testing.T) {
+	testCases := []struct {
+		name               string
+		lot                *models.EsppLot
+		expectedMilestones []Milestone
+		expectedError      bool
+	}{
+		{
+			name: "long-term before qualifying",
+			lot:  &models.EsppLot{GrantDate: "2023-01-01", PurchaseDate: "2023-06-30"},
+			expectedMilestones: []Milestone{
+				{Kind: MilestoneLongTerm, Date: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)},
+				{Kind: MilestoneQualifying, Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "same day",
+			lot:  &models.EsppLot{GrantDate: "2022-06-30", PurchaseDate: "2023-06-30"},
+			expectedMilestones: []Milestone{
+				{Kind: MilestoneQualifying, Date: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:          "invalid grant date",
+			lot:           &models.EsppLot{GrantDate: "banana", PurchaseDate: "2023-06-30"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			milestones, err := LotMilestones(tc.lot)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMilestones, milestones)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package espp
+
+import (
+	"fmt"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+type SaleTaxes struct {
+	Sale        *models.EsppSale `json:"sale"`
+	Gains       Gains            `json:"gains"`
+	Disposition Disposition      `json:"disposition"`
+}
+
+// CalculateSaleTaxes computes the taxes owed on a recorded sale, picking the
+// disposition from how long the shares were held before the sale date.
+func CalculateSaleTaxes(lot *models.EsppLot, plan *models.EsppPlan, sale *models.EsppSale, calculator Calculator) (*SaleTaxes, error) {
+	dates, err := parseLotDates(lot)
+	if err != nil {
+		return nil, err
+	}
+
+	saleDate, err := ParseDate(sale.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date for sale %s: %w", sale.ID, err)
+	}
+
+	saleFraction := 0.0
+	if lot.Shares != 0 {
+		saleFraction = sale.Shares / lot.Shares
+	}
+
+	discountAmount := calculateDiscountAmount(lot.OfferEndPrice, lot.PurchasePrice, lot.Shares) * saleFraction
+	marketGain := calculateMarketGain(lot.OfferEndPrice, sale.Price, sale.Shares)
+
+	gains := Gains{
+		DiscountAmount: discountAmount,
+		Market:         marketGain,
+		Total:          discountAmount + marketGain,
+	}
+
+	var disposition Disposition
+	switch {
+	case saleDate.Before(dates.longTerm):
+		disposition = disqualifyingSTCGDisposition(lot, plan, dates, gains, sale.Price, calculator)
+	case saleDate.Before(dates.qualifying):
+		disposition = disqualifyingLTCGDisposition(lot, dates, gains, sale.Price, calculator)
+	default:
+		disposition = qualifyingDisposition(lot, plan, sale.Price, sale.Shares, calculator)
+	}
+
+	return &SaleTaxes{
+		Sale:        sale,
+		Gains:       gains,
+		Disposition: disposition,
+	}, nil
+}
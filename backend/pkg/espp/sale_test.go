@@ -0,0 +1,79 @@
+package espp
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateSaleTaxes(t *testing.T) {
+	testCases := []struct {
+		name                string
+		sale                *models.EsppSale
+		expectedDisposition DispositionName
+		expectedDiscount    float64
+		expectedMarketGain  float64
+		expectedTotal       float64
+	}{
+		{
+			name:                "sold before long-term date",
+			sale:                &models.EsppSale{ID: "sale1", Date: "2023-06-01", Price: 125.1, Shares: 48.26515},
+			expectedDisposition: DispositionDisqualifyingSTCG,
+			expectedDiscount:    2386.71,
+			expectedMarketGain:  241.33,
+			expectedTotal:       630.73,
+		},
+		{
+			name:                "sold between long-term and qualifying dates",
+			sale:                &models.EsppSale{ID: "sale1", Date: "2024-03-31", Price: 125.1, Shares: 48.26515},
+			expectedDisposition: DispositionDisqualifyingLTCG,
+			expectedDiscount:    2386.71,
+			expectedMarketGain:  241.33,
+			expectedTotal:       609.01,
+		},
+		{
+			name:                "sold on qualifying date",
+			sale:                &models.EsppSale{ID: "sale1", Date: "2024-10-01", Price: 125.1, Shares: 48.26515},
+			expectedDisposition: DispositionQualifying,
+			expectedDiscount:    2386.71,
+			expectedMarketGain:  241.33,
+			expectedTotal:       448.36,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			saleTaxes, err := CalculateSaleTaxes(risingLot, &DefaultPlan, tc.sale, DefaultRates)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.sale, saleTaxes.Sale)
+			assert.Equal(t, tc.expectedDisposition, saleTaxes.Disposition.Name)
+			assert.InDelta(t, tc.expectedDiscount, saleTaxes.Gains.DiscountAmount, 0.005)
+			assert.InDelta(t, tc.expectedMarketGain, saleTaxes.Gains.Market, 0.005)
+			assert.InDelta(t, tc.expectedTotal, saleTaxes.Disposition.Taxes.Total, 0.005)
+		})
+	}
+}
+
+func TestCalculateSaleTaxesWithProfileRates(t *testing.T) {
+	rates := RatesForProfile(&models.TaxProfile{
+		FilingStatus:             models.FilingStatusSingle,
+		OrdinaryIncomeRate:       32,
+		LongTermCapitalGainsRate: 15,
+		StateRate:                5,
+		NIIT:                     true,
+	})
+
+	saleTaxes, err := CalculateSaleTaxes(risingLot, &DefaultPlan, &models.EsppSale{ID: "sale1", Date: "2023-06-01", Price: 125.1, Shares: 48.26515}, rates)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 883.08, saleTaxes.Disposition.Taxes.OrdinaryIncome, 0.005)
+	assert.InDelta(t, 98.46, saleTaxes.Disposition.Taxes.STCG, 0.005)
+	assert.InDelta(t, 981.54, saleTaxes.Disposition.Taxes.Total, 0.01)
+}
+
+func TestCalculateSaleTaxesInvalidDate(t *testing.T) {
+	_, err := CalculateSaleTaxes(risingLot, &DefaultPlan, &models.EsppSale{ID: "sale1", Date: "banana", Price: 100, Shares: 1}, DefaultRates)
+	assert.Error(t, err)
+}
@@ -0,0 +1,281 @@
+package espp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/tax"
+)
+
+type SellStrategy string
+
+const (
+	SellStrategyOptimized SellStrategy = "optimized"
+	SellStrategyFIFO      SellStrategy = "fifo"
+	SellStrategyHIFO      SellStrategy = "hifo"
+)
+
+// shareTolerance absorbs floating point error when adding up fractional shares.
+const shareTolerance = 1e-9
+
+var ErrInsufficientShares = errors.New("not enough unsold shares")
+
+// AvailableLot is a lot with the shares that have not been sold yet and the
+// plan it was bought under.
+type AvailableLot struct {
+	Lot    *models.EsppLot
+	Plan   *models.EsppPlan
+	Shares float64
+}
+
+// SellPlanLot is the part of one lot a strategy sells.
+type SellPlanLot struct {
+	LotID       string      `json:"lotId"`
+	Shares      float64     `json:"shares"`
+	Proceeds    float64     `json:"proceeds"`
+	Gains       Gains       `json:"gains"`
+	Disposition Disposition `json:"disposition"`
+}
+
+// SellSelection is the lots one strategy sells to reach the target. Taxes
+// prices the income of every lot sold together, so gains and losses net and
+// lots push each other into higher brackets, while each lot's disposition
+// shows its taxes as if it were sold alone.
+type SellSelection struct {
+	Strategy    SellStrategy  `json:"strategy"`
+	Lots        []SellPlanLot `json:"lots"`
+	Shares      float64       `json:"shares"`
+	Proceeds    float64       `json:"proceeds"`
+	Taxes       float64       `json:"taxes"`
+	NetProceeds float64       `json:"netProceeds"`
+}
+
+// SellPlan compares the specific identification of lots that owes the least
+// tax against selling the oldest lots first (FIFO) and the lots with the
+// highest cost first (HIFO).
+type SellPlan struct {
+	Date          string        `json:"date"`
+	Price         float64       `json:"price"`
+	Shares        float64       `json:"shares"`
+	Optimized     SellSelection `json:"optimized"`
+	FIFO          SellSelection `json:"fifo"`
+	HIFO          SellSelection `json:"hifo"`
+	SavingsVsFIFO float64       `json:"savingsVsFifo"`
+	SavingsVsHIFO float64       `json:"savingsVsHifo"`
+}
+
+// candidate is an available lot with the tax of selling one of its shares.
+type candidate struct {
+	AvailableLot
+	dates       lotDates
+	taxPerShare float64
+}
+
+// PlanSale picks the lots to sell to reach the input's target. A cash target
+// is gross proceeds, so it is converted to shares at the sale price. Lots
+// bought after the sale date are skipped.
+//
+// The optimized selection is the cheapest of a few orders: lots by the tax
+// each adds to the lots already picked, lots by their tax per share when sold
+// alone, FIFO and HIFO. It is exact when every share is taxed the same
+// whatever else is sold, as with flat Rates on gains of one sign, and never
+// owes more than FIFO or HIFO otherwise.
+func PlanSale(lots []AvailableLot, input models.EsppSellPlanInput, calculator Calculator) (*SellPlan, error) {
+	shares := input.Shares
+	if input.Cash > 0 {
+		shares = input.Cash / input.Price
+	}
+
+	saleDate, err := ParseDate(input.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sale date: %w", err)
+	}
+
+	candidates := []candidate{}
+	available := 0.0
+	for _, lot := range lots {
+		if lot.Shares <= shareTolerance {
+			continue
+		}
+
+		dates, err := parseLotDates(lot.Lot)
+		if err != nil {
+			return nil, err
+		}
+		if dates.purchase.After(saleDate) {
+			continue
+		}
+
+		taxes, _, err := sellLotShares(lot, input.Date, input.Price, lot.Shares, calculator)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, candidate{
+			AvailableLot: lot,
+			dates:        dates,
+			taxPerShare:  taxes.Disposition.Taxes.Total / lot.Shares,
+		})
+		available += lot.Shares
+	}
+
+	if available+shareTolerance < shares {
+		return nil, fmt.Errorf("%w: %g available, %g requested", ErrInsufficientShares, available, shares)
+	}
+
+	// Candidates start in FIFO order so the other orderings break ties by age.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dates.purchase.Before(candidates[j].dates.purchase)
+	})
+
+	plan := &SellPlan{Date: input.Date, Price: input.Price, Shares: shares}
+
+	if plan.FIFO, err = selectLots(SellStrategyFIFO, candidates, shares, input, calculator); err != nil {
+		return nil, err
+	}
+
+	// HIFO uses the price paid for the shares as their cost.
+	hifo := append([]candidate{}, candidates...)
+	sort.SliceStable(hifo, func(i, j int) bool {
+		return hifo[i].Lot.PurchasePrice > hifo[j].Lot.PurchasePrice
+	})
+	if plan.HIFO, err = selectLots(SellStrategyHIFO, hifo, shares, input, calculator); err != nil {
+		return nil, err
+	}
+
+	byTaxPerShare := append([]candidate{}, candidates...)
+	sort.SliceStable(byTaxPerShare, func(i, j int) bool {
+		return byTaxPerShare[i].taxPerShare < byTaxPerShare[j].taxPerShare
+	})
+
+	byAddedTax, err := orderByAddedTax(candidates, shares, input, calculator)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, order := range [][]candidate{byAddedTax, byTaxPerShare, candidates, hifo} {
+		selection, err := selectLots(SellStrategyOptimized, order, shares, input, calculator)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || selection.Taxes < plan.Optimized.Taxes {
+			plan.Optimized = selection
+		}
+	}
+
+	plan.SavingsVsFIFO = plan.FIFO.Taxes - plan.Optimized.Taxes
+	plan.SavingsVsHIFO = plan.HIFO.Taxes - plan.Optimized.Taxes
+
+	return plan, nil
+}
+
+// orderByAddedTax orders the lots by how much tax selling them adds to the
+// lots picked before them, per share, so losses are matched against gains
+// and lots that would reach a higher bracket are left for last.
+func orderByAddedTax(candidates []candidate, shares float64, input models.EsppSellPlanInput, calculator Calculator) ([]candidate, error) {
+	remaining := append([]candidate{}, candidates...)
+	order := []candidate{}
+
+	picked := tax.Income{}
+	pickedTax := calculator.IncrementalTax(picked).Total
+	left := shares
+	for left > shareTolerance && len(remaining) > 0 {
+		best, bestTaxPerShare := -1, 0.0
+		var bestIncome tax.Income
+		for i, candidate := range remaining {
+			sold := math.Min(candidate.Shares, left)
+
+			_, income, err := sellLotShares(candidate.AvailableLot, input.Date, input.Price, sold, calculator)
+			if err != nil {
+				return nil, err
+			}
+
+			taxPerShare := (calculator.IncrementalTax(picked.Add(income)).Total - pickedTax) / sold
+			if best < 0 || taxPerShare < bestTaxPerShare {
+				best, bestTaxPerShare, bestIncome = i, taxPerShare, income
+			}
+		}
+
+		left -= math.Min(remaining[best].Shares, left)
+		picked = picked.Add(bestIncome)
+		pickedTax = calculator.IncrementalTax(picked).Total
+
+		order = append(order, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return append(order, remaining...), nil
+}
+
+// selectLots sells whole lots in the given order until the last one needed is
+// sold in part, and prices the income of all of them together.
+func selectLots(strategy SellStrategy, candidates []candidate, shares float64, input models.EsppSellPlanInput, calculator Calculator) (SellSelection, error) {
+	selection := SellSelection{Strategy: strategy, Lots: []SellPlanLot{}}
+
+	income := tax.Income{}
+	remaining := shares
+	for _, candidate := range candidates {
+		if remaining <= shareTolerance {
+			break
+		}
+
+		sold := math.Min(candidate.Shares, remaining)
+		remaining -= sold
+
+		taxes, lotIncome, err := sellLotShares(candidate.AvailableLot, input.Date, input.Price, sold, calculator)
+		if err != nil {
+			return SellSelection{}, err
+		}
+		income = income.Add(lotIncome)
+
+		lot := SellPlanLot{
+			LotID:       candidate.Lot.ID,
+			Shares:      sold,
+			Proceeds:    sold * input.Price,
+			Gains:       taxes.Gains,
+			Disposition: taxes.Disposition,
+		}
+		selection.Lots = append(selection.Lots, lot)
+		selection.Shares += lot.Shares
+		selection.Proceeds += lot.Proceeds
+	}
+
+	selection.Taxes = calculator.IncrementalTax(income).Total
+	selection.NetProceeds = selection.Proceeds - selection.Taxes
+
+	return selection, nil
+}
+
+// sellLotShares prices selling shares of the lot on their own, and returns the
+// income the sale adds so it can be priced with other sales.
+func sellLotShares(lot AvailableLot, date string, price float64, shares float64, calculator Calculator) (*SaleTaxes, tax.Income, error) {
+	recorder := &incomeRecorder{Calculator: calculator}
+
+	taxes, err := CalculateSaleTaxes(lot.Lot, lot.Plan, &models.EsppSale{
+		LotID:  lot.Lot.ID,
+		UserID: lot.Lot.UserID,
+		Date:   date,
+		Price:  price,
+		Shares: shares,
+	}, recorder)
+	if err != nil {
+		return nil, tax.Income{}, err
+	}
+
+	return taxes, recorder.added, nil
+}
+
+// incomeRecorder passes through to a Calculator and keeps the income it was
+// last asked to price.
+type incomeRecorder struct {
+	Calculator
+	added tax.Income
+}
+
+func (r *incomeRecorder) IncrementalTax(added tax.Income) tax.Breakdown {
+	r.added = added
+	return r.Calculator.IncrementalTax(added)
+}
@@ -0,0 +1,137 @@
+package espp
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/tax"
+	"github.com/stretchr/testify/assert"
+)
+
+func sellPlanLots() []AvailableLot {
+	return []AvailableLot{
+		// Qualifying by the sale date.
+		{Lot: &models.EsppLot{ID: "old", GrantDate: "2021-01-01", PurchaseDate: "2021-06-30", OfferStartPrice: 50, OfferEndPrice: 60, PurchasePrice: 42.5, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+		// Short-term, bought above the sale price.
+		{Lot: &models.EsppLot{ID: "loss", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 150, OfferEndPrice: 160, PurchasePrice: 127.5, Shares: 12}, Plan: &DefaultPlan, Shares: 10},
+		// Short-term, bought below the sale price.
+		{Lot: &models.EsppLot{ID: "recent", GrantDate: "2024-01-01", PurchaseDate: "2024-06-28", OfferStartPrice: 100, OfferEndPrice: 110, PurchasePrice: 85, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+		// Bought after the sale date.
+		{Lot: &models.EsppLot{ID: "future", GrantDate: "2024-07-01", PurchaseDate: "2024-12-31", OfferStartPrice: 100, OfferEndPrice: 110, PurchasePrice: 85, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+	}
+}
+
+func selectedShares(selection SellSelection) map[string]float64 {
+	shares := map[string]float64{}
+	for _, lot := range selection.Lots {
+		shares[lot.LotID] = lot.Shares
+	}
+
+	return shares
+}
+
+func TestPlanSale(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input models.EsppSellPlanInput
+	}{
+		{
+			name:  "share target",
+			input: models.EsppSellPlanInput{Shares: 15, Date: "2024-07-01", Price: 120},
+		},
+		{
+			name:  "cash target",
+			input: models.EsppSellPlanInput{Cash: 1800, Date: "2024-07-01", Price: 120},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := PlanSale(sellPlanLots(), tc.input, DefaultRates)
+
+			assert.NoError(t, err)
+			assert.InDelta(t, 15, plan.Shares, shareTolerance)
+
+			assert.Equal(t, SellStrategyOptimized, plan.Optimized.Strategy)
+			assert.Equal(t, map[string]float64{"loss": 10, "recent": 5}, selectedShares(plan.Optimized))
+			assert.InDelta(t, 1800, plan.Optimized.Proceeds, 0.005)
+			assert.InDelta(t, 24, plan.Optimized.Taxes, 0.005)
+			assert.InDelta(t, 1776, plan.Optimized.NetProceeds, 0.005)
+
+			assert.Equal(t, map[string]float64{"old": 10, "loss": 5}, selectedShares(plan.FIFO))
+			assert.InDelta(t, 114, plan.FIFO.Taxes, 0.005)
+			assert.Equal(t, DispositionQualifying, plan.FIFO.Lots[0].Disposition.Name)
+
+			assert.Equal(t, map[string]float64{"loss": 10, "recent": 5}, selectedShares(plan.HIFO))
+
+			assert.InDelta(t, 90, plan.SavingsVsFIFO, 0.005)
+			assert.InDelta(t, 0, plan.SavingsVsHIFO, 0.005)
+		})
+	}
+}
+
+func TestPlanSaleAcrossBracket(t *testing.T) {
+	table, err := tax.TableFor(2024, models.FilingStatusSingle)
+	assert.NoError(t, err)
+
+	// Taxable salary ends $200 below the 24% bracket, and each lot adds $150
+	// of discount income, so only the two lots together cross into it.
+	calculator := tax.Calculator{Table: table, Base: tax.Income{Ordinary: 100325 + table.StandardDeduction}}
+
+	lots := []AvailableLot{
+		{Lot: &models.EsppLot{ID: "first", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 100, OfferEndPrice: 100, PurchasePrice: 85, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+		{Lot: &models.EsppLot{ID: "second", GrantDate: "2024-01-01", PurchaseDate: "2024-06-28", OfferStartPrice: 100, OfferEndPrice: 100, PurchasePrice: 85, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+	}
+
+	plan, err := PlanSale(lots, models.EsppSellPlanInput{Shares: 20, Date: "2024-07-01", Price: 100}, calculator)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"first": 10, "second": 10}, selectedShares(plan.Optimized))
+
+	// Sold alone, each lot's discount stays in the 22% bracket.
+	assert.InDelta(t, 33, plan.Optimized.Lots[0].Disposition.Taxes.Total, 0.005)
+	assert.InDelta(t, 33, plan.Optimized.Lots[1].Disposition.Taxes.Total, 0.005)
+
+	// Sold together, $100 of the discount is taxed at 24%.
+	assert.InDelta(t, 68, plan.Optimized.Taxes, 0.005)
+	assert.InDelta(t, 68, plan.FIFO.Taxes, 0.005)
+	assert.InDelta(t, 2000-68, plan.Optimized.NetProceeds, 0.005)
+	assert.InDelta(t, 0, plan.SavingsVsFIFO, 0.005)
+}
+
+func TestPlanSaleNetsGainsAndLosses(t *testing.T) {
+	lots := []AvailableLot{
+		// Long-term with a $300 market loss and no discount.
+		{Lot: &models.EsppLot{ID: "loss", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 130, OfferEndPrice: 130, PurchasePrice: 130, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+		// Long-term with a $300 market gain and no discount.
+		{Lot: &models.EsppLot{ID: "gain", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 70, OfferEndPrice: 70, PurchasePrice: 70, Shares: 10}, Plan: &DefaultPlan, Shares: 10},
+	}
+
+	plan, err := PlanSale(lots, models.EsppSellPlanInput{Shares: 20, Date: "2024-07-01", Price: 100}, DefaultRates)
+
+	assert.NoError(t, err)
+
+	// Priced alone, the loss would offset ordinary income at 24% while the gain
+	// paid 15%. Together they net to nothing.
+	assert.Equal(t, DispositionDisqualifyingLTCG, plan.Optimized.Lots[0].Disposition.Name)
+	assert.InDelta(t, -72+45, plan.Optimized.Lots[0].Disposition.Taxes.Total+plan.Optimized.Lots[1].Disposition.Taxes.Total, 0.005)
+	assert.InDelta(t, 0, plan.Optimized.Taxes, 0.005)
+}
+
+func TestPlanSaleInsufficientShares(t *testing.T) {
+	_, err := PlanSale(sellPlanLots(), models.EsppSellPlanInput{Shares: 30, Date: "2024-07-01", Price: 120}, DefaultRates)
+	assert.NoError(t, err)
+
+	_, err = PlanSale(sellPlanLots(), models.EsppSellPlanInput{Shares: 30.5, Date: "2024-07-01", Price: 120}, DefaultRates)
+	assert.ErrorIs(t, err, ErrInsufficientShares)
+}
+
+func TestPlanSaleInvalidDates(t *testing.T) {
+	_, err := PlanSale(sellPlanLots(), models.EsppSellPlanInput{Shares: 1, Date: "banana", Price: 120}, DefaultRates)
+	assert.Error(t, err)
+
+	lots := sellPlanLots()
+	lots[0].Lot.GrantDate = ""
+	_, err = PlanSale(lots, models.EsppSellPlanInput{Shares: 1, Date: "2024-07-01", Price: 120}, DefaultRates)
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// getOwnedEsppLot loads a lot that belongs to the caller. When the lot is
+// missing or owned by someone else, it returns a nil lot along with the
+// response to send instead.
+func getOwnedEsppLot(lotRepo db.EsppLotRepository, lotID string, callerID string) (*models.EsppLot, events.APIGatewayProxyResponse, error) {
+	lot, err := lotRepo.GetEsppLot(lotID)
+	if err != nil {
+		response, err := utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lot"})
+		return nil, response, err
+	}
+
+	if lot == nil {
+		response, err := utils.APIResponse(404, map[string]string{"error": "ESPP lot not found"})
+		return nil, response, err
+	}
+
+	if lot.UserID != callerID {
+		response, err := utils.ForbiddenError()
+		return nil, response, err
+	}
+
+	return lot, events.APIGatewayProxyResponse{}, nil
+}
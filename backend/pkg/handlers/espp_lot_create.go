@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// createEsppLotResponse is the created lot along with warnings about entered
+// offer prices that disagree with the recorded closes.
+type createEsppLotResponse struct {
+	*models.EsppLot
+	Warnings models.ValidationErrors `json:"warnings,omitempty"`
+}
+
+// CreateEsppLot fills in missing offer prices of lots with a ticker from the
+// recorded closes on the grant and purchase dates before validating the lot
+// and checking it against the plan it references.
+func CreateEsppLot(lotRepo db.EsppLotRepository, historyRepo db.PriceHistoryRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		var lot models.EsppLotInput
+		if err := json.Unmarshal([]byte(request.Body), &lot); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		// The lot always belongs to the caller. A userId in the body is only
+		// accepted when it agrees with the token.
+		if lot.UserID != "" && lot.UserID != callerID {
+			return utils.ForbiddenError()
+		}
+		lot.UserID = callerID
+
+		warnings, err := quotes.BackfillOfferPrices(historyRepo, &lot)
+		if err != nil {
+			slog.Error("Failed to retrieve price history", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve price history"})
+		}
+
+		if errs := lot.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		planErrs, err := validateLotPlan(planRepo, *models.NewEsppLot(lot), callerID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP plan", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plan"})
+		}
+		if len(planErrs) > 0 {
+			return validationError(planErrs)
+		}
+
+		createdLot, err := lotRepo.CreateEsppLot(lot)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to create ESPP lot"})
+		}
+
+		return utils.ETagResponse(201, versionETag(createdLot.Version), createEsppLotResponse{EsppLot: createdLot, Warnings: warnings})
+	}
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEsppLot(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		callerID             string
+		request              events.APIGatewayProxyRequest
+		mockError            error
+		historyError         error
+		planError            error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:     "successful creation",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"userId": "user123",
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0
+				}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   201,
+			expectedBodyContains: `"userId":"user123"`,
+		},
+		{
+			name:     "user ID taken from token",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0
+				}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   201,
+			expectedBodyContains: `"userId":"user123"`,
+		},
+		{
+			name:     "user ID for another user",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"userId": "user123",
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0
+				}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   403,
+			expectedBodyContains: `"error":"Forbidden"`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"userId": "user123"}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   401,
+			expectedBodyContains: `"error":"Unauthorized"`,
+		},
+		{
+			name:     "invalid request body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{invalid json}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   400,
+			expectedBodyContains: `"error":"Invalid request body"`,
+		},
+		{
+			name:     "invalid lot",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "banana",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 130.0,
+					"shares": -10.0
+				}`,
+			},
+			mockError:            nil,
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"error":"Validation failed","fields":[{"field":"grantDate","message":"must be a date in YYYY-MM-DD format"},{"field":"purchasePrice","message":"must not be greater than offerEndPrice"},{"field":"shares","message":"must be greater than zero"}]}`,
+		},
+		{
+			name:     "offer prices filled from price history",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"ticker": "nke"
+				}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"offerStartPrice":100,"offerEndPrice":120`,
+		},
+		{
+			name:     "offer price disagreeing with price history",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 101.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"ticker": "NKE"
+				}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"warnings":[{"field":"offerStartPrice","message":"does not match the recorded close of 100.00 on 2022-12-30"}]`,
+		},
+		{
+			name:     "missing offer prices without price history",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"ticker": "AAPL"
+				}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"field":"offerStartPrice","message":"must be greater than zero"}`,
+		},
+		{
+			name:     "price history error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"ticker": "NKE"
+				}`,
+			},
+			historyError:         errors.New("database error"),
+			expectedStatusCode:   500,
+			expectedBodyContains: `"error":"Failed to retrieve price history"`,
+		},
+		{
+			name:     "plan reference",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"planId": "plan123"
+				}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"planId":"plan123"`,
+		},
+		{
+			name:     "plan of another user",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"planId": "plan456"
+				}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"field":"planId","message":"must reference one of your ESPP plans"}`,
+		},
+		{
+			name:     "lot breaks plan rules",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-12-29",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 25.0,
+					"planId": "plan123"
+				}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `[{"field":"purchaseDate","message":"must be within the plan's 6 month offering period"},{"field":"shares","message":"must not be greater than the plan's cap of 20 shares"}]`,
+		},
+		{
+			name:     "plan retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0,
+					"planId": "plan123"
+				}`,
+			},
+			planError:            errors.New("database error"),
+			expectedStatusCode:   500,
+			expectedBodyContains: `"error":"Failed to retrieve ESPP plan"`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"userId": "user123",
+					"grantDate": "2023-01-01",
+					"purchaseDate": "2023-06-30",
+					"offerStartPrice": 100.0,
+					"offerEndPrice": 120.0,
+					"purchasePrice": 85.0,
+					"shares": 10.0
+				}`,
+			},
+			mockError:            errors.New("database error"),
+			expectedStatusCode:   500,
+			expectedBodyContains: `"error":"Failed to create ESPP lot"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppLotRepository()
+
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.mockError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.mockError}
+			}
+
+			memoryHistoryRepo := db.NewMemoryPriceHistoryRepository()
+			assert.NoError(t, memoryHistoryRepo.BatchPutClosingPrices([]models.ClosingPrice{
+				{Ticker: "NKE", Date: "2022-12-30", Close: 100},
+				{Ticker: "NKE", Date: "2023-06-30", Close: 120},
+			}))
+
+			var historyRepo db.PriceHistoryRepository = memoryHistoryRepo
+			if tc.historyError != nil {
+				historyRepo = &failingPriceHistoryRepository{err: tc.historyError}
+			}
+
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan123", UserID: "user123", OfferingPeriodMonths: 6, MaxSharesPerPurchase: 20})
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan456", UserID: "user456", OfferingPeriodMonths: 6})
+
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+
+			handler := CreateEsppLot(lotRepo, historyRepo, planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Contains(t, response.Body, tc.expectedBodyContains)
+
+			// For successful creation, verify the lot was stored and returned
+			if tc.expectedStatusCode == 201 {
+				var createdLot models.EsppLot
+				err := json.Unmarshal([]byte(response.Body), &createdLot)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, createdLot.ID)
+				assert.NotEmpty(t, createdLot.CreatedAt)
+				assert.Equal(t, int64(1), createdLot.Version)
+				assert.Equal(t, `"1"`, response.Headers["ETag"])
+
+				storedLot, err := memoryRepo.GetEsppLot(createdLot.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, &createdLot, storedLot)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func DeleteEsppLot(lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		// Sales are removed first so a failure never leaves sales without a lot.
+		sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP lot"})
+		}
+
+		for _, sale := range sales {
+			if err := saleRepo.DeleteEsppSale(sale.ID); err != nil {
+				return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP lot"})
+			}
+		}
+
+		err = lotRepo.DeleteEsppLot(lotID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP lot"})
+		}
+
+		return utils.APIResponse(200, map[string]string{"message": "ESPP lot deleted successfully"})
+	}
+}
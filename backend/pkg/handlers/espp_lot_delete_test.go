@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingDeleteEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+	err error
+}
+
+func (r *failingDeleteEsppLotRepository) DeleteEsppLot(_ string) error {
+	return r.err
+}
+
+func TestDeleteEsppLot(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		getError           error
+		deleteError        error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful deletion",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"message":"ESPP lot deleted successfully"}`,
+		},
+		{
+			name:     "missing lot ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: lotId"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "lot not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "nonexistent",
+				},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP lot not found"}`,
+		},
+		{
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			getError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lot"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			deleteError:        errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete ESPP lot"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppLotRepository()
+			memoryRepo.PutEsppLot(models.EsppLot{ID: "lot123", UserID: "user123", Shares: 10.0})
+
+			saleRepo := db.NewMemoryEsppSaleRepository()
+			saleRepo.PutEsppSale(models.EsppSale{ID: "sale123", LotID: "lot123", UserID: "user123", Shares: 5.0})
+
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.getError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.getError}
+			} else if tc.deleteError != nil {
+				lotRepo = &failingDeleteEsppLotRepository{MemoryEsppLotRepository: memoryRepo, err: tc.deleteError}
+			}
+
+			handler := DeleteEsppLot(lotRepo, saleRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			storedLot, err := memoryRepo.GetEsppLot("lot123")
+			assert.NoError(t, err)
+			storedSale, err := saleRepo.GetEsppSale("sale123")
+			assert.NoError(t, err)
+			if tc.expectedStatusCode == 200 {
+				assert.Nil(t, storedLot)
+				assert.Nil(t, storedSale)
+			} else {
+				assert.NotNil(t, storedLot)
+			}
+		})
+	}
+}
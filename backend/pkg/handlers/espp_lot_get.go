@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func GetEsppLot(lotRepo db.EsppLotRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		return utils.ETagResponse(200, versionETag(lot.Version), lot)
+	}
+}
@@ -1,19 +1,19 @@
-package main
+package handlers
 
 import (
-	"context"
 	"errors"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/db"
 	"github.com/ljhurst/fife/pkg/models"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestHandler(t *testing.T) {
+func TestGetEsppLot(t *testing.T) {
 	testCases := []struct {
 		name               string
+		callerID           string
 		request            events.APIGatewayProxyRequest
 		mockLot            *models.EsppLot
 		mockError          error
@@ -21,7 +21,8 @@ func TestHandler(t *testing.T) {
 		expectedBody       string
 	}{
 		{
-			name: "successful retrieval",
+			name:     "successful retrieval",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"lotId": "lot123",
@@ -41,10 +42,11 @@ func TestHandler(t *testing.T) {
 			},
 			mockError:          nil,
 			expectedStatusCode: 200,
-			expectedBody:       `{"id":"lot123","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10,"createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-01T00:00:00Z"}`,
+			expectedBody:       `{"id":"lot123","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10,"version":0,"createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-01T00:00:00Z"}`,
 		},
 		{
-			name: "lot not found",
+			name:     "lot not found",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"lotId": "nonexistent",
@@ -56,7 +58,37 @@ func TestHandler(t *testing.T) {
 			expectedBody:       `{"error":"ESPP lot not found"}`,
 		},
 		{
-			name: "database error",
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			mockLot: &models.EsppLot{
+				ID:     "lot123",
+				UserID: "user123",
+			},
+			mockError:          nil,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+			},
+			mockLot:            nil,
+			mockError:          nil,
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"lotId": "lot123",
@@ -68,7 +100,8 @@ func TestHandler(t *testing.T) {
 			expectedBody:       `{"error":"Failed to retrieve ESPP lot"}`,
 		},
 		{
-			name: "missing lot ID",
+			name:     "missing lot ID",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{},
 			},
@@ -81,16 +114,25 @@ func TestHandler(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockGetEsppLot := func(svc dynamodbiface.DynamoDBAPI, id string) (*models.EsppLot, error) {
-				return tc.mockLot, tc.mockError
+			memoryRepo := db.NewMemoryEsppLotRepository()
+			if tc.mockLot != nil {
+				memoryRepo.PutEsppLot(*tc.mockLot)
 			}
 
-			handler := handlerWithDeps(mockGetEsppLot)
-			response, err := handler(context.Background(), tc.request)
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.mockError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.mockError}
+			}
+
+			handler := GetEsppLot(lotRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
 
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
 			assert.Equal(t, tc.expectedBody, response.Body)
+			if tc.expectedStatusCode == 200 {
+				assert.Equal(t, versionETag(tc.mockLot.Version), response.Headers["ETag"])
+			}
 		})
 	}
 }
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// UpdateEsppLot applies a full or partial update to a lot. An If-Match header
+// with the ETag from a previous read makes the write fail with 412 when the
+// lot has changed since. Without it, a lot that changes while the update is
+// being checked answers 409.
+func UpdateEsppLot(lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		expectedVersion, ok := ifMatchVersion(request.Headers)
+		if !ok {
+			return utils.PreconditionFailedError()
+		}
+
+		var lotUpdate models.EsppLotUpdate
+		if err := json.Unmarshal([]byte(request.Body), &lotUpdate); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if lotUpdate.IsEmpty() {
+			return utils.APIResponse(400, map[string]string{"error": "No fields to update"})
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		if expectedVersion != nil && *expectedVersion != lot.Version {
+			return utils.PreconditionFailedError()
+		}
+
+		// The checks below are only right for the version they were read from,
+		// so the write is held to it even without If-Match.
+		ifMatch := expectedVersion != nil
+		expectedVersion = &lot.Version
+
+		if errs := lotUpdate.Validate(*lot); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		// The plan rules apply to the lot as it will be after the update.
+		updated := *lot
+		lotUpdate.Apply(&updated)
+
+		planErrs, err := validateLotPlan(planRepo, updated, callerID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plan"})
+		}
+		if len(planErrs) > 0 {
+			return validationError(planErrs)
+		}
+
+		if lotUpdate.Shares != nil || lotUpdate.PurchaseDate != nil {
+			sales, err := saleRepo.GetEsppSalesByLotID(lotID)
+			if err != nil {
+				return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+			}
+
+			if models.TotalSoldShares(sales) > updated.Shares {
+				return utils.APIResponse(400, map[string]string{"error": "Lot shares cannot be less than sold shares"})
+			}
+
+			for _, sale := range sales {
+				if updated.ValidateSale(models.EsppSaleInput{Date: sale.Date}) != nil {
+					return validationError(models.ValidationErrors{{Field: "purchaseDate", Message: "must not be after the date of a sale from the lot"}})
+				}
+			}
+		}
+
+		updatedLot, err := lotRepo.UpdateEsppLot(lotID, lotUpdate, expectedVersion)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to update ESPP lot"})
+		}
+
+		// The write only comes back empty when the lot changed while the update
+		// was being checked.
+		if updatedLot == nil {
+			if ifMatch {
+				return utils.PreconditionFailedError()
+			}
+			return utils.APIResponse(409, map[string]string{"error": "ESPP lot changed during the update"})
+		}
+
+		return utils.ETagResponse(200, versionETag(updatedLot.Version), updatedLot)
+	}
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingUpdateEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+	err error
+}
+
+func (r *failingUpdateEsppLotRepository) UpdateEsppLot(_ string, _ models.EsppLotUpdate, _ *int64) (*models.EsppLot, error) {
+	return nil, r.err
+}
+
+// racingEsppLotRepository changes the lot just before the handler writes it,
+// as a concurrent update would.
+type racingEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+}
+
+func (r *racingEsppLotRepository) UpdateEsppLot(id string, lotUpdate models.EsppLotUpdate, expectedVersion *int64) (*models.EsppLot, error) {
+	purchaseDate := "2022-12-01"
+	if _, err := r.MemoryEsppLotRepository.UpdateEsppLot(id, models.EsppLotUpdate{PurchaseDate: &purchaseDate}, nil); err != nil {
+		return nil, err
+	}
+
+	return r.MemoryEsppLotRepository.UpdateEsppLot(id, lotUpdate, expectedVersion)
+}
+
+func TestUpdateEsppLot(t *testing.T) {
+	existingLot := models.EsppLot{
+		ID:              "lot123",
+		UserID:          "user123",
+		GrantDate:       "2023-01-01",
+		PurchaseDate:    "2023-06-30",
+		OfferStartPrice: 100.0,
+		OfferEndPrice:   120.0,
+		PurchasePrice:   85.0,
+		Shares:          10.0,
+		Version:         2,
+		CreatedAt:       "2023-01-01T00:00:00Z",
+		UpdatedAt:       "2023-01-01T00:00:00Z",
+	}
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockSales          []models.EsppSale
+		getError           error
+		updateError        error
+		concurrentUpdate   bool
+		saleError          error
+		planError          error
+		expectedStatusCode int
+		expectedBody       string
+		expectedLot        *models.EsppLot
+	}{
+		{
+			name:     "partial update",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"purchasePrice":80,"shares":12.5}`,
+			},
+			expectedStatusCode: 200,
+			expectedLot: &models.EsppLot{
+				ID:              "lot123",
+				UserID:          "user123",
+				GrantDate:       "2023-01-01",
+				PurchaseDate:    "2023-06-30",
+				OfferStartPrice: 100.0,
+				OfferEndPrice:   120.0,
+				PurchasePrice:   80.0,
+				Shares:          12.5,
+				Version:         3,
+				CreatedAt:       "2023-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:     "matching If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Headers: map[string]string{
+					"If-Match": `"2"`,
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 200,
+			expectedLot: &models.EsppLot{
+				ID:              "lot123",
+				UserID:          "user123",
+				GrantDate:       "2023-01-01",
+				PurchaseDate:    "2023-06-30",
+				OfferStartPrice: 100.0,
+				OfferEndPrice:   120.0,
+				PurchasePrice:   85.0,
+				Shares:          12.5,
+				Version:         3,
+				CreatedAt:       "2023-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:     "stale If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Headers: map[string]string{
+					"If-Match": `"1"`,
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 412,
+			expectedBody:       `{"error":"Precondition failed"}`,
+		},
+		{
+			name:     "malformed If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Headers: map[string]string{
+					"If-Match": `"1", "2"`,
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 412,
+			expectedBody:       `{"error":"Precondition failed"}`,
+		},
+		{
+			name:     "plan reference",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"planId":"plan123"}`,
+			},
+			expectedStatusCode: 200,
+			expectedLot: &models.EsppLot{
+				ID:              "lot123",
+				UserID:          "user123",
+				GrantDate:       "2023-01-01",
+				PurchaseDate:    "2023-06-30",
+				OfferStartPrice: 100.0,
+				OfferEndPrice:   120.0,
+				PurchasePrice:   85.0,
+				Shares:          10.0,
+				PlanID:          "plan123",
+				Version:         3,
+				CreatedAt:       "2023-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:     "unknown plan",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"planId":"missing"}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"planId","message":"must reference one of your ESPP plans"}]}`,
+		},
+		{
+			name:     "updated lot breaks plan rules",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":25,"planId":"plan123"}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"shares","message":"must not be greater than the plan's cap of 20 shares"}]}`,
+		},
+		{
+			name:     "plan retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"planId":"plan123"}`,
+			},
+			planError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plan"}`,
+		},
+		{
+			name:     "shares below sold shares",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":3}`,
+			},
+			mockSales: []models.EsppSale{
+				{ID: "sale123", LotID: "lot123", UserID: "user123", Date: "2024-01-01", Price: 150.0, Shares: 4.0},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Lot shares cannot be less than sold shares"}`,
+		},
+		{
+			name:     "purchase date after a sale",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"purchaseDate":"2023-07-15"}`,
+			},
+			mockSales: []models.EsppSale{
+				{ID: "sale123", LotID: "lot123", UserID: "user123", Date: "2023-07-10", Price: 150.0, Shares: 4.0},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"purchaseDate","message":"must not be after the date of a sale from the lot"}]}`,
+		},
+		{
+			name:     "sale database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":3}`,
+			},
+			saleError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sales"}`,
+		},
+		{
+			name:     "invalid update",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"purchaseDate":"2022-12-31"}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"purchaseDate","message":"must not be before grantDate"}]}`,
+		},
+		{
+			name:     "empty update",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"No fields to update"}`,
+		},
+		{
+			name:     "invalid request body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `invalid json`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "lot not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "nonexistent",
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP lot not found"}`,
+		},
+		{
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":12.5}`,
+			},
+			getError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lot"}`,
+		},
+		{
+			name:     "update error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":12.5}`,
+			},
+			updateError:        errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to update ESPP lot"}`,
+		},
+		{
+			name:     "changed during update",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"grantDate":"2023-01-15"}`,
+			},
+			concurrentUpdate:   true,
+			expectedStatusCode: 409,
+			expectedBody:       `{"error":"ESPP lot changed during the update"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"lotId": "lot123",
+				},
+				Body: `{"shares":12.5}`,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing lot ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           `{"shares":12.5}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: lotId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			memoryLotRepo.PutEsppLot(existingLot)
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			for _, sale := range tc.mockSales {
+				memorySaleRepo.PutEsppSale(sale)
+			}
+
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.getError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.getError}
+			}
+			if tc.updateError != nil {
+				lotRepo = &failingUpdateEsppLotRepository{MemoryEsppLotRepository: memoryLotRepo, err: tc.updateError}
+			}
+			if tc.concurrentUpdate {
+				lotRepo = &racingEsppLotRepository{MemoryEsppLotRepository: memoryLotRepo}
+			}
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.saleError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.saleError}
+			}
+
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan123", UserID: "user123", OfferingPeriodMonths: 6, MaxSharesPerPurchase: 20})
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+
+			handler := UpdateEsppLot(lotRepo, saleRepo, planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedLot == nil {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			assert.Equal(t, versionETag(tc.expectedLot.Version), response.Headers["ETag"])
+
+			lot, err := memoryLotRepo.GetEsppLot(tc.expectedLot.ID)
+			assert.NoError(t, err)
+			assert.NotEqual(t, existingLot.UpdatedAt, lot.UpdatedAt)
+			tc.expectedLot.UpdatedAt = lot.UpdatedAt
+			assert.Equal(t, tc.expectedLot, lot)
+		})
+	}
+}
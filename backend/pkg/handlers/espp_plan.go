@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// getOwnedEsppPlan loads a plan that belongs to the caller. When the plan is
+// missing or owned by someone else, it returns a nil plan along with the
+// response to send instead.
+func getOwnedEsppPlan(planRepo db.EsppPlanRepository, planID string, callerID string) (*models.EsppPlan, events.APIGatewayProxyResponse, error) {
+	plan, err := planRepo.GetEsppPlan(planID)
+	if err != nil {
+		response, err := utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plan"})
+		return nil, response, err
+	}
+
+	if plan == nil {
+		response, err := utils.APIResponse(404, map[string]string{"error": "ESPP plan not found"})
+		return nil, response, err
+	}
+
+	if plan.UserID != callerID {
+		response, err := utils.ForbiddenError()
+		return nil, response, err
+	}
+
+	return plan, events.APIGatewayProxyResponse{}, nil
+}
+
+// validateLotPlan checks that a lot references one of the caller's plans and
+// follows its rules. Lots without a plan are not checked.
+func validateLotPlan(planRepo db.EsppPlanRepository, lot models.EsppLot, callerID string) (models.ValidationErrors, error) {
+	if lot.PlanID == "" {
+		return nil, nil
+	}
+
+	plan, err := planRepo.GetEsppPlan(lot.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan == nil || plan.UserID != callerID {
+		return models.ValidationErrors{{Field: "planId", Message: "must reference one of your ESPP plans"}}, nil
+	}
+
+	return plan.ValidateLot(lot), nil
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func CreateEsppPlan(planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		var plan models.EsppPlanInput
+		if err := json.Unmarshal([]byte(request.Body), &plan); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		// The plan always belongs to the caller. A userId in the body is only
+		// accepted when it agrees with the token.
+		if plan.UserID != "" && plan.UserID != callerID {
+			return utils.ForbiddenError()
+		}
+		plan.UserID = callerID
+
+		if errs := plan.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		createdPlan, err := planRepo.CreateEsppPlan(plan)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to create ESPP plan"})
+		}
+
+		return utils.APIResponse(201, createdPlan)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEsppPlan(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		callerID             string
+		request              events.APIGatewayProxyRequest
+		mockError            error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:     "successful creation",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{
+					"name": " Acme ESPP ",
+					"employer": "Acme",
+					"discountPercent": 10,
+					"lookback": false,
+					"offeringPeriodMonths": 12,
+					"purchasePeriodMonths": 6,
+					"maxSharesPerPurchase": 500
+				}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"name":"Acme ESPP","employer":"Acme","discountPercent":10,"lookback":false,"offeringPeriodMonths":12,"purchasePeriodMonths":6,"resetRule":"none","maxSharesPerPurchase":500`,
+		},
+		{
+			name:     "other user's plan",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"userId":"user456","name":"Acme ESPP","discountPercent":15,"offeringPeriodMonths":6,"purchasePeriodMonths":6}`,
+			},
+			expectedStatusCode:   403,
+			expectedBodyContains: `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "invalid plan",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"name":"Acme ESPP","discountPercent":20,"offeringPeriodMonths":6,"purchasePeriodMonths":12,"resetRule":"sometimes"}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `"fields":[{"field":"discountPercent","message":"must be between 0 and 15"},{"field":"purchasePeriodMonths","message":"must not be greater than offeringPeriodMonths"},{"field":"resetRule","message":"must be one of none, lower_price"}]`,
+		},
+		{
+			name:     "invalid JSON",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"name":`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"name":"Acme ESPP","discountPercent":15,"offeringPeriodMonths":6,"purchasePeriodMonths":6}`,
+			},
+			expectedStatusCode:   401,
+			expectedBodyContains: `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				Body: `{"name":"Acme ESPP","discountPercent":15,"offeringPeriodMonths":6,"purchasePeriodMonths":6}`,
+			},
+			mockError:            errors.New("database error"),
+			expectedStatusCode:   500,
+			expectedBodyContains: `{"error":"Failed to create ESPP plan"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppPlanRepository()
+
+			var planRepo db.EsppPlanRepository = memoryRepo
+			if tc.mockError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.mockError}
+			}
+
+			handler := CreateEsppPlan(planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Contains(t, response.Body, tc.expectedBodyContains)
+
+			if tc.expectedStatusCode == 201 {
+				var createdPlan models.EsppPlan
+				assert.NoError(t, json.Unmarshal([]byte(response.Body), &createdPlan))
+				assert.Equal(t, "user123", createdPlan.UserID)
+
+				storedPlan, err := memoryRepo.GetEsppPlan(createdPlan.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, &createdPlan, storedPlan)
+			}
+		})
+	}
+}
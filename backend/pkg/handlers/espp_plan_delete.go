@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// DeleteEsppPlan refuses to delete a plan that lots still reference, since
+// their tax estimates would silently fall back to the default plan.
+func DeleteEsppPlan(planRepo db.EsppPlanRepository, lotRepo db.EsppLotRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		planID := request.PathParameters[constants.PathPlanID]
+		if planID == "" {
+			return utils.MissingPathParameterError(constants.PathPlanID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		plan, errResponse, err := getOwnedEsppPlan(planRepo, planID, callerID)
+		if plan == nil {
+			return errResponse, err
+		}
+
+		lots, err := lotRepo.GetEsppLotsByUserID(plan.UserID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP plan"})
+		}
+
+		for _, lot := range lots {
+			if lot.PlanID == plan.ID {
+				return utils.APIResponse(409, map[string]string{"error": "ESPP plan is still referenced by ESPP lots"})
+			}
+		}
+
+		err = planRepo.DeleteEsppPlan(planID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP plan"})
+		}
+
+		return utils.APIResponse(200, map[string]string{"message": "ESPP plan deleted successfully"})
+	}
+}
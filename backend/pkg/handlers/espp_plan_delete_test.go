@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingDeleteEsppPlanRepository struct {
+	*db.MemoryEsppPlanRepository
+	err error
+}
+
+func (r *failingDeleteEsppPlanRepository) DeleteEsppPlan(_ string) error {
+	return r.err
+}
+
+func TestDeleteEsppPlan(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockLots           []models.EsppLot
+		getError           error
+		lotError           error
+		deleteError        error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful deletion",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			mockLots: []models.EsppLot{
+				{ID: "lot123", UserID: "user123", PlanID: "plan456"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"message":"ESPP plan deleted successfully"}`,
+		},
+		{
+			name:     "plan referenced by a lot",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			mockLots: []models.EsppLot{
+				{ID: "lot123", UserID: "user123", PlanID: "plan123"},
+			},
+			expectedStatusCode: 409,
+			expectedBody:       `{"error":"ESPP plan is still referenced by ESPP lots"}`,
+		},
+		{
+			name:     "plan not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "nonexistent"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP plan not found"}`,
+		},
+		{
+			name:     "other user's plan",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			getError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plan"}`,
+		},
+		{
+			name:     "lot retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete ESPP plan"}`,
+		},
+		{
+			name:     "delete error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			deleteError:        errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete ESPP plan"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing plan ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: planId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan123", UserID: "user123", Name: "Acme ESPP"})
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range tc.mockLots {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.getError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.getError}
+			}
+			if tc.deleteError != nil {
+				planRepo = &failingDeleteEsppPlanRepository{MemoryEsppPlanRepository: memoryPlanRepo, err: tc.deleteError}
+			}
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+
+			handler := DeleteEsppPlan(planRepo, lotRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			if tc.expectedStatusCode == 200 {
+				plan, err := memoryPlanRepo.GetEsppPlan("plan123")
+				assert.NoError(t, err)
+				assert.Nil(t, plan)
+			}
+		})
+	}
+}
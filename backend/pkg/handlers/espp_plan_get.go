@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func GetEsppPlan(planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		planID := request.PathParameters[constants.PathPlanID]
+		if planID == "" {
+			return utils.MissingPathParameterError(constants.PathPlanID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		plan, errResponse, err := getOwnedEsppPlan(planRepo, planID, callerID)
+		if plan == nil {
+			return errResponse, err
+		}
+
+		return utils.APIResponse(200, plan)
+	}
+}
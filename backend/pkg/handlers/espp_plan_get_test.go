@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEsppPlan(t *testing.T) {
+	plan := models.EsppPlan{
+		ID:                   "plan123",
+		UserID:               "user123",
+		Name:                 "Acme ESPP",
+		DiscountPercent:      15,
+		Lookback:             true,
+		OfferingPeriodMonths: 6,
+		PurchasePeriodMonths: 6,
+		ResetRule:            models.EsppResetRuleNone,
+		CreatedAt:            "2023-01-01T00:00:00Z",
+		UpdatedAt:            "2023-01-01T00:00:00Z",
+	}
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful retrieval",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"id":"plan123","userId":"user123","name":"Acme ESPP","discountPercent":15,"lookback":true,"offeringPeriodMonths":6,"purchasePeriodMonths":6,"resetRule":"none","createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-01T00:00:00Z"}`,
+		},
+		{
+			name:     "plan not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "nonexistent"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP plan not found"}`,
+		},
+		{
+			name:     "other user's plan",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plan"}`,
+		},
+		{
+			name:     "missing plan ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: planId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppPlanRepository()
+			memoryRepo.PutEsppPlan(plan)
+
+			var planRepo db.EsppPlanRepository = memoryRepo
+			if tc.mockError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.mockError}
+			}
+
+			handler := GetEsppPlan(planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// UpdateEsppPlan replaces the rules of a plan. Lots keep referencing the plan,
+// so their tax estimates follow the new rules.
+func UpdateEsppPlan(planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		planID := request.PathParameters[constants.PathPlanID]
+		if planID == "" {
+			return utils.MissingPathParameterError(constants.PathPlanID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		var planInput models.EsppPlanInput
+		if err := json.Unmarshal([]byte(request.Body), &planInput); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if planInput.UserID != "" && planInput.UserID != callerID {
+			return utils.ForbiddenError()
+		}
+		planInput.UserID = callerID
+
+		if errs := planInput.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		plan, errResponse, err := getOwnedEsppPlan(planRepo, planID, callerID)
+		if plan == nil {
+			return errResponse, err
+		}
+
+		updatedPlan, err := planRepo.UpdateEsppPlan(planID, planInput)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to update ESPP plan"})
+		}
+
+		if updatedPlan == nil {
+			return utils.APIResponse(404, map[string]string{"error": "ESPP plan not found"})
+		}
+
+		return utils.APIResponse(200, updatedPlan)
+	}
+}
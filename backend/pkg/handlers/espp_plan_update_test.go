@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingUpdateEsppPlanRepository struct {
+	*db.MemoryEsppPlanRepository
+	err error
+}
+
+func (r *failingUpdateEsppPlanRepository) UpdateEsppPlan(_ string, _ models.EsppPlanInput) (*models.EsppPlan, error) {
+	return nil, r.err
+}
+
+func TestUpdateEsppPlan(t *testing.T) {
+	existingPlan := models.EsppPlan{
+		ID:                   "plan123",
+		UserID:               "user123",
+		Name:                 "Acme ESPP",
+		DiscountPercent:      15,
+		Lookback:             true,
+		OfferingPeriodMonths: 6,
+		PurchasePeriodMonths: 6,
+		ResetRule:            models.EsppResetRuleNone,
+		CreatedAt:            "2023-01-01T00:00:00Z",
+		UpdatedAt:            "2023-01-01T00:00:00Z",
+	}
+	body := `{"name":"Acme ESPP","discountPercent":10,"lookback":false,"offeringPeriodMonths":24,"purchasePeriodMonths":6,"resetRule":"lower_price"}`
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		getError           error
+		updateError        error
+		expectedStatusCode int
+		expectedBody       string
+		expectedPlan       *models.EsppPlan
+	}{
+		{
+			name:     "successful update",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           body,
+			},
+			expectedStatusCode: 200,
+			expectedPlan: &models.EsppPlan{
+				ID:                   "plan123",
+				UserID:               "user123",
+				Name:                 "Acme ESPP",
+				DiscountPercent:      10,
+				Lookback:             false,
+				OfferingPeriodMonths: 24,
+				PurchasePeriodMonths: 6,
+				ResetRule:            models.EsppResetRuleLowerPrice,
+				CreatedAt:            "2023-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:     "invalid plan",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           `{"name":"","discountPercent":10,"offeringPeriodMonths":30,"purchasePeriodMonths":6}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"name","message":"is required"},{"field":"offeringPeriodMonths","message":"must be between 1 and 27"}]}`,
+		},
+		{
+			name:     "invalid JSON",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           `{"name":`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "plan not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "nonexistent"},
+				Body:           body,
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP plan not found"}`,
+		},
+		{
+			name:     "other user's plan",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           body,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "retrieve error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           body,
+			},
+			getError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plan"}`,
+		},
+		{
+			name:     "update error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           body,
+			},
+			updateError:        errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to update ESPP plan"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"planId": "plan123"},
+				Body:           body,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing plan ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           body,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: planId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppPlanRepository()
+			memoryRepo.PutEsppPlan(existingPlan)
+
+			var planRepo db.EsppPlanRepository = memoryRepo
+			if tc.getError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.getError}
+			}
+			if tc.updateError != nil {
+				planRepo = &failingUpdateEsppPlanRepository{MemoryEsppPlanRepository: memoryRepo, err: tc.updateError}
+			}
+
+			handler := UpdateEsppPlan(planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedPlan == nil {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			plan, err := memoryRepo.GetEsppPlan(tc.expectedPlan.ID)
+			assert.NoError(t, err)
+			assert.NotEqual(t, existingPlan.UpdatedAt, plan.UpdatedAt)
+			tc.expectedPlan.UpdatedAt = plan.UpdatedAt
+			assert.Equal(t, tc.expectedPlan, plan)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func CreateEsppSale(lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		var sale models.EsppSaleInput
+		if err := json.Unmarshal([]byte(request.Body), &sale); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if errs := sale.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		if errs := lot.ValidateSale(sale); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		existingSales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+		}
+
+		if models.TotalSoldShares(existingSales)+sale.Shares > lot.Shares {
+			return utils.APIResponse(400, map[string]string{"error": "Total sold shares cannot exceed lot shares"})
+		}
+
+		sale.LotID = lot.ID
+		sale.UserID = lot.UserID
+
+		createdSale, err := saleRepo.CreateEsppSale(sale)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to create ESPP sale"})
+		}
+
+		return utils.APIResponse(201, createdSale)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEsppSale(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		callerID             string
+		request              events.APIGatewayProxyRequest
+		mockError            error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:     "successful creation",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 4.0}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"lotId":"lot123"`,
+		},
+		{
+			name:     "sells the remaining shares",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 6.0}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"shares":6`,
+		},
+		{
+			name:     "exceeds lot shares",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 6.5}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `"error":"Total sold shares cannot exceed lot shares"`,
+		},
+		{
+			name:     "sold before purchase",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2023-06-29", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"error":"Validation failed","fields":[{"field":"date","message":"must not be before the lot's purchaseDate"}]}`,
+		},
+		{
+			name:     "sold on the purchase date",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2023-06-30", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   201,
+			expectedBodyContains: `"date":"2023-06-30"`,
+		},
+		{
+			name:     "non-positive shares",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": -1}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `{"error":"Validation failed","fields":[{"field":"shares","message":"must be greater than zero"}]}`,
+		},
+		{
+			name:     "invalid request body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{invalid json}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `"error":"Invalid request body"`,
+		},
+		{
+			name:     "missing lot ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   400,
+			expectedBodyContains: `"error":"Missing path parameter: lotId"`,
+		},
+		{
+			name:     "lot not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "nonexistent"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   404,
+			expectedBodyContains: `"error":"ESPP lot not found"`,
+		},
+		{
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   403,
+			expectedBodyContains: `"error":"Forbidden"`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 1.0}`,
+			},
+			expectedStatusCode:   401,
+			expectedBodyContains: `"error":"Unauthorized"`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+				Body:           `{"date": "2024-07-01", "price": 150.0, "shares": 1.0}`,
+			},
+			mockError:            errors.New("database error"),
+			expectedStatusCode:   500,
+			expectedBodyContains: `"error":"Failed to retrieve ESPP sales"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lotRepo := db.NewMemoryEsppLotRepository()
+			lotRepo.PutEsppLot(models.EsppLot{ID: "lot123", UserID: "user123", PurchaseDate: "2023-06-30", Shares: 10.0})
+
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale123", LotID: "lot123", UserID: "user123", Shares: 4.0})
+
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.mockError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.mockError}
+			}
+
+			handler := CreateEsppSale(lotRepo, saleRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Contains(t, response.Body, tc.expectedBodyContains)
+
+			if tc.expectedStatusCode == 201 {
+				var createdSale models.EsppSale
+				err := json.Unmarshal([]byte(response.Body), &createdSale)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, createdSale.ID)
+				assert.Equal(t, "user123", createdSale.UserID)
+
+				sales, err := memorySaleRepo.GetEsppSalesByLotID("lot123")
+				assert.NoError(t, err)
+				assert.Len(t, sales, 2)
+			}
+		})
+	}
+}
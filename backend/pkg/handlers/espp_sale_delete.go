@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func DeleteEsppSale(lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		saleID := request.PathParameters[constants.PathSaleID]
+		if saleID == "" {
+			return utils.MissingPathParameterError(constants.PathSaleID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		sale, err := saleRepo.GetEsppSale(saleID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sale"})
+		}
+
+		if sale == nil || sale.LotID != lot.ID {
+			return utils.APIResponse(404, map[string]string{"error": "ESPP sale not found"})
+		}
+
+		err = saleRepo.DeleteEsppSale(saleID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete ESPP sale"})
+		}
+
+		return utils.APIResponse(200, map[string]string{"message": "ESPP sale deleted successfully"})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteEsppSale(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful deletion",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123", "saleId": "sale123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"message":"ESPP sale deleted successfully"}`,
+		},
+		{
+			name:     "sale belongs to another lot",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot456", "saleId": "sale123"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP sale not found"}`,
+		},
+		{
+			name:     "sale not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123", "saleId": "nonexistent"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP sale not found"}`,
+		},
+		{
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123", "saleId": "sale123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "missing sale ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: saleId"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123", "saleId": "sale123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sale"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lotRepo := db.NewMemoryEsppLotRepository()
+			lotRepo.PutEsppLot(models.EsppLot{ID: "lot123", UserID: "user123", Shares: 10.0})
+			lotRepo.PutEsppLot(models.EsppLot{ID: "lot456", UserID: "user123", Shares: 10.0})
+
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale123", LotID: "lot123", UserID: "user123", Shares: 4.0})
+
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.mockError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.mockError}
+			}
+
+			handler := DeleteEsppSale(lotRepo, saleRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			storedSale, err := memorySaleRepo.GetEsppSale("sale123")
+			assert.NoError(t, err)
+			if tc.expectedStatusCode == 200 {
+				assert.Nil(t, storedSale)
+			} else {
+				assert.NotNil(t, storedSale)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func ListEsppSales(lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		lotID := request.PathParameters[constants.PathLotID]
+		if lotID == "" {
+			return utils.MissingPathParameterError(constants.PathLotID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		lot, errResponse, err := getOwnedEsppLot(lotRepo, lotID, callerID)
+		if lot == nil {
+			return errResponse, err
+		}
+
+		sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+		}
+
+		return utils.APIResponse(200, sales)
+	}
+}
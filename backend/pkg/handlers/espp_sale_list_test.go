@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListEsppSales(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful retrieval",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `[{"id":"sale123","lotId":"lot123","userId":"user123","date":"2024-07-01","price":150,"shares":4,"createdAt":"2024-07-01T00:00:00Z","updatedAt":"2024-07-01T00:00:00Z"}]`,
+		},
+		{
+			name:     "no sales",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot456"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `[]`,
+		},
+		{
+			name:     "other user's lot",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "lot not found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "nonexistent"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"ESPP lot not found"}`,
+		},
+		{
+			name:     "missing lot ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: lotId"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"lotId": "lot123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sales"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lotRepo := db.NewMemoryEsppLotRepository()
+			lotRepo.PutEsppLot(models.EsppLot{ID: "lot123", UserID: "user123", Shares: 10.0})
+			lotRepo.PutEsppLot(models.EsppLot{ID: "lot456", UserID: "user123", Shares: 10.0})
+
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			memorySaleRepo.PutEsppSale(models.EsppSale{
+				ID:        "sale123",
+				LotID:     "lot123",
+				UserID:    "user123",
+				Date:      "2024-07-01",
+				Price:     150.0,
+				Shares:    4.0,
+				CreatedAt: "2024-07-01T00:00:00Z",
+				UpdatedAt: "2024-07-01T00:00:00Z",
+			})
+
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.mockError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.mockError}
+			}
+
+			handler := ListEsppSales(lotRepo, saleRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
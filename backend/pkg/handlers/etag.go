@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// versionETag is the strong entity tag for a version of a user or lot.
+func versionETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion reads the If-Match header of a write. It returns a nil
+// version when the write is unconditional, because the header is missing or
+// is "*". ok is false when the header names no version we could have sent,
+// like a weak tag or a list of tags, so it cannot match.
+func ifMatchVersion(headers map[string]string) (version *int64, ok bool) {
+	for name, value := range headers {
+		if !strings.EqualFold(name, "If-Match") {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "*" {
+			return nil, true
+		}
+
+		tag, found := strings.CutPrefix(value, `"`)
+		tag, closed := strings.CutSuffix(tag, `"`)
+		if !found || !closed {
+			return nil, false
+		}
+
+		parsed, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, false
+		}
+
+		return &parsed, true
+	}
+
+	return nil, true
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	testCases := []struct {
+		name            string
+		headers         map[string]string
+		expectedVersion *int64
+		expectedOK      bool
+	}{
+		{
+			name:       "no headers",
+			headers:    nil,
+			expectedOK: true,
+		},
+		{
+			name:       "no If-Match",
+			headers:    map[string]string{"Content-Type": "application/json"},
+			expectedOK: true,
+		},
+		{
+			name:       "wildcard",
+			headers:    map[string]string{"If-Match": "*"},
+			expectedOK: true,
+		},
+		{
+			name:            "strong tag",
+			headers:         map[string]string{"If-Match": `"4"`},
+			expectedVersion: int64Ptr(4),
+			expectedOK:      true,
+		},
+		{
+			name:            "lowercase header",
+			headers:         map[string]string{"if-match": ` "0" `},
+			expectedVersion: int64Ptr(0),
+			expectedOK:      true,
+		},
+		{
+			name:    "weak tag",
+			headers: map[string]string{"If-Match": `W/"4"`},
+		},
+		{
+			name:    "unquoted tag",
+			headers: map[string]string{"If-Match": "4"},
+		},
+		{
+			name:    "list of tags",
+			headers: map[string]string{"If-Match": `"3", "4"`},
+		},
+		{
+			name:    "negative version",
+			headers: map[string]string{"If-Match": `"-1"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, ok := ifMatchVersion(tc.headers)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedVersion, version)
+		})
+	}
+}
+
+func TestVersionETag(t *testing.T) {
+	assert.Equal(t, `"0"`, versionETag(0))
+	assert.Equal(t, `"12"`, versionETag(12))
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
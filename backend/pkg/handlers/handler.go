@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type Handler = func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// PostConfirmationHandler handles the Cognito trigger that runs after a user
+// confirms their account. It must return the event it was given.
+type PostConfirmationHandler = func(ctx context.Context, event events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error)
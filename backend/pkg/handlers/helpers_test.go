@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+)
+
+func callerContext(userID string) context.Context {
+	return auth.WithClaims(context.Background(), &auth.Claims{Subject: userID, TokenUse: auth.TokenUseID})
+}
+
+func adminContext(userID string) context.Context {
+	return auth.WithClaims(context.Background(), &auth.Claims{Subject: userID, TokenUse: auth.TokenUseID, Groups: []string{auth.AdminGroup}})
+}
+
+type failingUserRepository struct {
+	err error
+}
+
+var _ db.UserRepository = &failingUserRepository{}
+
+func (r *failingUserRepository) GetUser(_ string) (*models.User, error) {
+	return nil, r.err
+}
+
+func (r *failingUserRepository) UpdateUserSettings(_ string, _ models.UserSettings, _ *int64) (*models.User, error) {
+	return nil, r.err
+}
+
+func (r *failingUserRepository) SetCalendarTokenHash(_ string, _ string) (bool, error) {
+	return false, r.err
+}
+
+func (r *failingUserRepository) ListUsers() ([]*models.User, error) {
+	return nil, r.err
+}
+
+func (r *failingUserRepository) SetNotificationCheckpoints(_ string, _ models.NotificationCheckpoints) (bool, error) {
+	return false, r.err
+}
+
+func (r *failingUserRepository) CreateUser(_ models.User) (*models.User, error) {
+	return nil, r.err
+}
+
+func (r *failingUserRepository) DeleteUser(_ string) error {
+	return r.err
+}
+
+func (r *failingUserRepository) ReplaceUser(_ models.User) error {
+	return r.err
+}
+
+type failingEsppLotRepository struct {
+	err error
+}
+
+var _ db.EsppLotRepository = &failingEsppLotRepository{}
+
+func (r *failingEsppLotRepository) CreateEsppLot(_ models.EsppLotInput) (*models.EsppLot, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) BatchCreateEsppLots(_ []models.EsppLotInput) ([]*models.EsppLot, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) GetEsppLot(_ string) (*models.EsppLot, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) GetEsppLotsByUserID(_ string) ([]*models.EsppLot, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) ListEsppLotsByUserID(_ models.EsppLotListOptions) (*models.EsppLotPage, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) UpdateEsppLot(_ string, _ models.EsppLotUpdate, _ *int64) (*models.EsppLot, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppLotRepository) DeleteEsppLot(_ string) error {
+	return r.err
+}
+
+func (r *failingEsppLotRepository) DeleteEsppLotsByUserID(_ string) (int, error) {
+	return 0, r.err
+}
+
+func (r *failingEsppLotRepository) BatchPutEsppLots(_ []models.EsppLot) error {
+	return r.err
+}
+
+type failingEsppSaleRepository struct {
+	err error
+}
+
+var _ db.EsppSaleRepository = &failingEsppSaleRepository{}
+
+func (r *failingEsppSaleRepository) CreateEsppSale(_ models.EsppSaleInput) (*models.EsppSale, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppSaleRepository) GetEsppSale(_ string) (*models.EsppSale, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppSaleRepository) GetEsppSalesByLotID(_ string) ([]*models.EsppSale, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppSaleRepository) DeleteEsppSale(_ string) error {
+	return r.err
+}
+
+func (r *failingEsppSaleRepository) BatchDeleteEsppSales(_ []string) error {
+	return r.err
+}
+
+func (r *failingEsppSaleRepository) BatchPutEsppSales(_ []models.EsppSale) error {
+	return r.err
+}
+
+type failingPriceProvider struct {
+	err error
+}
+
+var _ quotes.PriceProvider = &failingPriceProvider{}
+
+func (p *failingPriceProvider) LatestQuote(_ string) (*models.Quote, error) {
+	return nil, p.err
+}
+
+type failingPriceHistoryRepository struct {
+	err error
+}
+
+var _ db.PriceHistoryRepository = &failingPriceHistoryRepository{}
+
+func (r *failingPriceHistoryRepository) GetClosingPriceOnOrBefore(_ string, _ string) (*models.ClosingPrice, error) {
+	return nil, r.err
+}
+
+func (r *failingPriceHistoryRepository) BatchPutClosingPrices(_ []models.ClosingPrice) error {
+	return r.err
+}
+
+type failingEsppPlanRepository struct {
+	err error
+}
+
+var _ db.EsppPlanRepository = &failingEsppPlanRepository{}
+
+func (r *failingEsppPlanRepository) CreateEsppPlan(_ models.EsppPlanInput) (*models.EsppPlan, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppPlanRepository) GetEsppPlan(_ string) (*models.EsppPlan, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppPlanRepository) GetEsppPlansByUserID(_ string) ([]*models.EsppPlan, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppPlanRepository) UpdateEsppPlan(_ string, _ models.EsppPlanInput) (*models.EsppPlan, error) {
+	return nil, r.err
+}
+
+func (r *failingEsppPlanRepository) DeleteEsppPlan(_ string) error {
+	return r.err
+}
+
+func (r *failingEsppPlanRepository) DeleteEsppPlansByUserID(_ string) (int, error) {
+	return 0, r.err
+}
+
+func (r *failingEsppPlanRepository) BatchPutEsppPlans(_ []models.EsppPlan) error {
+	return r.err
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func GetQuote(quoteProvider quotes.PriceProvider) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ticker := models.NormalizeTicker(request.PathParameters[constants.PathTicker])
+		if ticker == "" {
+			return utils.MissingPathParameterError(constants.PathTicker)
+		}
+
+		if _, ok := auth.UserIDFromContext(ctx); !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if !models.IsValidTicker(ticker) {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Invalid ticker: %s", ticker)})
+		}
+
+		quote, err := quoteProvider.LatestQuote(ticker)
+		if err != nil {
+			slog.Error("Failed to retrieve quote", slog.String("ticker", ticker), slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve quote"})
+		}
+
+		if quote == nil {
+			return utils.APIResponse(404, map[string]string{"error": "Quote not found"})
+		}
+
+		return utils.APIResponse(200, quote)
+	}
+}
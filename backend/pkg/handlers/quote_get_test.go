@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQuote(t *testing.T) {
+	quoteProvider := quotes.NewFileProvider([]models.Quote{
+		{Ticker: "NKE", Price: 97.5, Date: "2026-10-16", UpdatedAt: "2026-10-16T21:00:00Z"},
+	})
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		quoteError         error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "nke"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"ticker":"NKE","price":97.5,"date":"2026-10-16","updatedAt":"2026-10-16T21:00:00Z"}`,
+		},
+		{
+			name:     "Missing Ticker",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: ticker"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Invalid Ticker",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "$NKE"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid ticker: $NKE"}`,
+		},
+		{
+			name:     "Quote Not Found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "AAPL"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"Quote not found"}`,
+		},
+		{
+			name:     "Provider Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+			},
+			quoteError:         errors.New("quote service down"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve quote"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var provider quotes.PriceProvider = quoteProvider
+			if tc.quoteError != nil {
+				provider = &failingPriceProvider{err: tc.quoteError}
+			}
+
+			response, err := GetQuote(provider)(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
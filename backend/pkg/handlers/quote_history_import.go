@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type priceHistoryImportReport struct {
+	Ticker   string `json:"ticker"`
+	Imported int    `json:"imported"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// ImportQuoteHistory records the daily closes of a ticker from a CSV price
+// history export. Closes already recorded for the same dates are replaced.
+// The price history is shared by every user's lots, so only admins may import.
+func ImportQuoteHistory(historyRepo db.PriceHistoryRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ticker := models.NormalizeTicker(request.PathParameters[constants.PathTicker])
+		if ticker == "" {
+			return utils.MissingPathParameterError(constants.PathTicker)
+		}
+
+		if _, ok := auth.UserIDFromContext(ctx); !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if !auth.IsAdmin(ctx) {
+			return utils.ForbiddenError()
+		}
+
+		if !models.IsValidTicker(ticker) {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Invalid ticker: %s", ticker)})
+		}
+
+		body := []byte(request.Body)
+		if request.IsBase64Encoded {
+			decoded, err := base64.StdEncoding.DecodeString(request.Body)
+			if err != nil {
+				return utils.InvalidRequestBodyError()
+			}
+			body = decoded
+		}
+
+		prices, err := quotes.ParseHistoryCSV(ticker, body)
+		if err != nil {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Invalid price history file: %s", err)})
+		}
+
+		if len(prices) > constants.MaxPriceHistoryRows {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Price history is limited to %d rows", constants.MaxPriceHistoryRows)})
+		}
+
+		if err := historyRepo.BatchPutClosingPrices(prices); err != nil {
+			slog.Error("Failed to import price history", slog.String("ticker", ticker), slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to import price history"})
+		}
+
+		report := priceHistoryImportReport{Ticker: ticker, Imported: len(prices)}
+		for _, price := range prices {
+			if report.From == "" || price.Date < report.From {
+				report.From = price.Date
+			}
+			if price.Date > report.To {
+				report.To = price.Date
+			}
+		}
+
+		return utils.APIResponse(200, report)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportQuoteHistory(t *testing.T) {
+	history := "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+		"2023-03-31,119.60,121.00,119.00,120.10,118.80,100\n" +
+		"2023-03-30,118.00,120.00,117.50,119.50,118.20,100\n"
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		admin              bool
+		request            events.APIGatewayProxyRequest
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+		expectedClose      *models.ClosingPrice
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "nke"},
+				Body:           history,
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"ticker":"NKE","imported":2,"from":"2023-03-30","to":"2023-03-31"}`,
+			expectedClose:      &models.ClosingPrice{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+		},
+		{
+			name:     "Base64 Body",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters:  map[string]string{"ticker": "NKE"},
+				Body:            base64.StdEncoding.EncodeToString([]byte(history)),
+				IsBase64Encoded: true,
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"ticker":"NKE","imported":2,"from":"2023-03-30","to":"2023-03-31"}`,
+			expectedClose:      &models.ClosingPrice{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+		},
+		{
+			name:     "Missing Ticker",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: ticker"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+				Body:           history,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Not Admin",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+				Body:           history,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Invalid Ticker",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "$NKE"},
+				Body:           history,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid ticker: $NKE"}`,
+		},
+		{
+			name:     "Invalid File",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+				Body:           "Date,Open\n2023-03-31,119.60\n",
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid price history file: expected Date and Close columns"}`,
+		},
+		{
+			name:     "Conflicting Duplicate Date",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+				Body:           "Date,Close\n2023-03-31,120.10\n03/31/2023,121.00\n",
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid price history file: row 2: close for 2023-03-31 conflicts with row 1"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			admin:    true,
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"ticker": "NKE"},
+				Body:           history,
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to import price history"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryPriceHistoryRepository()
+
+			var historyRepo db.PriceHistoryRepository = memoryRepo
+			if tc.mockError != nil {
+				historyRepo = &failingPriceHistoryRepository{err: tc.mockError}
+			}
+
+			ctx := callerContext(tc.callerID)
+			if tc.admin {
+				ctx = adminContext(tc.callerID)
+			}
+
+			response, err := ImportQuoteHistory(historyRepo)(ctx, tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			if tc.expectedClose != nil {
+				stored, err := memoryRepo.GetClosingPriceOnOrBefore("NKE", "2023-03-31")
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedClose, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/retirement"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func PlanUser401k(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		var input models.Retirement401kPlanInput
+		if request.Body != "" {
+			if err := json.Unmarshal([]byte(request.Body), &input); err != nil {
+				return utils.InvalidRequestBodyError()
+			}
+		}
+
+		if errs := input.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		if user == nil {
+			return utils.APIResponse(404, map[string]string{"error": "User not found"})
+		}
+
+		finance := user.Settings.Finance
+		if finance.AnnualSalary <= 0 || finance.PaychecksPerYear <= 0 {
+			return utils.APIResponse(400, map[string]string{"error": "User finance settings are incomplete"})
+		}
+
+		if input.Year == 0 {
+			input.Year = time.Now().UTC().Year()
+		}
+
+		if input.PaychecksRemaining == 0 {
+			input.PaychecksRemaining = finance.PaychecksPerYear
+		}
+
+		if input.PaychecksRemaining > finance.PaychecksPerYear {
+			return validationError(models.ValidationErrors{
+				{Field: "paychecksRemaining", Message: "must not be greater than finance.paychecksPerYear"},
+			})
+		}
+
+		settings := user.Settings.Retirement
+
+		age := 0
+		if settings.BirthDate != "" {
+			birthDate, err := time.Parse(models.DateLayout, settings.BirthDate)
+			if err != nil {
+				return utils.APIResponse(400, map[string]string{"error": "User birth date is invalid"})
+			}
+			age = retirement.AgeAtYearEnd(birthDate, input.Year)
+		}
+
+		plan, err := retirement.BuildPlan(retirement.PlanInput{
+			Year:               input.Year,
+			Age:                age,
+			AnnualSalary:       finance.AnnualSalary,
+			PaychecksPerYear:   finance.PaychecksPerYear,
+			PaychecksRemaining: input.PaychecksRemaining,
+			ContributionsSoFar: input.ContributionsSoFar,
+			EmployerMatchRate:  settings.EmployerMatchRate,
+			EmployerMatchLimit: settings.EmployerMatchLimit,
+			RothPercent:        settings.RothPercent,
+			ContributionLimit:  input.ContributionLimit,
+		})
+		if errors.Is(err, retirement.ErrUnknownYear) {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("No 401k limits for year %d", input.Year)})
+		}
+		if err != nil {
+			slog.Error("Failed to build 401k plan", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to build 401k plan"})
+		}
+
+		return utils.APIResponse(200, plan)
+	}
+}
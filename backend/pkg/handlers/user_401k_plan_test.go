@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/retirement"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanUser401k(t *testing.T) {
+	existingUser := &models.User{
+		UserID: "user123",
+		Settings: models.UserSettings{
+			Finance: models.UserFinanceSettings{
+				AnnualSalary:     130000,
+				PaychecksPerYear: 26,
+			},
+			Retirement: models.UserRetirementSettings{
+				BirthDate:          "1990-05-01",
+				EmployerMatchRate:  100,
+				EmployerMatchLimit: 6,
+				RothPercent:        25,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		existingUser       *models.User
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"year":2026,"contributionsSoFar":12000,"paychecksRemaining":10}`,
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 200,
+		},
+		{
+			name:     "Catch-up From Birth Date",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"year":2026}`,
+			},
+			existingUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance:    models.UserFinanceSettings{AnnualSalary: 200000, PaychecksPerYear: 24},
+					Retirement: models.UserRetirementSettings{BirthDate: "1965-01-01"},
+				},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Invalid Body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"year":"next"}`,
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "Invalid Fields",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"contributionsSoFar":-1}`,
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"contributionsSoFar","message":"must not be negative"}]}`,
+		},
+		{
+			name:     "Too Many Paychecks Remaining",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"year":2026,"paychecksRemaining":27}`,
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"paychecksRemaining","message":"must not be greater than finance.paychecksPerYear"}]}`,
+		},
+		{
+			name:     "User Not Found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"User not found"}`,
+		},
+		{
+			name:     "Incomplete Finance Settings",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			existingUser: &models.User{
+				UserID:   "user123",
+				Settings: models.UserSettings{Finance: models.UserFinanceSettings{PaychecksPerYear: 26}},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"User finance settings are incomplete"}`,
+		},
+		{
+			name:     "Unknown Year",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"year":2040}`,
+			},
+			existingUser:       existingUser,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"No 401k limits for year 2040"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryUserRepository()
+			if tc.existingUser != nil {
+				memoryRepo.PutUser(*tc.existingUser)
+			}
+
+			var userRepo db.UserRepository = memoryRepo
+			if tc.mockError != nil {
+				userRepo = &failingUserRepository{err: tc.mockError}
+			}
+
+			handlerFn := PlanUser401k(userRepo)
+
+			response, err := handlerFn(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+
+			if tc.expectedStatusCode != 200 {
+				assert.Equal(t, tc.expectedBody, response.Body)
+			}
+		})
+	}
+}
+
+func TestPlanUser401kSchedule(t *testing.T) {
+	userRepo := db.NewMemoryUserRepository()
+	userRepo.PutUser(models.User{
+		UserID: "user123",
+		Settings: models.UserSettings{
+			Finance: models.UserFinanceSettings{AnnualSalary: 200000, PaychecksPerYear: 24},
+			Retirement: models.UserRetirementSettings{
+				BirthDate:          "1965-01-01",
+				EmployerMatchRate:  50,
+				EmployerMatchLimit: 6,
+			},
+		},
+	})
+
+	response, err := PlanUser401k(userRepo)(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+		Body:           `{"year":2026,"paychecksRemaining":12,"contributionsSoFar":17750}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var plan retirement.Plan
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &plan))
+
+	// Age 61 in 2026 gets the larger catch-up.
+	assert.Equal(t, 11250.0, plan.CatchUp)
+	assert.Equal(t, 35750.0, plan.ContributionLimit)
+	assert.Equal(t, 18000.0, plan.RemainingContribution)
+	assert.Len(t, plan.Paychecks, 12)
+	assert.InDelta(t, 18000, plan.TotalContribution, 0.001)
+	assert.InDelta(t, 0, plan.MissedEmployerMatch, 0.001)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// ExportUserArchive returns everything stored for the user as a versioned JSON
+// document that RestoreUserArchive can load back. Lots are ordered by purchase
+// date and sales follow the lots they belong to.
+func ExportUserArchive(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		archive := models.UserArchive{
+			Version:    models.ArchiveVersion,
+			ExportedAt: utils.GetCurrentTimeUTC(),
+			EsppSales:  []*models.EsppSale{},
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to export archive"})
+		}
+		archive.User = user
+
+		archive.EsppPlans, err = planRepo.GetEsppPlansByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP plans", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to export archive"})
+		}
+
+		archive.EsppLots, err = listAllEsppLots(lotRepo, userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to export archive"})
+		}
+
+		for _, lot := range archive.EsppLots {
+			sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to export archive"})
+			}
+			archive.EsppSales = append(archive.EsppSales, sales...)
+		}
+
+		body, err := json.Marshal(archive)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to export archive"})
+		}
+		return utils.AttachmentResponse("application/json", "fife-archive.json", body)
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// archiveTestRepos holds one user's data alongside another user's, for the
+// archive export and restore tests.
+type archiveTestRepos struct {
+	users *db.MemoryUserRepository
+	lots  *db.MemoryEsppLotRepository
+	sales *db.MemoryEsppSaleRepository
+	plans *db.MemoryEsppPlanRepository
+}
+
+func newArchiveTestRepos() archiveTestRepos {
+	repos := archiveTestRepos{
+		users: db.NewMemoryUserRepository(),
+		lots:  db.NewMemoryEsppLotRepository(),
+		sales: db.NewMemoryEsppSaleRepository(),
+		plans: db.NewMemoryEsppPlanRepository(),
+	}
+
+	repos.users.PutUser(models.User{
+		UserID:            "user123",
+		Settings:          models.UserSettings{Finance: models.UserFinanceSettings{AnnualSalary: 120000, PaychecksPerYear: 24}},
+		CalendarTokenHash: "hash",
+		Version:           2,
+		CreatedAt:         "2023-01-01T00:00:00Z",
+		UpdatedAt:         "2023-02-01T00:00:00Z",
+	})
+	repos.plans.PutEsppPlan(models.EsppPlan{
+		ID: "plan1", UserID: "user123", Name: "Acme ESPP", DiscountPercent: 15, Lookback: true,
+		OfferingPeriodMonths: 6, PurchasePeriodMonths: 6, ResetRule: models.EsppResetRuleNone,
+		CreatedAt: "2023-01-01T00:00:00Z", UpdatedAt: "2023-01-01T00:00:00Z",
+	})
+	repos.lots.PutEsppLot(models.EsppLot{
+		ID: "lot2", UserID: "user123", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29",
+		OfferStartPrice: 110, OfferEndPrice: 130, PurchasePrice: 93.5, Shares: 8, PlanID: "plan1", Version: 1,
+		CreatedAt: "2024-01-02T00:00:00Z", UpdatedAt: "2024-01-02T00:00:00Z",
+	})
+	repos.lots.PutEsppLot(models.EsppLot{
+		ID: "lot1", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30",
+		OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 10, Ticker: "ACME", Version: 4,
+		CreatedAt: "2023-07-01T00:00:00Z", UpdatedAt: "2023-07-01T00:00:00Z",
+	})
+	repos.sales.PutEsppSale(models.EsppSale{
+		ID: "sale1", LotID: "lot1", UserID: "user123", Date: "2024-03-01", Price: 140, Shares: 4,
+		CreatedAt: "2024-03-01T00:00:00Z", UpdatedAt: "2024-03-01T00:00:00Z",
+	})
+
+	repos.users.PutUser(models.User{UserID: "user456"})
+	repos.plans.PutEsppPlan(models.EsppPlan{ID: "plan9", UserID: "user456", Name: "Other ESPP"})
+	repos.lots.PutEsppLot(models.EsppLot{ID: "lot9", UserID: "user456", PurchaseDate: "2023-06-30", Shares: 5})
+	repos.sales.PutEsppSale(models.EsppSale{ID: "sale9", LotID: "lot9", UserID: "user456", Shares: 1})
+
+	return repos
+}
+
+func TestExportUserArchive(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		failUsers          bool
+		failLots           bool
+		failSales          bool
+		failPlans          bool
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:     "User Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			failUsers:          true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to export archive"}`,
+		},
+		{
+			name:     "Lot Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			failLots:           true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to export archive"}`,
+		},
+		{
+			name:     "Sale Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			failSales:          true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to export archive"}`,
+		},
+		{
+			name:     "Plan Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			failPlans:          true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to export archive"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repos := newArchiveTestRepos()
+
+			var userRepo db.UserRepository = repos.users
+			if tc.failUsers {
+				userRepo = &failingUserRepository{err: errors.New("database error")}
+			}
+			var lotRepo db.EsppLotRepository = repos.lots
+			if tc.failLots {
+				lotRepo = &failingEsppLotRepository{err: errors.New("database error")}
+			}
+			var saleRepo db.EsppSaleRepository = repos.sales
+			if tc.failSales {
+				saleRepo = &failingEsppSaleRepository{err: errors.New("database error")}
+			}
+			var planRepo db.EsppPlanRepository = repos.plans
+			if tc.failPlans {
+				planRepo = &failingEsppPlanRepository{err: errors.New("database error")}
+			}
+
+			handler := ExportUserArchive(userRepo, lotRepo, saleRepo, planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			assert.Equal(t, "application/json", response.Headers["Content-Type"])
+			assert.Equal(t, `attachment; filename="fife-archive.json"`, response.Headers["Content-Disposition"])
+			assert.NotContains(t, response.Body, "hash")
+
+			var archive models.UserArchive
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &archive))
+			assert.Equal(t, models.ArchiveVersion, archive.Version)
+			assert.NotEmpty(t, archive.ExportedAt)
+			assert.Equal(t, "user123", archive.User.UserID)
+			assert.Equal(t, "2023-01-01T00:00:00Z", archive.User.CreatedAt)
+			assert.Len(t, archive.EsppPlans, 1)
+			assert.Len(t, archive.EsppLots, 2)
+			assert.Equal(t, "lot1", archive.EsppLots[0].ID)
+			assert.Equal(t, "lot2", archive.EsppLots[1].ID)
+			assert.Len(t, archive.EsppSales, 1)
+			assert.Equal(t, "sale1", archive.EsppSales[0].ID)
+		})
+	}
+}
+
+func TestExportUserArchiveWithoutData(t *testing.T) {
+	handler := ExportUserArchive(db.NewMemoryUserRepository(), db.NewMemoryEsppLotRepository(), db.NewMemoryEsppSaleRepository(), db.NewMemoryEsppPlanRepository())
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Contains(t, response.Body, `"user":null,"esppPlans":[],"esppLots":[],"esppSales":[]`)
+}
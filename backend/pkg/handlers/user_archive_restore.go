@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type archiveRestoreReport struct {
+	Mode     models.ArchiveMode `json:"mode"`
+	User     bool               `json:"user"`
+	Restored esppDataCounts     `json:"restored"`
+	Removed  esppDataCounts     `json:"removed"`
+}
+
+// RestoreUserArchive loads an archive from ExportUserArchive back into the
+// user's account, keeping the IDs and timestamps it holds. The mode query
+// parameter picks merge, the default, or replace. The user's calendar token
+// and notification checkpoints are never in an archive, so they are kept.
+func RestoreUserArchive(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		mode := models.ArchiveMode(request.QueryStringParameters[constants.QueryMode])
+		if mode == "" {
+			mode = models.ArchiveModeMerge
+		}
+		if mode != models.ArchiveModeMerge && mode != models.ArchiveModeReplace {
+			return utils.InvalidQueryParameterError(constants.QueryMode)
+		}
+
+		var archive models.UserArchive
+		if err := json.Unmarshal([]byte(request.Body), &archive); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if errs := archive.Validate(userID); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		// IDs are keys shared by every user, so an archive must not write over
+		// another user's items.
+		foreign, lotVersions, err := archiveHasForeignIDs(archive, userID, lotRepo, saleRepo, planRepo)
+		if err != nil {
+			slog.Error("Failed to check archive IDs", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+		}
+		if foreign {
+			return utils.APIResponse(409, map[string]string{"error": "Archive contains IDs that belong to another user"})
+		}
+
+		report := archiveRestoreReport{Mode: mode}
+
+		if mode == models.ArchiveModeMerge {
+			oversold, err := archiveOversellsLots(archive, saleRepo)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+			}
+			if oversold {
+				return utils.APIResponse(400, map[string]string{"error": "Total sold shares cannot exceed lot shares"})
+			}
+		} else {
+			report.Removed, err = deleteEsppData(userID, lotRepo, saleRepo, planRepo)
+			if err != nil {
+				return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+			}
+		}
+
+		now := utils.GetCurrentTimeUTC()
+
+		// Parents are written before their children, so a failure part way
+		// never leaves a sale without its lot.
+		plans := make([]models.EsppPlan, 0, len(archive.EsppPlans))
+		for _, plan := range archive.EsppPlans {
+			plan.CreatedAt, plan.UpdatedAt = archiveTimestamps(plan.CreatedAt, plan.UpdatedAt, now)
+			plans = append(plans, *plan)
+		}
+		if err := planRepo.BatchPutEsppPlans(plans); err != nil {
+			slog.Error("Failed to restore ESPP plans", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+		}
+		report.Restored.Plans = len(plans)
+
+		lots := make([]models.EsppLot, 0, len(archive.EsppLots))
+		for _, lot := range archive.EsppLots {
+			lot.CreatedAt, lot.UpdatedAt = archiveTimestamps(lot.CreatedAt, lot.UpdatedAt, now)
+			lot.Version = restoredVersion(lot.Version, lotVersions[lot.ID])
+			lots = append(lots, *lot)
+		}
+		if err := lotRepo.BatchPutEsppLots(lots); err != nil {
+			slog.Error("Failed to restore ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+		}
+		report.Restored.Lots = len(lots)
+
+		sales := make([]models.EsppSale, 0, len(archive.EsppSales))
+		for _, sale := range archive.EsppSales {
+			sale.CreatedAt, sale.UpdatedAt = archiveTimestamps(sale.CreatedAt, sale.UpdatedAt, now)
+			sales = append(sales, *sale)
+		}
+		if err := saleRepo.BatchPutEsppSales(sales); err != nil {
+			slog.Error("Failed to restore ESPP sales", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+		}
+		report.Restored.Sales = len(sales)
+
+		if archive.User != nil {
+			if err := restoreArchivedUser(userRepo, *archive.User, now); err != nil {
+				slog.Error("Failed to restore user", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to restore archive"})
+			}
+			report.User = true
+		}
+
+		return utils.APIResponse(200, report)
+	}
+}
+
+// archiveHasForeignIDs reports whether any item in the archive has the ID of
+// an item stored for another user. It also returns the versions of the
+// archived lots that are already stored, for restoredVersion.
+func archiveHasForeignIDs(archive models.UserArchive, userID string, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) (bool, map[string]int64, error) {
+	for _, plan := range archive.EsppPlans {
+		stored, err := planRepo.GetEsppPlan(plan.ID)
+		if err != nil {
+			return false, nil, err
+		}
+		if stored != nil && stored.UserID != userID {
+			return true, nil, nil
+		}
+	}
+
+	lotVersions := map[string]int64{}
+	for _, lot := range archive.EsppLots {
+		stored, err := lotRepo.GetEsppLot(lot.ID)
+		if err != nil {
+			return false, nil, err
+		}
+		if stored == nil {
+			continue
+		}
+		if stored.UserID != userID {
+			return true, nil, nil
+		}
+		lotVersions[lot.ID] = stored.Version
+	}
+
+	for _, sale := range archive.EsppSales {
+		stored, err := saleRepo.GetEsppSale(sale.ID)
+		if err != nil {
+			return false, nil, err
+		}
+		if stored != nil && stored.UserID != userID {
+			return true, nil, nil
+		}
+	}
+
+	return false, lotVersions, nil
+}
+
+// archiveOversellsLots reports whether merging the archive would leave a lot
+// with more shares sold than it holds, counting the stored sales of each
+// archived lot that the archive does not replace.
+func archiveOversellsLots(archive models.UserArchive, saleRepo db.EsppSaleRepository) (bool, error) {
+	archivedSales := map[string]bool{}
+	soldShares := map[string]float64{}
+	for _, sale := range archive.EsppSales {
+		archivedSales[sale.ID] = true
+		soldShares[sale.LotID] += sale.Shares
+	}
+
+	for _, lot := range archive.EsppLots {
+		stored, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			return false, err
+		}
+
+		sold := soldShares[lot.ID]
+		for _, sale := range stored {
+			if !archivedSales[sale.ID] {
+				sold += sale.Shares
+			}
+		}
+		if sold > lot.Shares {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// restoreArchivedUser writes the archived user, carrying over the fields an
+// archive never holds from the stored user.
+func restoreArchivedUser(userRepo db.UserRepository, user models.User, now string) error {
+	stored, err := userRepo.GetUser(user.UserID)
+	if err != nil {
+		return err
+	}
+	var storedVersion int64
+	if stored != nil {
+		user.CalendarTokenHash = stored.CalendarTokenHash
+		user.NotificationCheckpoints = stored.NotificationCheckpoints
+		storedVersion = stored.Version
+	}
+
+	user.CreatedAt, user.UpdatedAt = archiveTimestamps(user.CreatedAt, user.UpdatedAt, now)
+	user.Version = restoredVersion(user.Version, storedVersion)
+
+	return userRepo.ReplaceUser(user)
+}
+
+// restoredVersion is the version for an archived user or lot that is written
+// over a stored one, 0 when nothing is stored. It is always past the stored
+// version, so an ETag read before the restore no longer matches.
+func restoredVersion(archived int64, stored int64) int64 {
+	return max(archived, stored+1)
+}
+
+// archiveTimestamps keeps the archived timestamps, filling in now for any that
+// are missing.
+func archiveTimestamps(createdAt string, updatedAt string, now string) (string, string) {
+	if createdAt == "" {
+		createdAt = now
+	}
+	if updatedAt == "" {
+		updatedAt = createdAt
+	}
+
+	return createdAt, updatedAt
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingBatchPutEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+	err error
+}
+
+func (r *failingBatchPutEsppLotRepository) BatchPutEsppLots(_ []models.EsppLot) error {
+	return r.err
+}
+
+const restoreArchiveBody = `{
+	"version": 1,
+	"user": {"userId": "user123", "settings": {"finance": {"annualSalary": 150000, "paychecksPerYear": 26}}, "createdAt": "2022-05-01T00:00:00Z", "updatedAt": "2022-06-01T00:00:00Z"},
+	"esppPlans": [],
+	"esppLots": [
+		{"id": "lot1", "userId": "user123", "grantDate": "2023-01-01", "purchaseDate": "2023-06-30", "offerStartPrice": 100, "offerEndPrice": 120, "purchasePrice": 85, "shares": 12, "createdAt": "2023-07-01T00:00:00Z", "updatedAt": "2023-07-01T00:00:00Z"},
+		{"id": "lot3", "userId": "user123", "grantDate": "2024-01-01", "purchaseDate": "2024-06-28", "offerStartPrice": 150, "offerEndPrice": 160, "purchasePrice": 127.5, "shares": 6, "createdAt": "2024-07-01T00:00:00Z"}
+	],
+	"esppSales": [
+		{"id": "sale2", "lotId": "lot1", "userId": "user123", "date": "2024-05-01", "price": 150, "shares": 7, "createdAt": "2024-05-01T00:00:00Z", "updatedAt": "2024-05-01T00:00:00Z"}
+	]
+}`
+
+func TestRestoreUserArchive(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		failLotPut         bool
+		failSales          bool
+		expectedStatusCode int
+		expectedBody       string
+		expectedLots       []string
+		expectedSales      []string
+	}{
+		{
+			name:     "Merge",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           restoreArchiveBody,
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"mode":"merge","user":true,"restored":{"lots":2,"sales":1,"plans":0},"removed":{"lots":0,"sales":0,"plans":0}}`,
+			expectedLots:       []string{"lot1", "lot2", "lot3"},
+			expectedSales:      []string{"sale1", "sale2"},
+		},
+		{
+			name:     "Replace",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"mode": "replace"},
+				Body:                  restoreArchiveBody,
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"mode":"replace","user":true,"restored":{"lots":2,"sales":1,"plans":0},"removed":{"lots":2,"sales":1,"plans":1}}`,
+			expectedLots:       []string{"lot1", "lot3"},
+			expectedSales:      []string{"sale2"},
+		},
+		{
+			name:     "Merge Oversells Lot",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"version":1,"esppLots":[{"id":"lot1","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}],"esppSales":[{"id":"sale2","lotId":"lot1","userId":"user123","date":"2024-05-01","price":150,"shares":7}]}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Total sold shares cannot exceed lot shares"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Another User's ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"mode": "replace"},
+				Body:                  `{"version":1,"esppLots":[{"id":"lot9","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}]}`,
+			},
+			expectedStatusCode: 409,
+			expectedBody:       `{"error":"Archive contains IDs that belong to another user"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Unsupported Version",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"version":2}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"version","message":"must be 1"}]}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Invalid Mode",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"mode": "overwrite"},
+				Body:                  restoreArchiveBody,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: mode"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Invalid Body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `[]`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Lookup Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           restoreArchiveBody,
+			},
+			failSales:          true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to restore archive"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Write Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           restoreArchiveBody,
+			},
+			failLotPut:         true,
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to restore archive"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           restoreArchiveBody,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           restoreArchiveBody,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           restoreArchiveBody,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+			expectedLots:       []string{"lot1", "lot2"},
+			expectedSales:      []string{"sale1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repos := newArchiveTestRepos()
+
+			var lotRepo db.EsppLotRepository = repos.lots
+			if tc.failLotPut {
+				lotRepo = &failingBatchPutEsppLotRepository{MemoryEsppLotRepository: repos.lots, err: errors.New("database error")}
+			}
+			var saleRepo db.EsppSaleRepository = repos.sales
+			if tc.failSales {
+				saleRepo = &failingEsppSaleRepository{err: errors.New("database error")}
+			}
+
+			handler := RestoreUserArchive(repos.users, lotRepo, saleRepo, repos.plans)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			lots, _ := repos.lots.GetEsppLotsByUserID("user123")
+			lotIDs := []string{}
+			saleIDs := []string{}
+			for _, lot := range lots {
+				lotIDs = append(lotIDs, lot.ID)
+				sales, _ := repos.sales.GetEsppSalesByLotID(lot.ID)
+				for _, sale := range sales {
+					saleIDs = append(saleIDs, sale.ID)
+				}
+			}
+			assert.ElementsMatch(t, tc.expectedLots, lotIDs)
+			assert.ElementsMatch(t, tc.expectedSales, saleIDs)
+
+			// Another user's data is never touched.
+			otherLot, _ := repos.lots.GetEsppLot("lot9")
+			assert.Equal(t, "user456", otherLot.UserID)
+			otherPlans, _ := repos.plans.GetEsppPlansByUserID("user456")
+			assert.Len(t, otherPlans, 1)
+
+			user, _ := repos.users.GetUser("user123")
+			if tc.expectedStatusCode != 200 {
+				assert.Equal(t, "2023-01-01T00:00:00Z", user.CreatedAt)
+				return
+			}
+
+			assert.Equal(t, "2022-05-01T00:00:00Z", user.CreatedAt)
+			assert.Equal(t, "2022-06-01T00:00:00Z", user.UpdatedAt)
+			assert.Equal(t, float64(150000), user.Settings.Finance.AnnualSalary)
+			assert.Equal(t, "hash", user.CalendarTokenHash)
+			assert.Equal(t, int64(3), user.Version)
+
+			lot1, _ := repos.lots.GetEsppLot("lot1")
+			assert.Equal(t, float64(12), lot1.Shares)
+			assert.Equal(t, "2023-07-01T00:00:00Z", lot1.CreatedAt)
+			assert.Equal(t, int64(5), lot1.Version)
+
+			lot3, _ := repos.lots.GetEsppLot("lot3")
+			assert.Equal(t, "2024-07-01T00:00:00Z", lot3.CreatedAt)
+			assert.Equal(t, "2024-07-01T00:00:00Z", lot3.UpdatedAt)
+			assert.Equal(t, int64(1), lot3.Version)
+		})
+	}
+}
+
+func TestRestoreUserArchiveRoundTrip(t *testing.T) {
+	source := newArchiveTestRepos()
+	exported, err := ExportUserArchive(source.users, source.lots, source.sales, source.plans)(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+	})
+	assert.NoError(t, err)
+
+	target := archiveTestRepos{
+		users: db.NewMemoryUserRepository(),
+		lots:  db.NewMemoryEsppLotRepository(),
+		sales: db.NewMemoryEsppSaleRepository(),
+		plans: db.NewMemoryEsppPlanRepository(),
+	}
+	response, err := RestoreUserArchive(target.users, target.lots, target.sales, target.plans)(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters:        map[string]string{"userId": "user123"},
+		QueryStringParameters: map[string]string{"mode": "replace"},
+		Body:                  exported.Body,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `{"mode":"replace","user":true,"restored":{"lots":2,"sales":1,"plans":1},"removed":{"lots":0,"sales":0,"plans":0}}`, response.Body)
+
+	reexported, err := ExportUserArchive(target.users, target.lots, target.sales, target.plans)(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+	})
+	assert.NoError(t, err)
+
+	var before, after models.UserArchive
+	assert.NoError(t, json.Unmarshal([]byte(exported.Body), &before))
+	assert.NoError(t, json.Unmarshal([]byte(reexported.Body), &after))
+	after.ExportedAt = before.ExportedAt
+	assert.Equal(t, before, after)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/ical"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+const calendarName = "fife ESPP milestones"
+
+// GetUserCalendar serves an iCalendar feed with the dates each lot with unsold
+// shares becomes long-term and qualifying. Calendar apps cannot sign in, so
+// the feed is unlocked by the user's calendar token in the query string
+// instead of a bearer token.
+func GetUserCalendar(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		// Unknown users and wrong tokens look the same so the feed does not
+		// reveal which user IDs exist.
+		if user == nil || !auth.CalendarTokenMatches(user.CalendarTokenHash, request.QueryStringParameters[constants.QueryToken]) {
+			return utils.UnauthorizedError()
+		}
+
+		lots, err := lotRepo.GetEsppLotsByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		calendar := ical.Calendar{Name: calendarName, Events: []ical.Event{}}
+		for _, lot := range lots {
+			sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+			}
+
+			unsold := lot.Shares - models.TotalSoldShares(sales)
+			if unsold <= 0 {
+				continue
+			}
+
+			events, err := lotMilestoneEvents(lot, unsold)
+			if err != nil {
+				slog.Error("Failed to build calendar", slog.String("lotId", lot.ID), slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to build calendar"})
+			}
+			calendar.Events = append(calendar.Events, events...)
+		}
+
+		sort.SliceStable(calendar.Events, func(i, j int) bool {
+			if !calendar.Events[i].Date.Equal(calendar.Events[j].Date) {
+				return calendar.Events[i].Date.Before(calendar.Events[j].Date)
+			}
+			return calendar.Events[i].UID < calendar.Events[j].UID
+		})
+
+		var body bytes.Buffer
+		if err := ical.Write(&body, calendar, time.Now()); err != nil {
+			slog.Error("Failed to write calendar", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to build calendar"})
+		}
+
+		return utils.FeedResponse("text/calendar; charset=utf-8", body.Bytes())
+	}
+}
+
+// lotMilestoneEvents returns an event for each of the lot's milestones.
+func lotMilestoneEvents(lot *models.EsppLot, unsold float64) ([]ical.Event, error) {
+	milestones, err := espp.LotMilestones(lot)
+	if err != nil {
+		return nil, err
+	}
+
+	name := lot.DisplayName()
+	description := lotMilestoneDescription(lot, unsold, milestones)
+
+	events := []ical.Event{}
+	for _, milestone := range milestones {
+		event := ical.Event{Date: milestone.Date}
+		switch milestone.Kind {
+		case espp.MilestoneLongTerm:
+			event.UID = lot.ID + "-long-term@fife"
+			event.Summary = fmt.Sprintf("%s lot from %s is long-term", name, lot.PurchaseDate)
+			event.Description = description + "\n\nGains on a sale from today are long-term, but the sale is still a disqualifying disposition."
+		case espp.MilestoneQualifying:
+			event.UID = lot.ID + "-qualifying@fife"
+			event.Summary = fmt.Sprintf("%s lot from %s is qualifying", name, lot.PurchaseDate)
+			event.Description = description + "\n\nA sale from today is a qualifying disposition."
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// lotMilestoneDescription describes the lot and lists all of its milestones.
+// When only the qualifying milestone is given, the lot becomes long-term on the
+// same day.
+func lotMilestoneDescription(lot *models.EsppLot, unsold float64, milestones []espp.Milestone) string {
+	longTermDate := milestones[0].Date
+	qualifyingDate := milestones[len(milestones)-1].Date
+
+	return fmt.Sprintf(
+		"%s unsold shares of %s bought on %s at $%.2f, granted on %s.\nLong-term: %s\nQualifying: %s",
+		strconv.FormatFloat(unsold, 'f', -1, 64),
+		lot.DisplayName(),
+		lot.PurchaseDate,
+		lot.PurchasePrice,
+		lot.GrantDate,
+		espp.FormatDate(longTermDate),
+		espp.FormatDate(qualifyingDate),
+	)
+}
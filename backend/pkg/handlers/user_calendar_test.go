@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserCalendar(t *testing.T) {
+	// Two years after the grant is later than one year after the purchase.
+	nkeLot := models.EsppLot{ID: "nke", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 10, Ticker: "nke"}
+	// Both milestones fall on the same day.
+	acmeLot := models.EsppLot{ID: "acme", UserID: "user123", GrantDate: "2022-06-30", PurchaseDate: "2023-06-30", OfferStartPrice: 50, OfferEndPrice: 60, PurchasePrice: 42.5, Shares: 5, Employer: "Acme, Inc."}
+	soldLot := models.EsppLot{ID: "sold", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 4}
+	partialSale := models.EsppSale{ID: "sale1", LotID: "nke", UserID: "user123", Date: "2024-01-15", Price: 150, Shares: 2.5}
+	fullSale := models.EsppSale{ID: "sale2", LotID: "sold", UserID: "user123", Date: "2024-01-15", Price: 150, Shares: 4}
+
+	testCases := []struct {
+		name               string
+		request            events.APIGatewayProxyRequest
+		userError          error
+		lotError           error
+		saleError          error
+		expectedStatusCode int
+		expectedBody       string
+		expectedLines      []string
+		unexpectedLines    []string
+	}{
+		{
+			name: "Success",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			expectedStatusCode: 200,
+			expectedLines: []string{
+				"BEGIN:VCALENDAR",
+				"X-WR-CALNAME:fife ESPP milestones",
+				"UID:acme-qualifying@fife",
+				"DTSTART;VALUE=DATE:20240630",
+				`SUMMARY:Acme\, Inc. lot from 2023-06-30 is qualifying`,
+				"UID:nke-long-term@fife",
+				"DTSTART;VALUE=DATE:20240630",
+				"SUMMARY:NKE lot from 2023-06-30 is long-term",
+				"UID:nke-qualifying@fife",
+				"DTSTART;VALUE=DATE:20250101",
+				"SUMMARY:NKE lot from 2023-06-30 is qualifying",
+				"END:VCALENDAR",
+			},
+			unexpectedLines: []string{
+				"UID:acme-long-term@fife",
+				"UID:sold-qualifying@fife",
+			},
+		},
+		{
+			name: "Wrong Token",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"token": "guess"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name: "Missing Token",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name: "Unknown User",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user456"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name: "User Database Error",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+		{
+			name: "Lot Database Error",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name: "Sale Database Error",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			saleError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sales"}`,
+		},
+		{
+			name: "Missing User ID",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			memoryUserRepo.PutUser(models.User{UserID: "user123", CalendarTokenHash: auth.HashCalendarToken("secret")})
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range []models.EsppLot{nkeLot, acmeLot, soldLot} {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			memorySaleRepo.PutEsppSale(partialSale)
+			memorySaleRepo.PutEsppSale(fullSale)
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.saleError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.saleError}
+			}
+
+			// The feed is read by calendar apps, so there is no caller.
+			handler := GetUserCalendar(userRepo, lotRepo, saleRepo)
+			response, err := handler(context.Background(), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			assert.Equal(t, "text/calendar; charset=utf-8", response.Headers["Content-Type"])
+			assert.Equal(t, "private", response.Headers["Cache-Control"])
+
+			lines := strings.Split(response.Body, "\r\n")
+			lastIndex := -1
+			for _, expected := range tc.expectedLines {
+				index := indexOf(lines, expected, lastIndex+1)
+				assert.Greater(t, index, lastIndex, "expected %q in order", expected)
+				lastIndex = index
+			}
+			for _, unexpected := range tc.unexpectedLines {
+				assert.NotContains(t, lines, unexpected)
+			}
+			assert.Contains(t, response.Body, `7.5 unsold shares of NKE bought on 2023-06-30 at $85.00`)
+		})
+	}
+}
+
+func indexOf(lines []string, line string, from int) int {
+	for i := from; i < len(lines); i++ {
+		if lines[i] == line {
+			return i
+		}
+	}
+
+	return -1
+}
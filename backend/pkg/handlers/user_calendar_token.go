@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// CreateUserCalendarToken issues a new token for the user's calendar feed and
+// revokes the previous one. Only a hash is stored, so the token is returned
+// this one time.
+func CreateUserCalendarToken(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		token, err := auth.NewCalendarToken()
+		if err != nil {
+			slog.Error("Failed to generate calendar token", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to create calendar token"})
+		}
+
+		found, err := userRepo.SetCalendarTokenHash(userID, auth.HashCalendarToken(token))
+		if err != nil {
+			slog.Error("Failed to store calendar token", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to create calendar token"})
+		}
+
+		if !found {
+			return utils.APIResponse(404, map[string]string{"error": "User not found"})
+		}
+
+		return utils.APIResponse(201, map[string]string{"token": token})
+	}
+}
+
+// RevokeUserCalendarToken stops the user's calendar feed from being served
+// until a new token is created.
+func RevokeUserCalendarToken(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		found, err := userRepo.SetCalendarTokenHash(userID, "")
+		if err != nil {
+			slog.Error("Failed to revoke calendar token", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to revoke calendar token"})
+		}
+
+		if !found {
+			return utils.APIResponse(404, map[string]string{"error": "User not found"})
+		}
+
+		return utils.APIResponse(200, map[string]string{"message": "Calendar token revoked successfully"})
+	}
+}
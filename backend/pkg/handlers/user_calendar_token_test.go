@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUserCalendarToken(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		userError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 201,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to create calendar token"}`,
+		},
+		{
+			name:     "Deleted User",
+			callerID: "user789",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user789"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"User not found"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			memoryUserRepo.PutUser(models.User{UserID: "user123", CalendarTokenHash: auth.HashCalendarToken("old")})
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+
+			handler := CreateUserCalendarToken(userRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			var body map[string]string
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.NotEmpty(t, body["token"])
+
+			user, _ := memoryUserRepo.GetUser("user123")
+			assert.True(t, auth.CalendarTokenMatches(user.CalendarTokenHash, body["token"]))
+			assert.False(t, auth.CalendarTokenMatches(user.CalendarTokenHash, "old"))
+		})
+	}
+}
+
+func TestRevokeUserCalendarToken(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		userError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"message":"Calendar token revoked successfully"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to revoke calendar token"}`,
+		},
+		{
+			name:     "Deleted User",
+			callerID: "user789",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user789"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"User not found"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			memoryUserRepo.PutUser(models.User{UserID: "user123", CalendarTokenHash: auth.HashCalendarToken("old")})
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+
+			handler := RevokeUserCalendarToken(userRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			user, _ := memoryUserRepo.GetUser("user123")
+			assert.Equal(t, tc.expectedStatusCode != 200, auth.CalendarTokenMatches(user.CalendarTokenHash, "old"))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// postConfirmationSignUp is the trigger source for a newly signed up user.
+// The same trigger also runs after a forgotten password is reset.
+const postConfirmationSignUp = "PostConfirmation_ConfirmSignUp"
+
+// CreateUserOnSignUp creates the user with default settings once Cognito
+// confirms their sign-up. The user ID is the sub attribute, which is the sub
+// claim of their tokens. Failures are logged rather than returned because an
+// error here would be shown to the user as a failed sign-up, and saving
+// settings creates the user anyway.
+func CreateUserOnSignUp(userRepo db.UserRepository) PostConfirmationHandler {
+	return func(ctx context.Context, event events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error) {
+		if event.TriggerSource != postConfirmationSignUp {
+			return event, nil
+		}
+
+		userID := event.Request.UserAttributes["sub"]
+		if userID == "" {
+			slog.Error("Post confirmation event has no sub attribute", slog.String("userName", event.UserName))
+			return event, nil
+		}
+
+		user, err := userRepo.CreateUser(*models.NewUser(userID, event.Request.UserAttributes["email"]))
+		if err != nil {
+			slog.Error("Failed to create user", slog.String("userId", userID), slog.Any("error", err))
+			return event, nil
+		}
+
+		if user == nil {
+			slog.Info("User already exists", slog.String("userId", userID))
+		}
+
+		return event, nil
+	}
+}
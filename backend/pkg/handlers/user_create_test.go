@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func postConfirmationEvent(triggerSource string, attributes map[string]string) events.CognitoEventUserPoolsPostConfirmation {
+	event := events.CognitoEventUserPoolsPostConfirmation{}
+	event.TriggerSource = triggerSource
+	event.UserName = "jane"
+	event.Request.UserAttributes = attributes
+	return event
+}
+
+func TestCreateUserOnSignUp(t *testing.T) {
+	testCases := []struct {
+		name          string
+		event         events.CognitoEventUserPoolsPostConfirmation
+		existing      *models.User
+		userError     error
+		expectedUser  *models.User
+		expectCreated bool
+	}{
+		{
+			name:          "Sign Up",
+			event:         postConfirmationEvent("PostConfirmation_ConfirmSignUp", map[string]string{"sub": "user123", "email": "jane@example.com"}),
+			expectCreated: true,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance:       models.UserFinanceSettings{PaychecksPerYear: 26},
+					Notifications: &models.NotificationSettings{Email: "jane@example.com"},
+				},
+			},
+		},
+		{
+			name:          "Sign Up Without Email",
+			event:         postConfirmationEvent("PostConfirmation_ConfirmSignUp", map[string]string{"sub": "user123"}),
+			expectCreated: true,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{PaychecksPerYear: 26},
+				},
+			},
+		},
+		{
+			name:     "Existing User Is Kept",
+			event:    postConfirmationEvent("PostConfirmation_ConfirmSignUp", map[string]string{"sub": "user123", "email": "jane@example.com"}),
+			existing: &models.User{UserID: "user123", Settings: models.UserSettings{Finance: models.UserFinanceSettings{AnnualSalary: 120000, PaychecksPerYear: 24}}},
+			expectedUser: &models.User{
+				UserID:   "user123",
+				Settings: models.UserSettings{Finance: models.UserFinanceSettings{AnnualSalary: 120000, PaychecksPerYear: 24}},
+			},
+		},
+		{
+			name:  "Forgot Password",
+			event: postConfirmationEvent("PostConfirmation_ConfirmForgotPassword", map[string]string{"sub": "user123"}),
+		},
+		{
+			name:  "Missing Sub",
+			event: postConfirmationEvent("PostConfirmation_ConfirmSignUp", map[string]string{"email": "jane@example.com"}),
+		},
+		{
+			name:      "Database Error Does Not Block Sign Up",
+			event:     postConfirmationEvent("PostConfirmation_ConfirmSignUp", map[string]string{"sub": "user123"}),
+			userError: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			if tc.existing != nil {
+				memoryUserRepo.PutUser(*tc.existing)
+			}
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+
+			handler := CreateUserOnSignUp(userRepo)
+			response, err := handler(context.Background(), tc.event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.event, response)
+
+			user, err := memoryUserRepo.GetUser("user123")
+			assert.NoError(t, err)
+			if tc.expectedUser == nil {
+				assert.Nil(t, user)
+				return
+			}
+
+			assert.Equal(t, tc.expectedUser.Settings, user.Settings)
+			if tc.expectCreated {
+				assert.NotEmpty(t, user.CreatedAt)
+				assert.Equal(t, user.CreatedAt, user.UpdatedAt)
+			}
+		})
+	}
+}
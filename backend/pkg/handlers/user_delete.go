@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// esppDataCounts counts a user's ESPP plans, lots and sales.
+type esppDataCounts struct {
+	Lots  int `json:"lots"`
+	Sales int `json:"sales"`
+	Plans int `json:"plans"`
+}
+
+// userDeletionReport counts what deleting a user removed.
+type userDeletionReport struct {
+	UserID string `json:"userId"`
+	esppDataCounts
+}
+
+// DeleteUser deletes the user and everything they own. The Cognito account is
+// not touched.
+func DeleteUser(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		removed, err := deleteEsppData(userID, lotRepo, saleRepo, planRepo)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete user"})
+		}
+
+		if err := userRepo.DeleteUser(userID); err != nil {
+			slog.Error("Failed to delete user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to delete user"})
+		}
+
+		return utils.APIResponse(200, userDeletionReport{UserID: userID, esppDataCounts: removed})
+	}
+}
+
+// deleteEsppData deletes every ESPP sale, lot and plan the user owns. Children
+// are removed before their parents, so a failure part way never leaves sales
+// without a lot and retrying picks up where the last attempt stopped.
+func deleteEsppData(userID string, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) (esppDataCounts, error) {
+	var removed esppDataCounts
+
+	lots, err := lotRepo.GetEsppLotsByUserID(userID)
+	if err != nil {
+		slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+		return removed, err
+	}
+
+	saleIDs := []string{}
+	for _, lot := range lots {
+		sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+			return removed, err
+		}
+		for _, sale := range sales {
+			saleIDs = append(saleIDs, sale.ID)
+		}
+	}
+
+	if err := saleRepo.BatchDeleteEsppSales(saleIDs); err != nil {
+		slog.Error("Failed to delete ESPP sales", slog.Any("error", err))
+		return removed, err
+	}
+	removed.Sales = len(saleIDs)
+
+	removed.Lots, err = lotRepo.DeleteEsppLotsByUserID(userID)
+	if err != nil {
+		slog.Error("Failed to delete ESPP lots", slog.Any("error", err))
+		return removed, err
+	}
+
+	removed.Plans, err = planRepo.DeleteEsppPlansByUserID(userID)
+	if err != nil {
+		slog.Error("Failed to delete ESPP plans", slog.Any("error", err))
+		return removed, err
+	}
+
+	return removed, nil
+}
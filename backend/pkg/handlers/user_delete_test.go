@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingBatchDeleteEsppSaleRepository struct {
+	*db.MemoryEsppSaleRepository
+	err error
+}
+
+func (r *failingBatchDeleteEsppSaleRepository) BatchDeleteEsppSales(_ []string) error {
+	return r.err
+}
+
+func TestDeleteUser(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		lotError           error
+		saleError          error
+		planError          error
+		userError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"userId":"user123","lots":2,"sales":3,"plans":1}`,
+		},
+		{
+			name:     "Lot Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete user"}`,
+		},
+		{
+			name:     "Sale Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			saleError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete user"}`,
+		},
+		{
+			name:     "Plan Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			planError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete user"}`,
+		},
+		{
+			name:     "User Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to delete user"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			memoryUserRepo.PutUser(models.User{UserID: "user123"})
+			memoryUserRepo.PutUser(models.User{UserID: "user456"})
+
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			memoryLotRepo.PutEsppLot(models.EsppLot{ID: "lot1", UserID: "user123", Shares: 10})
+			memoryLotRepo.PutEsppLot(models.EsppLot{ID: "lot2", UserID: "user123", Shares: 10})
+			memoryLotRepo.PutEsppLot(models.EsppLot{ID: "lot3", UserID: "user456", Shares: 10})
+
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale1", LotID: "lot1", UserID: "user123", Shares: 2})
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale2", LotID: "lot1", UserID: "user123", Shares: 2})
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale3", LotID: "lot2", UserID: "user123", Shares: 2})
+			memorySaleRepo.PutEsppSale(models.EsppSale{ID: "sale4", LotID: "lot3", UserID: "user456", Shares: 2})
+
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan1", UserID: "user123"})
+			memoryPlanRepo.PutEsppPlan(models.EsppPlan{ID: "plan2", UserID: "user456"})
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.saleError != nil {
+				saleRepo = &failingBatchDeleteEsppSaleRepository{MemoryEsppSaleRepository: memorySaleRepo, err: tc.saleError}
+			}
+
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+
+			handler := DeleteUser(userRepo, lotRepo, saleRepo, planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+
+			// Another user's data is never touched.
+			otherUser, _ := memoryUserRepo.GetUser("user456")
+			assert.NotNil(t, otherUser)
+			otherLots, _ := memoryLotRepo.GetEsppLotsByUserID("user456")
+			assert.Len(t, otherLots, 1)
+			otherSales, _ := memorySaleRepo.GetEsppSalesByLotID("lot3")
+			assert.Len(t, otherSales, 1)
+			otherPlans, _ := memoryPlanRepo.GetEsppPlansByUserID("user456")
+			assert.Len(t, otherPlans, 1)
+
+			user, _ := memoryUserRepo.GetUser("user123")
+			lots, _ := memoryLotRepo.GetEsppLotsByUserID("user123")
+			if tc.expectedStatusCode == 200 {
+				assert.Nil(t, user)
+				assert.Empty(t, lots)
+				sales, _ := memorySaleRepo.GetEsppSalesByLotID("lot1")
+				assert.Empty(t, sales)
+				plans, _ := memoryPlanRepo.GetEsppPlansByUserID("user123")
+				assert.Empty(t, plans)
+			} else {
+				assert.NotNil(t, user)
+			}
+		})
+	}
+}
+
+func TestDeleteUserWithoutData(t *testing.T) {
+	handler := DeleteUser(db.NewMemoryUserRepository(), db.NewMemoryEsppLotRepository(), db.NewMemoryEsppSaleRepository(), db.NewMemoryEsppPlanRepository())
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `{"userId":"user123","lots":0,"sales":0,"plans":0}`, response.Body)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/lotfile"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// ExportUserEsppLots returns every lot the user owns, ordered by purchase date,
+// as a CSV in the import layout or as a JSON array.
+func ExportUserEsppLots(lotRepo db.EsppLotRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		format := request.QueryStringParameters[constants.QueryFormat]
+		if format == "" {
+			format = constants.FormatCSV
+		}
+		if format != constants.FormatCSV && format != constants.FormatJSON {
+			return utils.InvalidQueryParameterError(constants.QueryFormat)
+		}
+
+		lots, err := listAllEsppLots(lotRepo, userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		if format == constants.FormatJSON {
+			body, err := json.Marshal(lots)
+			if err != nil {
+				return utils.APIResponse(500, map[string]string{"error": "Failed to export ESPP lots"})
+			}
+			return utils.AttachmentResponse("application/json", "espp-lots.json", body)
+		}
+
+		var body bytes.Buffer
+		if err := lotfile.WriteCSV(&body, lots); err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to export ESPP lots"})
+		}
+		return utils.AttachmentResponse("text/csv; charset=utf-8", "espp-lots.csv", body.Bytes())
+	}
+}
+
+// listAllEsppLots follows the listing cursor until every page has been read.
+func listAllEsppLots(lotRepo db.EsppLotRepository, userID string) ([]*models.EsppLot, error) {
+	options := models.EsppLotListOptions{UserID: userID, Limit: constants.MaxPageLimit}
+
+	lots := []*models.EsppLot{}
+	for {
+		page, err := lotRepo.ListEsppLotsByUserID(options)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, page.Items...)
+
+		if page.NextCursor == "" {
+			return lots, nil
+		}
+		options.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportUserEsppLots(t *testing.T) {
+	mockLots := []models.EsppLot{
+		{
+			ID:              "lot456",
+			UserID:          "user123",
+			GrantDate:       "2023-07-01",
+			PurchaseDate:    "2023-12-31",
+			OfferStartPrice: 120.0,
+			OfferEndPrice:   140.0,
+			PurchasePrice:   95.0,
+			Shares:          15.0,
+		},
+		{
+			ID:              "lot123",
+			UserID:          "user123",
+			GrantDate:       "2023-01-01",
+			PurchaseDate:    "2023-06-30",
+			OfferStartPrice: 100.0,
+			OfferEndPrice:   120.0,
+			PurchasePrice:   85.0,
+			Shares:          10.0,
+		},
+		{
+			ID:           "other",
+			UserID:       "user456",
+			GrantDate:    "2023-01-01",
+			PurchaseDate: "2023-06-30",
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		callerID            string
+		request             events.APIGatewayProxyRequest
+		mockError           error
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:     "csv by default",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares,ticker,employer,planId\n" +
+				"2023-01-01,2023-06-30,100,120,85,10,,,\n" +
+				"2023-07-01,2023-12-31,120,140,95,15,,,\n",
+		},
+		{
+			name:     "json",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"format": "json"},
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "application/json",
+			expectedBody:        `[{"id":"lot123","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10,"version":0,"createdAt":"","updatedAt":""},{"id":"lot456","userId":"user123","grantDate":"2023-07-01","purchaseDate":"2023-12-31","offerStartPrice":120,"offerEndPrice":140,"purchasePrice":95,"shares":15,"version":0,"createdAt":"","updatedAt":""}]`,
+		},
+		{
+			name:     "invalid format",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"format": "xml"},
+			},
+			expectedStatusCode:  400,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"Invalid query parameter: format"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockError:           errors.New("database error"),
+			expectedStatusCode:  500,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name:     "other user",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode:  403,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode:  401,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode:  400,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range mockLots {
+				memoryRepo.PutEsppLot(lot)
+			}
+
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.mockError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.mockError}
+			}
+
+			handler := ExportUserEsppLots(lotRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedContentType, response.Headers["Content-Type"])
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
+
+func TestExportUserEsppLotsAllPages(t *testing.T) {
+	memoryRepo := db.NewMemoryEsppLotRepository()
+	for i := 0; i < 250; i++ {
+		memoryRepo.PutEsppLot(models.EsppLot{
+			ID:           fmt.Sprintf("lot%03d", i),
+			UserID:       "user123",
+			GrantDate:    "2023-01-01",
+			PurchaseDate: "2023-06-30",
+		})
+	}
+
+	handler := ExportUserEsppLots(memoryRepo)
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters:        map[string]string{"userId": "user123"},
+		QueryStringParameters: map[string]string{"format": "json"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `attachment; filename="espp-lots.json"`, response.Headers["Content-Disposition"])
+
+	var lots []*models.EsppLot
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &lots))
+	assert.Len(t, lots, 250)
+	assert.Equal(t, "lot000", lots[0].ID)
+	assert.Equal(t, "lot249", lots[249].ID)
+
+	response, err = handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 251, strings.Count(response.Body, "\n"))
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/lotfile"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+const (
+	importStatusCreated = "created"
+	importStatusValid   = "valid"
+	importStatusError   = "error"
+)
+
+type importRowResult struct {
+	Row    int                     `json:"row"`
+	Status string                  `json:"status"`
+	Lot    *models.EsppLot         `json:"lot,omitempty"`
+	Errors models.ValidationErrors `json:"errors,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+type importReport struct {
+	DryRun    bool              `json:"dryRun"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Rows      []importRowResult `json:"rows"`
+}
+
+// ImportUserEsppLots accepts a CSV file or a JSON array of lots, validates each
+// row and writes the valid ones. Invalid rows are reported rather than failing
+// the whole import. A row's planId must name one of the caller's plans, as it
+// must when a lot is created.
+func ImportUserEsppLots(lotRepo db.EsppLotRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		dryRun := false
+		if value, ok := request.QueryStringParameters[constants.QueryDryRun]; ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return utils.InvalidQueryParameterError(constants.QueryDryRun)
+			}
+			dryRun = parsed
+		}
+
+		body := []byte(request.Body)
+		if request.IsBase64Encoded {
+			decoded, err := base64.StdEncoding.DecodeString(request.Body)
+			if err != nil {
+				return utils.InvalidRequestBodyError()
+			}
+			body = decoded
+		}
+
+		rows, err := parseImportBody(request.Headers, body)
+		if err != nil {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Invalid import file: %s", err)})
+		}
+
+		if len(rows) > constants.MaxImportRows {
+			return utils.APIResponse(400, map[string]string{"error": fmt.Sprintf("Import is limited to %d rows", constants.MaxImportRows)})
+		}
+
+		report := importReport{
+			DryRun: dryRun,
+			Total:  len(rows),
+			Rows:   make([]importRowResult, len(rows)),
+		}
+
+		validInputs := []models.EsppLotInput{}
+		validRows := []int{}
+		for i, row := range rows {
+			report.Rows[i] = importRowResult{Row: i + 1}
+
+			if len(row.Errors) > 0 {
+				report.Rows[i].Status = importStatusError
+				report.Rows[i].Errors = row.Errors
+				continue
+			}
+
+			row.Input.UserID = userID
+
+			planErrs, err := validateLotPlan(planRepo, *models.NewEsppLot(row.Input), callerID)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP plan", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plan"})
+			}
+			if len(planErrs) > 0 {
+				report.Rows[i].Status = importStatusError
+				report.Rows[i].Errors = planErrs
+				continue
+			}
+
+			validInputs = append(validInputs, row.Input)
+			validRows = append(validRows, i)
+			report.Rows[i].Status = importStatusValid
+		}
+
+		if !dryRun && len(validInputs) > 0 {
+			created, err := lotRepo.BatchCreateEsppLots(validInputs)
+			if err != nil {
+				slog.Error("Failed to import ESPP lots", slog.Any("error", err))
+			}
+
+			for j, i := range validRows {
+				if j < len(created) && created[j] != nil {
+					report.Rows[i].Status = importStatusCreated
+					report.Rows[i].Lot = created[j]
+					continue
+				}
+
+				report.Rows[i].Status = importStatusError
+				report.Rows[i].Error = "Failed to save ESPP lot"
+			}
+		}
+
+		for _, row := range report.Rows {
+			if row.Status == importStatusError {
+				report.Failed++
+			} else {
+				report.Succeeded++
+			}
+		}
+
+		return utils.APIResponse(200, report)
+	}
+}
+
+// parseImportBody picks the parser from the Content-Type header, falling back
+// to sniffing for a JSON array when the header is missing or generic.
+func parseImportBody(headers map[string]string, body []byte) ([]lotfile.Row, error) {
+	contentType := ""
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = strings.ToLower(value)
+		}
+	}
+
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return lotfile.ParseCSV(body)
+	case strings.Contains(contentType, "json"):
+		return lotfile.ParseJSON(body)
+	case bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")):
+		return lotfile.ParseJSON(body)
+	default:
+		return lotfile.ParseCSV(body)
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// partialBatchEsppLotRepository writes only the first lot of a batch and then
+// fails, like a BatchWriteItem that runs out of retries.
+type partialBatchEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+	err error
+}
+
+func (r *partialBatchEsppLotRepository) BatchCreateEsppLots(lotInputs []models.EsppLotInput) ([]*models.EsppLot, error) {
+	created := make([]*models.EsppLot, len(lotInputs))
+	created[0], _ = r.CreateEsppLot(lotInputs[0])
+
+	return created, r.err
+}
+
+const importCSV = "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares\n" +
+	"2023-01-01,2023-06-30,100,120,85,10\n" +
+	"2023-07-01,2023-06-30,100,120,85,10\n" +
+	"2023-07-01,2023-12-31,120,140,95,15\n"
+
+func TestImportUserEsppLots(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		batchError         error
+		expectedStatusCode int
+		expectedBody       string
+		expectedStatuses   []string
+		expectedStored     int
+	}{
+		{
+			name:     "csv import",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Headers:        map[string]string{"content-type": "text/csv"},
+				Body:           importCSV,
+			},
+			expectedStatusCode: 200,
+			expectedStatuses:   []string{"created", "error", "created"},
+			expectedStored:     2,
+		},
+		{
+			name:     "base64 csv import",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:  map[string]string{"userId": "user123"},
+				Headers:         map[string]string{"Content-Type": "text/csv"},
+				Body:            base64.StdEncoding.EncodeToString([]byte(importCSV)),
+				IsBase64Encoded: true,
+			},
+			expectedStatusCode: 200,
+			expectedStatuses:   []string{"created", "error", "created"},
+			expectedStored:     2,
+		},
+		{
+			name:     "json import without content type",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `[{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":"100","offerEndPrice":"120","purchasePrice":"85","shares":"10"}]`,
+			},
+			expectedStatusCode: 200,
+			expectedStatuses:   []string{"created"},
+			expectedStored:     1,
+		},
+		{
+			name:     "dry run",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"dryRun": "true"},
+				Headers:               map[string]string{"Content-Type": "text/csv"},
+				Body:                  importCSV,
+			},
+			expectedStatusCode: 200,
+			expectedStatuses:   []string{"valid", "error", "valid"},
+			expectedStored:     0,
+		},
+		{
+			name:     "write failure",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Headers:        map[string]string{"Content-Type": "text/csv"},
+				Body:           importCSV,
+			},
+			batchError:         errors.New("database error"),
+			expectedStatusCode: 200,
+			expectedStatuses:   []string{"created", "error", "error"},
+			expectedStored:     1,
+		},
+		{
+			name:     "invalid dry run",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"dryRun": "maybe"},
+				Body:                  importCSV,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: dryRun"}`,
+		},
+		{
+			name:     "missing column",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Headers:        map[string]string{"Content-Type": "text/csv"},
+				Body:           "grantDate,purchaseDate\n2023-01-01,2023-06-30\n",
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid import file: missing column: offerStartPrice"}`,
+		},
+		{
+			name:     "invalid json",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Headers:        map[string]string{"Content-Type": "application/json"},
+				Body:           `{"grantDate":"2023-01-01"}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid import file: expected a JSON array of lots"}`,
+		},
+		{
+			name:     "other user",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           importCSV,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           importCSV,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           importCSV,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppLotRepository()
+
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.batchError != nil {
+				lotRepo = &partialBatchEsppLotRepository{MemoryEsppLotRepository: memoryRepo, err: tc.batchError}
+			}
+
+			handler := ImportUserEsppLots(lotRepo, db.NewMemoryEsppPlanRepository())
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			var report importReport
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &report))
+
+			statuses := []string{}
+			for i, row := range report.Rows {
+				assert.Equal(t, i+1, row.Row)
+				statuses = append(statuses, row.Status)
+				if row.Status == importStatusCreated {
+					assert.Equal(t, "user123", row.Lot.UserID)
+				}
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			assert.Equal(t, len(tc.expectedStatuses), report.Total)
+			assert.Equal(t, report.Total, report.Succeeded+report.Failed)
+
+			stored, err := memoryRepo.GetEsppLotsByUserID("user123")
+			assert.NoError(t, err)
+			assert.Len(t, stored, tc.expectedStored)
+		})
+	}
+}
+
+func TestImportUserEsppLotsRowErrors(t *testing.T) {
+	handler := ImportUserEsppLots(db.NewMemoryEsppLotRepository(), db.NewMemoryEsppPlanRepository())
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+		Headers:        map[string]string{"Content-Type": "text/csv"},
+		Body:           importCSV,
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, response.Body, `{"row":2,"status":"error","errors":[{"field":"purchaseDate","message":"must not be before grantDate"}]}`)
+	assert.Contains(t, response.Body, `"dryRun":false,"total":3,"succeeded":2,"failed":1`)
+}
+
+func TestImportUserEsppLotsPlans(t *testing.T) {
+	planRepo := db.NewMemoryEsppPlanRepository()
+	ownPlan, err := planRepo.CreateEsppPlan(models.EsppPlanInput{UserID: "user123", Name: "Nike ESPP", DiscountPercent: 10, Lookback: true, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6})
+	assert.NoError(t, err)
+	otherPlan, err := planRepo.CreateEsppPlan(models.EsppPlanInput{UserID: "user456", Name: "Other ESPP", DiscountPercent: 15, Lookback: true, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6})
+	assert.NoError(t, err)
+
+	lotRepo := db.NewMemoryEsppLotRepository()
+	handler := ImportUserEsppLots(lotRepo, planRepo)
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"userId": "user123"},
+		Headers:        map[string]string{"Content-Type": "text/csv"},
+		Body: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares,planId\n" +
+			"2023-01-01,2023-06-30,100,120,90,10," + ownPlan.ID + "\n" +
+			"2023-01-01,2023-06-30,100,120,85,10," + otherPlan.ID + "\n",
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, response.Body, `{"row":2,"status":"error","errors":[{"field":"planId","message":"must reference one of your ESPP plans"}]}`)
+	assert.Contains(t, response.Body, `"dryRun":false,"total":2,"succeeded":1,"failed":1`)
+
+	stored, err := lotRepo.GetEsppLotsByUserID("user123")
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, ownPlan.ID, stored[0].PlanID)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// GetUserEsppLotLimit reports how much of the section 423 limit the user's
+// lots used each year and the headroom of the next purchase. The limit covers
+// every plan of one employer, so the employer query parameter narrows the lots.
+// The next purchase comes from the grantDate, purchaseDate and offerStartPrice
+// query parameters, or is projected from the latest lot and its plan.
+func GetUserEsppLotLimit(lotRepo db.EsppLotRepository, planRepo db.EsppPlanRepository, historyRepo db.PriceHistoryRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		next, invalidParam := parsePlannedPurchase(request.QueryStringParameters)
+		if invalidParam != "" {
+			return utils.InvalidQueryParameterError(invalidParam)
+		}
+
+		allLots, err := lotRepo.GetEsppLotsByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		plans, err := planRepo.GetEsppPlansByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP plans", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plans"})
+		}
+
+		employer := strings.TrimSpace(request.QueryStringParameters[constants.QueryEmployer])
+		lots := allLots
+		if employer != "" {
+			lots = []*models.EsppLot{}
+			for _, lot := range allLots {
+				if strings.EqualFold(lot.Employer, employer) {
+					lots = append(lots, lot)
+				}
+			}
+		}
+
+		latest := latestEsppLot(lots)
+		if next == nil && latest != nil {
+			next, err = espp.ProjectNextPurchase(latest, espp.PlanFor(latest, plans))
+			if err != nil {
+				slog.Error("Failed to project next ESPP purchase", slog.String("lotId", latest.ID), slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP limit"})
+			}
+
+			// A new offering that already started was granted at the recorded
+			// close on its first day.
+			if next.OfferStartPrice == 0 && latest.Ticker != "" && next.GrantDate <= time.Now().UTC().Format(models.DateLayout) {
+				closing, err := historyRepo.GetClosingPriceOnOrBefore(latest.Ticker, next.GrantDate)
+				if err != nil {
+					slog.Error("Failed to retrieve price history", slog.Any("error", err))
+					return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve price history"})
+				}
+				if closing != nil {
+					next.OfferStartPrice = closing.Close
+				}
+			}
+		} else if next != nil {
+			lot := latest
+			if lot == nil {
+				lot = &models.EsppLot{Employer: employer}
+			}
+			next.ShareCap = espp.PlanFor(lot, plans).MaxSharesPerPurchase
+		}
+
+		limit, err := espp.CalculateSection423Limit(lots, next)
+		if err != nil {
+			slog.Error("Failed to calculate ESPP limit", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP limit"})
+		}
+
+		return utils.APIResponse(200, limit)
+	}
+}
+
+// parsePlannedPurchase reads a planned purchase from the query. grantDate and
+// purchaseDate go together and offerStartPrice is optional. When a parameter is
+// invalid or missing, its name is returned.
+func parsePlannedPurchase(query map[string]string) (*espp.PlannedPurchase, string) {
+	grantDate, hasGrantDate := query[constants.QueryGrantDate]
+	purchaseDate, hasPurchaseDate := query[constants.QueryPurchaseDate]
+	priceValue, hasPrice := query[constants.QueryOfferStartPrice]
+
+	if !hasGrantDate && !hasPurchaseDate && !hasPrice {
+		return nil, ""
+	}
+
+	if _, err := time.Parse(models.DateLayout, grantDate); err != nil {
+		return nil, constants.QueryGrantDate
+	}
+
+	if _, err := time.Parse(models.DateLayout, purchaseDate); err != nil || purchaseDate < grantDate {
+		return nil, constants.QueryPurchaseDate
+	}
+
+	next := &espp.PlannedPurchase{GrantDate: grantDate, PurchaseDate: purchaseDate}
+	if hasPrice {
+		price, err := strconv.ParseFloat(priceValue, 64)
+		if err != nil || price <= 0 {
+			return nil, constants.QueryOfferStartPrice
+		}
+		next.OfferStartPrice = price
+	}
+
+	return next, ""
+}
+
+// latestEsppLot returns the lot purchased last, or nil when there are none.
+func latestEsppLot(lots []*models.EsppLot) *models.EsppLot {
+	var latest *models.EsppLot
+	for _, lot := range lots {
+		if latest == nil || lot.PurchaseDate > latest.PurchaseDate {
+			latest = lot
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserEsppLotLimit(t *testing.T) {
+	nkeLot := models.EsppLot{ID: "nke", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 100, Ticker: "NKE", Employer: "Nike"}
+	acmeLot := models.EsppLot{ID: "acme", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 50, OfferEndPrice: 60, PurchasePrice: 42.5, Shares: 100, Employer: "Acme"}
+	acmePlan := models.EsppPlan{ID: "plan123", UserID: "user123", Name: "Acme ESPP", Employer: "Acme", DiscountPercent: 15, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6, MaxSharesPerPurchase: 50}
+
+	testCases := []struct {
+		name                 string
+		callerID             string
+		request              events.APIGatewayProxyRequest
+		mockLots             []models.EsppLot
+		lotError             error
+		planError            error
+		historyError         error
+		expectedStatusCode   int
+		expectedBody         string
+		expectedYears        []espp.LimitYear
+		expectedNextPurchase *espp.PurchaseHeadroom
+	}{
+		{
+			name:     "projected from the latest lot",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"employer": "nike"},
+			},
+			mockLots:           []models.EsppLot{nkeLot, acmeLot},
+			expectedStatusCode: 200,
+			expectedYears: []espp.LimitYear{
+				{Year: 2023, Limit: 25000, Accrued: 10000, Remaining: 15000},
+			},
+			expectedNextPurchase: &espp.PurchaseHeadroom{
+				PlannedPurchase: espp.PlannedPurchase{GrantDate: "2023-07-01", PurchaseDate: "2023-12-30", OfferStartPrice: 120},
+				Headroom:        15000,
+				MaxShares:       floatPtr(125),
+			},
+		},
+		{
+			name:     "planned purchase with plan cap",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"employer": "Acme", "grantDate": "2024-01-01", "purchaseDate": "2024-06-28", "offerStartPrice": "40"},
+			},
+			mockLots:           []models.EsppLot{nkeLot, acmeLot},
+			expectedStatusCode: 200,
+			expectedYears: []espp.LimitYear{
+				{Year: 2023, Limit: 25000, Accrued: 5000, Remaining: 20000},
+				{Year: 2024, Limit: 25000, Accrued: 0, Remaining: 25000},
+			},
+			expectedNextPurchase: &espp.PurchaseHeadroom{
+				PlannedPurchase: espp.PlannedPurchase{GrantDate: "2024-01-01", PurchaseDate: "2024-06-28", OfferStartPrice: 40, ShareCap: 50},
+				Headroom:        25000,
+				MaxShares:       floatPtr(50),
+			},
+		},
+		{
+			name:     "no lots",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `{"years":[]}`,
+		},
+		{
+			name:     "invalid grant date",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"grantDate": "01/01/2024", "purchaseDate": "2024-06-28"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: grantDate"}`,
+		},
+		{
+			name:     "missing purchase date",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"grantDate": "2024-01-01"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: purchaseDate"}`,
+		},
+		{
+			name:     "invalid offer start price",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"grantDate": "2024-01-01", "purchaseDate": "2024-06-28", "offerStartPrice": "-1"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: offerStartPrice"}`,
+		},
+		{
+			name:     "lot database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name:     "plan database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			planError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plans"}`,
+		},
+		{
+			name:     "price history error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockLots:           []models.EsppLot{nkeLot},
+			historyError:       errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve price history"}`,
+		},
+		{
+			name:     "other user's limit",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range tc.mockLots {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			memoryPlanRepo.PutEsppPlan(acmePlan)
+			memoryHistoryRepo := db.NewMemoryPriceHistoryRepository()
+			assert.NoError(t, memoryHistoryRepo.BatchPutClosingPrices([]models.ClosingPrice{
+				{Ticker: "NKE", Date: "2023-06-30", Close: 120},
+			}))
+
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+			var historyRepo db.PriceHistoryRepository = memoryHistoryRepo
+			if tc.historyError != nil {
+				historyRepo = &failingPriceHistoryRepository{err: tc.historyError}
+			}
+
+			handler := GetUserEsppLotLimit(lotRepo, planRepo, historyRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			var body espp.Section423Limit
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Equal(t, tc.expectedYears, body.Years)
+			assert.Equal(t, tc.expectedNextPurchase, body.NextPurchase)
+		})
+	}
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
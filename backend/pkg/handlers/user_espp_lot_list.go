@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func ListUserEsppLots(lotRepo db.EsppLotRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		options, invalidParam := parseEsppLotListOptions(request.QueryStringParameters)
+		if invalidParam != "" {
+			return utils.InvalidQueryParameterError(invalidParam)
+		}
+		options.UserID = userID
+
+		page, err := lotRepo.ListEsppLotsByUserID(options)
+		if errors.Is(err, db.ErrInvalidCursor) {
+			return utils.InvalidQueryParameterError(constants.QueryCursor)
+		}
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		return utils.APIResponse(200, page)
+	}
+}
+
+// parseEsppLotListOptions reads the listing query parameters. When one is
+// invalid, its name is returned alongside the partially filled options.
+func parseEsppLotListOptions(query map[string]string) (models.EsppLotListOptions, string) {
+	options := models.EsppLotListOptions{
+		Limit:            constants.DefaultPageLimit,
+		Cursor:           query[constants.QueryCursor],
+		PurchaseDateFrom: query[constants.QueryPurchaseDateFrom],
+		PurchaseDateTo:   query[constants.QueryPurchaseDateTo],
+		Ticker:           models.NormalizeTicker(query[constants.QueryTicker]),
+	}
+
+	if value, ok := query[constants.QueryLimit]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > constants.MaxPageLimit {
+			return options, constants.QueryLimit
+		}
+		options.Limit = limit
+	}
+
+	for param, value := range map[string]string{
+		constants.QueryPurchaseDateFrom: options.PurchaseDateFrom,
+		constants.QueryPurchaseDateTo:   options.PurchaseDateTo,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(models.DateLayout, value); err != nil {
+			return options, param
+		}
+	}
+
+	if options.PurchaseDateFrom != "" && options.PurchaseDateTo != "" && options.PurchaseDateFrom > options.PurchaseDateTo {
+		return options, constants.QueryPurchaseDateTo
+	}
+
+	switch query[constants.QueryOrder] {
+	case "", constants.OrderAsc:
+	case constants.OrderDesc:
+		options.Descending = true
+	default:
+		return options, constants.QueryOrder
+	}
+
+	return options, ""
+}
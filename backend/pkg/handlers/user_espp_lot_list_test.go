@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUserEsppLots(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockLots           []*models.EsppLot
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "successful retrieval",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockLots: []*models.EsppLot{
+				{
+					ID:              "lot123",
+					UserID:          "user123",
+					GrantDate:       "2023-01-01",
+					PurchaseDate:    "2023-06-30",
+					OfferStartPrice: 100.0,
+					OfferEndPrice:   120.0,
+					PurchasePrice:   85.0,
+					Shares:          10.0,
+					CreatedAt:       "2023-01-01T00:00:00Z",
+					UpdatedAt:       "2023-01-01T00:00:00Z",
+				},
+				{
+					ID:              "lot456",
+					UserID:          "user123",
+					GrantDate:       "2023-07-01",
+					PurchaseDate:    "2023-12-31",
+					OfferStartPrice: 120.0,
+					OfferEndPrice:   140.0,
+					PurchasePrice:   95.0,
+					Shares:          15.0,
+					CreatedAt:       "2023-07-01T00:00:00Z",
+					UpdatedAt:       "2023-07-01T00:00:00Z",
+				},
+			},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedBody:       `{"items":[{"id":"lot123","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10,"version":0,"createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-01T00:00:00Z"},{"id":"lot456","userId":"user123","grantDate":"2023-07-01","purchaseDate":"2023-12-31","offerStartPrice":120,"offerEndPrice":140,"purchasePrice":95,"shares":15,"version":0,"createdAt":"2023-07-01T00:00:00Z","updatedAt":"2023-07-01T00:00:00Z"}]}`,
+		},
+		{
+			name:     "no lots found",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user456",
+				},
+			},
+			mockLots:           []*models.EsppLot{},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedBody:       `{"items":[]}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockLots:           nil,
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name:     "invalid limit",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				QueryStringParameters: map[string]string{
+					"limit": "0",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: limit"}`,
+		},
+		{
+			name:     "invalid cursor",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				QueryStringParameters: map[string]string{
+					"cursor": "not a cursor",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: cursor"}`,
+		},
+		{
+			name:     "invalid purchase date filter",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				QueryStringParameters: map[string]string{
+					"purchaseDateFrom": "banana",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: purchaseDateFrom"}`,
+		},
+		{
+			name:     "purchase date range reversed",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				QueryStringParameters: map[string]string{
+					"purchaseDateFrom": "2024-01-01",
+					"purchaseDateTo":   "2023-01-01",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: purchaseDateTo"}`,
+		},
+		{
+			name:     "invalid order",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				QueryStringParameters: map[string]string{
+					"order": "sideways",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: order"}`,
+		},
+		{
+			name:     "other user",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			mockLots:           nil,
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range tc.mockLots {
+				memoryRepo.PutEsppLot(*lot)
+			}
+
+			var lotRepo db.EsppLotRepository = memoryRepo
+			if tc.mockError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.mockError}
+			}
+
+			handler := ListUserEsppLots(lotRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
+
+func TestListUserEsppLotsPagination(t *testing.T) {
+	memoryRepo := db.NewMemoryEsppLotRepository()
+	for _, lot := range []models.EsppLot{
+		{ID: "lot3", UserID: "user123", PurchaseDate: "2024-06-30", Ticker: "AAPL"},
+		{ID: "lot1", UserID: "user123", PurchaseDate: "2023-06-30", Ticker: "NKE"},
+		{ID: "lot4", UserID: "user123", PurchaseDate: "2024-12-31", Ticker: "AAPL"},
+		{ID: "lot2", UserID: "user123", PurchaseDate: "2023-12-31", Ticker: "NKE"},
+		{ID: "other", UserID: "user456", PurchaseDate: "2024-06-30"},
+	} {
+		memoryRepo.PutEsppLot(lot)
+	}
+
+	handler := ListUserEsppLots(memoryRepo)
+
+	list := func(query map[string]string) models.EsppLotPage {
+		response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+			PathParameters:        map[string]string{"userId": "user123"},
+			QueryStringParameters: query,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 200, response.StatusCode)
+
+		var page models.EsppLotPage
+		assert.NoError(t, json.Unmarshal([]byte(response.Body), &page))
+		return page
+	}
+
+	lotIDs := func(page models.EsppLotPage) []string {
+		ids := []string{}
+		for _, lot := range page.Items {
+			ids = append(ids, lot.ID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name        string
+		query       map[string]string
+		expectedIDs [][]string
+	}{
+		{
+			name:        "pages in purchase date order",
+			query:       map[string]string{"limit": "3"},
+			expectedIDs: [][]string{{"lot1", "lot2", "lot3"}, {"lot4"}},
+		},
+		{
+			name:        "descending order",
+			query:       map[string]string{"limit": "2", "order": "desc"},
+			expectedIDs: [][]string{{"lot4", "lot3"}, {"lot2", "lot1"}},
+		},
+		{
+			name:        "purchase date range",
+			query:       map[string]string{"limit": "1", "purchaseDateFrom": "2023-07-01", "purchaseDateTo": "2024-06-30"},
+			expectedIDs: [][]string{{"lot2"}, {"lot3"}},
+		},
+		{
+			name:        "ticker",
+			query:       map[string]string{"limit": "1", "ticker": "aapl"},
+			expectedIDs: [][]string{{"lot3"}, {"lot4"}},
+		},
+		{
+			name:        "default limit",
+			query:       map[string]string{},
+			expectedIDs: [][]string{{"lot1", "lot2", "lot3", "lot4"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := map[string]string{}
+			for key, value := range tc.query {
+				query[key] = value
+			}
+
+			for i, expected := range tc.expectedIDs {
+				page := list(query)
+				assert.Equal(t, expected, lotIDs(page))
+
+				if i == len(tc.expectedIDs)-1 {
+					assert.Empty(t, page.NextCursor)
+				} else {
+					assert.NotEmpty(t, page.NextCursor)
+				}
+				query["cursor"] = page.NextCursor
+			}
+		})
+	}
+}
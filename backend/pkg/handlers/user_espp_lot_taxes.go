@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type userEsppLotTaxesResponse struct {
+	MarketPrice  float64            `json:"marketPrice,omitempty"`
+	MarketPrices map[string]float64 `json:"marketPrices,omitempty"`
+	TaxYear      int                `json:"taxYear"`
+	Lots         []*espp.LotTaxes   `json:"lots"`
+	Sales        []*espp.SaleTaxes  `json:"sales"`
+}
+
+// GetUserEsppLotTaxes prices each lot from the marketPrice and marketPrices
+// query parameters, falling back to the latest known quote for its ticker.
+// Each lot is taxed under the rules of the plan it was bought under.
+func GetUserEsppLotTaxes(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository, quoteProvider quotes.PriceProvider) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		prices, invalidParam := parseMarketPrices(request.QueryStringParameters)
+		if invalidParam != "" {
+			return utils.InvalidQueryParameterError(invalidParam)
+		}
+
+		// Users who never saved settings still get estimates at the default
+		// rates.
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		var settings models.UserSettings
+		if user != nil {
+			settings = user.Settings
+		}
+		calculators := newTaxCalculators(settings)
+
+		taxYear := time.Now().UTC().Year()
+		lotCalculator, err := calculators.forYear(taxYear)
+		if err != nil {
+			slog.Error("Failed to build tax calculator", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP taxes"})
+		}
+
+		lots, err := lotRepo.GetEsppLotsByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		plans, err := planRepo.GetEsppPlansByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP plans", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plans"})
+		}
+
+		response := userEsppLotTaxesResponse{
+			MarketPrice:  prices.Default,
+			MarketPrices: prices.ByTicker,
+			TaxYear:      taxYear,
+			Lots:         []*espp.LotTaxes{},
+			Sales:        []*espp.SaleTaxes{},
+		}
+
+		for _, lot := range lots {
+			marketPrice, err := prices.PriceFor(lot)
+			if errors.Is(err, espp.ErrMissingMarketPrice) && lot.Ticker != "" {
+				quote, quoteErr := quoteProvider.LatestQuote(lot.Ticker)
+				if quoteErr != nil {
+					slog.Error("Failed to retrieve quote", slog.String("ticker", lot.Ticker), slog.Any("error", quoteErr))
+					return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve quote"})
+				}
+
+				// Later lots with the same ticker reuse the quote, and the
+				// response reports it alongside the prices that were passed in.
+				if quote != nil {
+					prices.ByTicker[models.NormalizeTicker(lot.Ticker)] = quote.Price
+					marketPrice, err = quote.Price, nil
+				}
+			}
+			if err != nil {
+				message := "Missing market price for lots without a ticker"
+				if lot.Ticker != "" {
+					message = fmt.Sprintf("Missing market price for ticker: %s", lot.Ticker)
+				}
+				return utils.APIResponse(400, map[string]string{"error": message})
+			}
+
+			plan := espp.PlanFor(lot, plans)
+
+			lotTaxes, err := espp.CalculateLotTaxes(lot, plan, marketPrice, lotCalculator)
+			if err != nil {
+				slog.Error("Failed to calculate ESPP lot taxes", slog.String("lotId", lot.ID), slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP taxes"})
+			}
+			response.Lots = append(response.Lots, lotTaxes)
+
+			sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+			}
+
+			for _, sale := range sales {
+				saleCalculator, err := calculators.forSale(sale)
+				if err != nil {
+					slog.Error("Failed to build tax calculator", slog.Any("error", err))
+					return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP taxes"})
+				}
+
+				saleTaxes, err := espp.CalculateSaleTaxes(lot, plan, sale, saleCalculator)
+				if err != nil {
+					slog.Error("Failed to calculate ESPP sale taxes", slog.String("saleId", sale.ID), slog.Any("error", err))
+					return utils.APIResponse(500, map[string]string{"error": "Failed to calculate ESPP taxes"})
+				}
+				response.Sales = append(response.Sales, saleTaxes)
+			}
+		}
+
+		return utils.APIResponse(200, response)
+	}
+}
+
+// parseMarketPrices reads marketPrice, the price for lots without a listed
+// ticker, and marketPrices, a comma-separated list of TICKER:price pairs. Both
+// are optional. When one is invalid, its name is returned.
+func parseMarketPrices(query map[string]string) (espp.MarketPrices, string) {
+	prices := espp.MarketPrices{ByTicker: map[string]float64{}}
+
+	if defaultValue, ok := query[constants.QueryMarketPrice]; ok {
+		price, err := strconv.ParseFloat(defaultValue, 64)
+		if err != nil || price <= 0 {
+			return prices, constants.QueryMarketPrice
+		}
+		prices.Default = price
+	}
+
+	if tickerValues := query[constants.QueryMarketPrices]; tickerValues != "" {
+		for _, pair := range strings.Split(tickerValues, ",") {
+			ticker, value, ok := strings.Cut(pair, ":")
+			ticker = models.NormalizeTicker(ticker)
+			if !ok || ticker == "" {
+				return prices, constants.QueryMarketPrices
+			}
+
+			price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || price <= 0 {
+				return prices, constants.QueryMarketPrices
+			}
+			prices.ByTicker[ticker] = price
+		}
+	}
+
+	return prices, ""
+}
+
+// taxCalculators builds one calculator per tax year, since recorded sales are
+// taxed with the tables for the year they happened.
+type taxCalculators struct {
+	settings models.UserSettings
+	byYear   map[int]espp.Calculator
+}
+
+func newTaxCalculators(settings models.UserSettings) *taxCalculators {
+	return &taxCalculators{settings: settings, byYear: map[int]espp.Calculator{}}
+}
+
+func (c *taxCalculators) forYear(year int) (espp.Calculator, error) {
+	if calculator, ok := c.byYear[year]; ok {
+		return calculator, nil
+	}
+
+	calculator, err := espp.CalculatorForSettings(c.settings, year)
+	if err != nil {
+		return nil, err
+	}
+	c.byYear[year] = calculator
+
+	return calculator, nil
+}
+
+// forSale falls back to the current year for unparseable dates and leaves
+// reporting them to espp.CalculateSaleTaxes.
+func (c *taxCalculators) forSale(sale *models.EsppSale) (espp.Calculator, error) {
+	year := time.Now().UTC().Year()
+	if date, err := espp.ParseDate(sale.Date); err == nil {
+		year = date.Year()
+	}
+
+	return c.forYear(year)
+}
@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserEsppLotTaxesByTicker(t *testing.T) {
+	lotRepo := db.NewMemoryEsppLotRepository()
+	for _, lot := range []models.EsppLot{
+		{ID: "nke", UserID: "user123", GrantDate: "2022-10-01", PurchaseDate: "2023-03-31", OfferStartPrice: 83.12, OfferEndPrice: 120.1, PurchasePrice: 70.65, Shares: 10, Ticker: "NKE"},
+		{ID: "aapl", UserID: "user123", GrantDate: "2022-10-01", PurchaseDate: "2023-03-31", OfferStartPrice: 150, OfferEndPrice: 160, PurchasePrice: 127.5, Shares: 10, Ticker: "AAPL"},
+		{ID: "untagged", UserID: "user123", GrantDate: "2022-10-01", PurchaseDate: "2023-03-31", OfferStartPrice: 83.12, OfferEndPrice: 120.1, PurchasePrice: 70.65, Shares: 10},
+	} {
+		lotRepo.PutEsppLot(lot)
+	}
+
+	quoteProvider := quotes.NewFileProvider([]models.Quote{{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"}})
+	handler := GetUserEsppLotTaxes(db.NewMemoryUserRepository(), lotRepo, db.NewMemoryEsppSaleRepository(), db.NewMemoryEsppPlanRepository(), quoteProvider)
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters:        map[string]string{"userId": "user123"},
+		QueryStringParameters: map[string]string{"marketPrice": "100", "marketPrices": "nke:125.1, AAPL:190"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var body userEsppLotTaxesResponse
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.Equal(t, map[string]float64{"NKE": 125.1, "AAPL": 190}, body.MarketPrices)
+
+	prices := map[string]float64{}
+	for _, lotTaxes := range body.Lots {
+		prices[lotTaxes.Lot.ID] = lotTaxes.MarketPrice
+	}
+	assert.Equal(t, map[string]float64{"nke": 125.1, "aapl": 190, "untagged": 100}, prices)
+}
+
+func TestGetUserEsppLotTaxesFromQuotes(t *testing.T) {
+	lotRepo := db.NewMemoryEsppLotRepository()
+	for _, lot := range []models.EsppLot{
+		{ID: "nke", UserID: "user123", GrantDate: "2022-10-01", PurchaseDate: "2023-03-31", OfferStartPrice: 83.12, OfferEndPrice: 120.1, PurchasePrice: 70.65, Shares: 10, Ticker: "NKE"},
+		{ID: "aapl", UserID: "user123", GrantDate: "2022-10-01", PurchaseDate: "2023-03-31", OfferStartPrice: 150, OfferEndPrice: 160, PurchasePrice: 127.5, Shares: 10, Ticker: "AAPL"},
+	} {
+		lotRepo.PutEsppLot(lot)
+	}
+
+	quoteProvider := quotes.NewFileProvider([]models.Quote{
+		{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"},
+		{Ticker: "AAPL", Price: 250, Date: "2026-10-16"},
+	})
+	handler := GetUserEsppLotTaxes(db.NewMemoryUserRepository(), lotRepo, db.NewMemoryEsppSaleRepository(), db.NewMemoryEsppPlanRepository(), quoteProvider)
+	response, err := handler(callerContext("user123"), events.APIGatewayProxyRequest{
+		PathParameters:        map[string]string{"userId": "user123"},
+		QueryStringParameters: map[string]string{"marketPrices": "AAPL:190"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var body userEsppLotTaxesResponse
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+
+	// Prices passed in win over quotes, and the quotes used are reported.
+	assert.Equal(t, map[string]float64{"NKE": 97.5, "AAPL": 190}, body.MarketPrices)
+	assert.Equal(t, 97.5, body.Lots[0].MarketPrice)
+	assert.Equal(t, 190.0, body.Lots[1].MarketPrice)
+}
+
+func TestGetUserEsppLotTaxes(t *testing.T) {
+	lot := models.EsppLot{
+		ID:              "lot123",
+		UserID:          "user123",
+		GrantDate:       "2022-10-01",
+		PurchaseDate:    "2023-03-31",
+		OfferStartPrice: 83.12,
+		OfferEndPrice:   120.1,
+		PurchasePrice:   70.65,
+		Shares:          96.5303,
+	}
+	sale := models.EsppSale{
+		ID:     "sale123",
+		LotID:  "lot123",
+		UserID: "user123",
+		Date:   "2024-10-01",
+		Price:  125.1,
+		Shares: 48.26515,
+	}
+
+	testCases := []struct {
+		name                    string
+		callerID                string
+		request                 events.APIGatewayProxyRequest
+		mockUser                *models.User
+		mockLots                []models.EsppLot
+		mockSales               []models.EsppSale
+		userError               error
+		lotError                error
+		saleError               error
+		mockPlans               []models.EsppPlan
+		planError               error
+		mockQuotes              []models.Quote
+		quoteError              error
+		expectedStatusCode      int
+		expectedBody            string
+		expectedLots            int
+		expectedSales           int
+		expectedQualifyingTotal float64
+	}{
+		{
+			name:     "successful calculation",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockLots:                []models.EsppLot{lot},
+			mockSales:               []models.EsppSale{sale},
+			expectedStatusCode:      200,
+			expectedLots:            1,
+			expectedSales:           1,
+			expectedQualifyingTotal: 896.73,
+		},
+		{
+			name:     "employer plan",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockLots:  []models.EsppLot{withEmployer(lot, "Acme")},
+			mockSales: []models.EsppSale{sale},
+			mockPlans: []models.EsppPlan{
+				{ID: "plan123", UserID: "user123", Name: "Acme ESPP", Employer: "acme", DiscountPercent: 10, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6},
+			},
+			expectedStatusCode:      200,
+			expectedLots:            1,
+			expectedSales:           1,
+			expectedQualifyingTotal: 860.62,
+		},
+		{
+			name:     "tax profile rates",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					TaxProfile: &models.TaxProfile{
+						FilingStatus:             models.FilingStatusSingle,
+						OrdinaryIncomeRate:       32,
+						LongTermCapitalGainsRate: 20,
+					},
+				},
+			},
+			mockLots:                []models.EsppLot{lot},
+			mockSales:               []models.EsppSale{sale},
+			expectedStatusCode:      200,
+			expectedLots:            1,
+			expectedSales:           1,
+			expectedQualifyingTotal: 1195.64,
+		},
+		{
+			name:     "tax brackets on top of salary",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{AnnualSalary: 40000, PaychecksPerYear: 26},
+					TaxProfile: &models.TaxProfile{
+						FilingStatus:             models.FilingStatusSingle,
+						OrdinaryIncomeRate:       12,
+						LongTermCapitalGainsRate: 0,
+					},
+				},
+			},
+			mockLots:           []models.EsppLot{lot},
+			mockSales:          []models.EsppSale{sale},
+			expectedStatusCode: 200,
+			expectedLots:       1,
+			expectedSales:      1,
+			// The discount is taxed at 12% and the gain fits in the 0% bracket.
+			expectedQualifyingTotal: 144.42,
+		},
+		{
+			name:     "no lots",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:     "user database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+		{
+			name:     "missing ticker price",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrices": "NKE:125.1"},
+			},
+			mockLots:           []models.EsppLot{{ID: "lot456", UserID: "user123", Ticker: "AAPL"}},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing market price for ticker: AAPL"}`,
+		},
+		{
+			name:     "missing price for lot without ticker",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrices": "NKE:125.1"},
+			},
+			mockLots:           []models.EsppLot{lot},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing market price for lots without a ticker"}`,
+		},
+		{
+			name:     "malformed market prices",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrices": "NKE=125.1"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: marketPrices"}`,
+		},
+		{
+			name:     "invalid lot dates",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockLots:           []models.EsppLot{{ID: "lot123", UserID: "user123", GrantDate: "banana", PurchaseDate: "2023-03-31"}},
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to calculate ESPP taxes"}`,
+		},
+		{
+			name:     "missing market price",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockLots:           []models.EsppLot{lot},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing market price for lots without a ticker"}`,
+		},
+		{
+			name:     "latest quote",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockLots:                []models.EsppLot{withTicker(lot, "NKE")},
+			mockSales:               []models.EsppSale{sale},
+			mockQuotes:              []models.Quote{{Ticker: "NKE", Price: 125.1, Date: "2024-10-01"}},
+			expectedStatusCode:      200,
+			expectedLots:            1,
+			expectedSales:           1,
+			expectedQualifyingTotal: 896.73,
+		},
+		{
+			name:     "quote provider error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockLots:           []models.EsppLot{withTicker(lot, "NKE")},
+			quoteError:         errors.New("quote service down"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve quote"}`,
+		},
+		{
+			name:     "non-positive market price",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "-5"},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: marketPrice"}`,
+		},
+		{
+			name:     "lot database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name:     "plan database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockLots:           []models.EsppLot{lot},
+			planError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plans"}`,
+		},
+		{
+			name:     "sale database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			mockLots:           []models.EsppLot{lot},
+			saleError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sales"}`,
+		},
+		{
+			name:     "other user",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"marketPrice": "125.1"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			if tc.mockUser != nil {
+				memoryUserRepo.PutUser(*tc.mockUser)
+			}
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range tc.mockLots {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			for _, sale := range tc.mockSales {
+				memorySaleRepo.PutEsppSale(sale)
+			}
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.saleError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.saleError}
+			}
+
+			memoryPlanRepo := db.NewMemoryEsppPlanRepository()
+			for _, plan := range tc.mockPlans {
+				memoryPlanRepo.PutEsppPlan(plan)
+			}
+			var planRepo db.EsppPlanRepository = memoryPlanRepo
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+
+			var quoteProvider quotes.PriceProvider = quotes.NewFileProvider(tc.mockQuotes)
+			if tc.quoteError != nil {
+				quoteProvider = &failingPriceProvider{err: tc.quoteError}
+			}
+
+			handler := GetUserEsppLotTaxes(userRepo, lotRepo, saleRepo, planRepo, quoteProvider)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			var body userEsppLotTaxesResponse
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Len(t, body.Lots, tc.expectedLots)
+			assert.Len(t, body.Sales, tc.expectedSales)
+			if tc.expectedLots == 0 {
+				return
+			}
+			assert.InDelta(t, tc.expectedQualifyingTotal, body.Lots[0].Dispositions.Qualifying.Taxes.Total, 0.005)
+			assert.Equal(t, espp.DispositionQualifying, body.Sales[0].Disposition.Name)
+		})
+	}
+}
+
+func withTicker(lot models.EsppLot, ticker string) models.EsppLot {
+	lot.Ticker = ticker
+	return lot
+}
+
+func withEmployer(lot models.EsppLot, employer string) models.EsppLot {
+	lot.Employer = employer
+	return lot
+}
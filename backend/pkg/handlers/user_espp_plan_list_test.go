@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUserEsppPlans(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockPlans          []models.EsppPlan
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "only the user's plans",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockPlans: []models.EsppPlan{
+				{ID: "plan123", UserID: "user123", Name: "Acme ESPP", DiscountPercent: 15, Lookback: true, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6, ResetRule: models.EsppResetRuleNone},
+				{ID: "plan456", UserID: "user456", Name: "Globex ESPP"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `[{"id":"plan123","userId":"user123","name":"Acme ESPP","discountPercent":15,"lookback":true,"offeringPeriodMonths":6,"purchasePeriodMonths":6,"resetRule":"none","createdAt":"","updatedAt":""}]`,
+		},
+		{
+			name:     "no plans",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       `[]`,
+		},
+		{
+			name:     "other user's plans",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plans"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryEsppPlanRepository()
+			for _, plan := range tc.mockPlans {
+				memoryRepo.PutEsppPlan(plan)
+			}
+
+			var planRepo db.EsppPlanRepository = memoryRepo
+			if tc.mockError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.mockError}
+			}
+
+			handler := ListUserEsppPlans(planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// PlanUserEsppSale picks the unsold shares to sell for a share or cash target
+// that owe the least tax, compared with FIFO and HIFO. Lots of different
+// stocks cannot share one price, so a ticker is required when the user holds
+// more than one.
+func PlanUserEsppSale(userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, planRepo db.EsppPlanRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		var input models.EsppSellPlanInput
+		if err := json.Unmarshal([]byte(request.Body), &input); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if errs := input.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		var settings models.UserSettings
+		if user != nil {
+			settings = user.Settings
+		}
+
+		lots, err := lotRepo.GetEsppLotsByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP lots", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP lots"})
+		}
+
+		lots, ambiguous := lotsForTicker(lots, input.Ticker)
+		if ambiguous {
+			return validationError(models.ValidationErrors{
+				{Field: "ticker", Message: "is required when lots have more than one ticker"},
+			})
+		}
+
+		plans, err := planRepo.GetEsppPlansByUserID(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve ESPP plans", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP plans"})
+		}
+
+		available := make([]espp.AvailableLot, 0, len(lots))
+		for _, lot := range lots {
+			sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+			if err != nil {
+				slog.Error("Failed to retrieve ESPP sales", slog.Any("error", err))
+				return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve ESPP sales"})
+			}
+
+			available = append(available, espp.AvailableLot{
+				Lot:    lot,
+				Plan:   espp.PlanFor(lot, plans),
+				Shares: lot.Shares - models.TotalSoldShares(sales),
+			})
+		}
+
+		calculator, err := newTaxCalculators(settings).forSale(&models.EsppSale{Date: input.Date})
+		if err != nil {
+			slog.Error("Failed to build tax calculator", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to plan ESPP sale"})
+		}
+
+		plan, err := espp.PlanSale(available, input, calculator)
+		if errors.Is(err, espp.ErrInsufficientShares) {
+			return utils.APIResponse(400, map[string]string{"error": "Not enough unsold shares to reach the target"})
+		}
+		if err != nil {
+			slog.Error("Failed to plan ESPP sale", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to plan ESPP sale"})
+		}
+
+		return utils.APIResponse(200, plan)
+	}
+}
+
+// lotsForTicker keeps the lots of the ticker, or every lot when no ticker is
+// given. It reports whether leaving out the ticker is ambiguous because the
+// lots span more than one ticker.
+func lotsForTicker(lots []*models.EsppLot, ticker string) ([]*models.EsppLot, bool) {
+	ticker = models.NormalizeTicker(ticker)
+	if ticker == "" {
+		tickers := map[string]bool{}
+		for _, lot := range lots {
+			if lot.Ticker != "" {
+				tickers[models.NormalizeTicker(lot.Ticker)] = true
+			}
+		}
+
+		return lots, len(tickers) > 1
+	}
+
+	matching := []*models.EsppLot{}
+	for _, lot := range lots {
+		if models.NormalizeTicker(lot.Ticker) == ticker {
+			matching = append(matching, lot)
+		}
+	}
+
+	return matching, false
+}
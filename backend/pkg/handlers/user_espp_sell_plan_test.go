@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanUserEsppSale(t *testing.T) {
+	oldLot := models.EsppLot{ID: "old", UserID: "user123", GrantDate: "2021-01-01", PurchaseDate: "2021-06-30", OfferStartPrice: 50, OfferEndPrice: 60, PurchasePrice: 42.5, Shares: 10, Ticker: "NKE"}
+	lossLot := models.EsppLot{ID: "loss", UserID: "user123", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 150, OfferEndPrice: 160, PurchasePrice: 127.5, Shares: 10, Ticker: "NKE"}
+	otherLot := models.EsppLot{ID: "other", UserID: "user123", GrantDate: "2023-07-01", PurchaseDate: "2023-12-29", OfferStartPrice: 10, OfferEndPrice: 12, PurchasePrice: 8.5, Shares: 100, Ticker: "AAPL"}
+	sale := models.EsppSale{ID: "sale123", LotID: "loss", UserID: "user123", Date: "2024-01-15", Price: 150, Shares: 4}
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		mockLots           []models.EsppLot
+		mockSales          []models.EsppSale
+		userError          error
+		lotError           error
+		saleError          error
+		planError          error
+		expectedStatusCode int
+		expectedBody       string
+		expectedOptimized  []string
+		expectedFIFO       []string
+	}{
+		{
+			name:     "share target",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":8,"date":"2024-07-01","price":120}`,
+			},
+			mockLots:           []models.EsppLot{oldLot, lossLot},
+			mockSales:          []models.EsppSale{sale},
+			expectedStatusCode: 200,
+			expectedOptimized:  []string{"loss", "old"},
+			expectedFIFO:       []string{"old"},
+		},
+		{
+			name:     "cash target for one ticker",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"cash":600,"date":"2024-07-01","price":120,"ticker":"nke"}`,
+			},
+			mockLots:           []models.EsppLot{oldLot, lossLot, otherLot},
+			expectedStatusCode: 200,
+			expectedOptimized:  []string{"loss"},
+			expectedFIFO:       []string{"old"},
+		},
+		{
+			name:     "ticker required",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			mockLots:           []models.EsppLot{oldLot, otherLot},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"ticker","message":"is required when lots have more than one ticker"}]}`,
+		},
+		{
+			name:     "not enough shares",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":17,"date":"2024-07-01","price":120}`,
+			},
+			mockLots:           []models.EsppLot{oldLot, lossLot},
+			mockSales:          []models.EsppSale{sale},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Not enough unsold shares to reach the target"}`,
+		},
+		{
+			name:     "invalid input",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"cash":600,"date":"07/01/2024","price":0}`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"cash","message":"must not be set along with shares"},{"field":"date","message":"must be a date in YYYY-MM-DD format"},{"field":"price","message":"must be greater than zero"}]}`,
+		},
+		{
+			name:     "invalid JSON",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":`,
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "user database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			userError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+		{
+			name:     "lot database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			lotError:           errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP lots"}`,
+		},
+		{
+			name:     "plan database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			mockLots:           []models.EsppLot{oldLot},
+			planError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP plans"}`,
+		},
+		{
+			name:     "sale database error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			mockLots:           []models.EsppLot{oldLot},
+			saleError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve ESPP sales"}`,
+		},
+		{
+			name:     "other user's lots",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+				Body:           `{"shares":5,"date":"2024-07-01","price":120}`,
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "missing user ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range tc.mockLots {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			for _, sale := range tc.mockSales {
+				memorySaleRepo.PutEsppSale(sale)
+			}
+
+			var userRepo db.UserRepository = db.NewMemoryUserRepository()
+			if tc.userError != nil {
+				userRepo = &failingUserRepository{err: tc.userError}
+			}
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotError != nil {
+				lotRepo = &failingEsppLotRepository{err: tc.lotError}
+			}
+			var saleRepo db.EsppSaleRepository = memorySaleRepo
+			if tc.saleError != nil {
+				saleRepo = &failingEsppSaleRepository{err: tc.saleError}
+			}
+			var planRepo db.EsppPlanRepository = db.NewMemoryEsppPlanRepository()
+			if tc.planError != nil {
+				planRepo = &failingEsppPlanRepository{err: tc.planError}
+			}
+
+			handler := PlanUserEsppSale(userRepo, lotRepo, saleRepo, planRepo)
+			response, err := handler(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, response.Body)
+				return
+			}
+
+			var body espp.SellPlan
+			assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+			assert.Equal(t, tc.expectedOptimized, sellPlanLotIDs(body.Optimized))
+			assert.Equal(t, tc.expectedFIFO, sellPlanLotIDs(body.FIFO))
+			assert.LessOrEqual(t, body.Optimized.Taxes, body.FIFO.Taxes)
+			assert.LessOrEqual(t, body.Optimized.Taxes, body.HIFO.Taxes)
+		})
+	}
+}
+
+func sellPlanLotIDs(selection espp.SellSelection) []string {
+	ids := []string{}
+	for _, lot := range selection.Lots {
+		ids = append(ids, lot.LotID)
+	}
+
+	return ids
+}
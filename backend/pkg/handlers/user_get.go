@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+func GetUser(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		if user == nil {
+			return utils.APIResponse(404, map[string]string{"error": "User not found"})
+		}
+
+		return utils.ETagResponse(200, versionETag(user.Version), user)
+	}
+}
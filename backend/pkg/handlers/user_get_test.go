@@ -1,27 +1,29 @@
-package main
+package handlers
 
 import (
-	"context"
 	"errors"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ljhurst/fife/pkg/db"
 	"github.com/ljhurst/fife/pkg/models"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestHandler(t *testing.T) {
+func TestGetUser(t *testing.T) {
 	testCases := []struct {
 		name               string
+		callerID           string
 		request            events.APIGatewayProxyRequest
 		mockUser           *models.User
 		mockError          error
 		expectedStatusCode int
 		expectedBody       string
+		expectedETag       string
 	}{
 		{
-			name: "Success",
+			name:     "Success",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"userId": "user123",
@@ -35,15 +37,18 @@ func TestHandler(t *testing.T) {
 						PaychecksPerYear: 26,
 					},
 				},
+				Version:   3,
 				CreatedAt: "2023-01-01T00:00:00Z",
 				UpdatedAt: "2023-01-02T00:00:00Z",
 			},
 			mockError:          nil,
 			expectedStatusCode: 200,
-			expectedBody:       `{"userId":"user123","settings":{"finance":{"annualSalary":100000,"paychecksPerYear":26}},"createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-02T00:00:00Z"}`,
+			expectedBody:       `{"userId":"user123","settings":{"finance":{"annualSalary":100000,"paychecksPerYear":26},"retirement":{"employerMatchRate":0,"employerMatchLimit":0,"rothPercent":0}},"version":3,"createdAt":"2023-01-01T00:00:00Z","updatedAt":"2023-01-02T00:00:00Z"}`,
+			expectedETag:       `"3"`,
 		},
 		{
-			name: "Missing User ID",
+			name:     "Missing User ID",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{},
 			},
@@ -53,7 +58,8 @@ func TestHandler(t *testing.T) {
 			expectedBody:       `{"error":"Missing path parameter: userId"}`,
 		},
 		{
-			name: "User Not Found",
+			name:     "User Not Found",
+			callerID: "nonexistent",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"userId": "nonexistent",
@@ -65,7 +71,34 @@ func TestHandler(t *testing.T) {
 			expectedBody:       `{"error":"User not found"}`,
 		},
 		{
-			name: "Database Error",
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockUser:           nil,
+			mockError:          nil,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+			},
+			mockUser:           nil,
+			mockError:          nil,
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
 			request: events.APIGatewayProxyRequest{
 				PathParameters: map[string]string{
 					"userId": "user123",
@@ -80,16 +113,23 @@ func TestHandler(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockGetUserFn := func(_ dynamodbiface.DynamoDBAPI, _ string) (*models.User, error) {
-				return tc.mockUser, tc.mockError
+			memoryRepo := db.NewMemoryUserRepository()
+			if tc.mockUser != nil {
+				memoryRepo.PutUser(*tc.mockUser)
+			}
+
+			var userRepo db.UserRepository = memoryRepo
+			if tc.mockError != nil {
+				userRepo = &failingUserRepository{err: tc.mockError}
 			}
 
-			handlerFn := handlerWithDeps(mockGetUserFn)
+			handlerFn := GetUser(userRepo)
 
-			response, err := handlerFn(context.Background(), tc.request)
+			response, err := handlerFn(callerContext(tc.callerID), tc.request)
 
 			assert.NoError(t, err)
 			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedETag, response.Headers["ETag"])
 
 			if tc.expectedStatusCode == 200 {
 				assert.JSONEq(t, tc.expectedBody, response.Body)
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/paycheck"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type userPaychecksRemainingResponse struct {
+	Date  string   `json:"date"`
+	Year  int      `json:"year"`
+	Count int      `json:"count"`
+	Dates []string `json:"dates"`
+}
+
+func GetUserPaychecksRemaining(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		from := time.Now().UTC()
+		if value := request.QueryStringParameters[constants.QueryDate]; value != "" {
+			date, err := time.Parse(models.DateLayout, value)
+			if err != nil {
+				return utils.InvalidQueryParameterError(constants.QueryDate)
+			}
+			from = date
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			slog.Error("Failed to retrieve user", slog.Any("error", err))
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		if user == nil {
+			return utils.APIResponse(404, map[string]string{"error": "User not found"})
+		}
+
+		schedule := user.Settings.Finance.PaySchedule
+		if schedule == nil {
+			return utils.APIResponse(400, map[string]string{"error": "User pay schedule is not set"})
+		}
+
+		dates, err := paycheck.Remaining(*schedule, from)
+		if err != nil {
+			return utils.APIResponse(400, map[string]string{"error": "User pay schedule is invalid"})
+		}
+
+		response := userPaychecksRemainingResponse{
+			Date:  from.Format(models.DateLayout),
+			Year:  from.Year(),
+			Count: len(dates),
+			Dates: make([]string, len(dates)),
+		}
+		for i, date := range dates {
+			response.Dates[i] = date.Format(models.DateLayout)
+		}
+
+		return utils.APIResponse(200, response)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserPaychecksRemaining(t *testing.T) {
+	scheduledUser := &models.User{
+		UserID: "user123",
+		Settings: models.UserSettings{
+			Finance: models.UserFinanceSettings{
+				AnnualSalary:     120000,
+				PaychecksPerYear: 24,
+				PaySchedule: &models.PaySchedule{
+					Frequency:  models.PayFrequencySemimonthly,
+					AnchorDate: "2026-01-15",
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		existingUser       *models.User
+		mockError          error
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"date": "2026-11-16"},
+			},
+			existingUser:       scheduledUser,
+			expectedStatusCode: 200,
+			expectedBody:       `{"date":"2026-11-16","year":2026,"count":3,"dates":["2026-11-30","2026-12-15","2026-12-30"]}`,
+		},
+		{
+			name:     "None Remaining",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"date": "2026-12-31"},
+			},
+			existingUser:       scheduledUser,
+			expectedStatusCode: 200,
+			expectedBody:       `{"date":"2026-12-31","year":2026,"count":0,"dates":[]}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+		{
+			name:     "Unauthenticated",
+			callerID: "",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 401,
+			expectedBody:       `{"error":"Unauthorized"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			existingUser:       scheduledUser,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Invalid Date",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"userId": "user123"},
+				QueryStringParameters: map[string]string{"date": "11/16/2026"},
+			},
+			existingUser:       scheduledUser,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid query parameter: date"}`,
+		},
+		{
+			name:     "User Not Found",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			expectedStatusCode: 404,
+			expectedBody:       `{"error":"User not found"}`,
+		},
+		{
+			name:     "No Pay Schedule",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			existingUser: &models.User{
+				UserID:   "user123",
+				Settings: models.UserSettings{Finance: models.UserFinanceSettings{AnnualSalary: 120000, PaychecksPerYear: 24}},
+			},
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"User pay schedule is not set"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"userId": "user123"},
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryUserRepository()
+			if tc.existingUser != nil {
+				memoryRepo.PutUser(*tc.existingUser)
+			}
+
+			var userRepo db.UserRepository = memoryRepo
+			if tc.mockError != nil {
+				userRepo = &failingUserRepository{err: tc.mockError}
+			}
+
+			handlerFn := GetUserPaychecksRemaining(userRepo)
+
+			response, err := handlerFn(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedBody, response.Body)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// UpdateUserSettings replaces the parts of the user's settings sent in the
+// body and keeps the rest. An If-Match header with the ETag from a previous
+// read makes the write fail with 412 when the settings have changed since.
+func UpdateUserSettings(userRepo db.UserRepository) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		userID := request.PathParameters[constants.PathUserID]
+		if userID == "" {
+			return utils.MissingPathParameterError(constants.PathUserID)
+		}
+
+		callerID, ok := auth.UserIDFromContext(ctx)
+		if !ok {
+			return utils.UnauthorizedError()
+		}
+
+		if userID != callerID {
+			return utils.ForbiddenError()
+		}
+
+		expectedVersion, ok := ifMatchVersion(request.Headers)
+		if !ok {
+			return utils.PreconditionFailedError()
+		}
+
+		var settingsUpdate models.UserSettingsUpdate
+		if err := json.Unmarshal([]byte(request.Body), &settingsUpdate); err != nil {
+			return utils.InvalidRequestBodyError()
+		}
+
+		if settingsUpdate.IsEmpty() {
+			return utils.APIResponse(400, map[string]string{"error": "No fields to update"})
+		}
+
+		user, err := userRepo.GetUser(userID)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to retrieve user"})
+		}
+
+		var userSettings models.UserSettings
+		if user != nil {
+			if expectedVersion != nil && *expectedVersion != user.Version {
+				return utils.PreconditionFailedError()
+			}
+
+			userSettings = user.Settings
+
+			// The merged settings are only right for the version they were
+			// read from, so the write is held to it.
+			expectedVersion = &user.Version
+		}
+		settingsUpdate.Apply(&userSettings)
+
+		if errs := userSettings.Validate(); len(errs) > 0 {
+			return validationError(errs)
+		}
+
+		updatedUser, err := userRepo.UpdateUserSettings(userID, userSettings, expectedVersion)
+		if err != nil {
+			return utils.APIResponse(500, map[string]string{"error": "Failed to update user settings"})
+		}
+
+		// The write only comes back empty when the user changed since it was read.
+		if updatedUser == nil {
+			return utils.PreconditionFailedError()
+		}
+
+		return utils.ETagResponse(200, versionETag(updatedUser.Version), updatedUser)
+	}
+}
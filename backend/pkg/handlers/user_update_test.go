@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateUserSettings(t *testing.T) {
+	testCases := []struct {
+		name               string
+		callerID           string
+		request            events.APIGatewayProxyRequest
+		existingUser       *models.User
+		mockError          error
+		expectedStatusCode int
+		expectedUser       *models.User
+		expectedBody       string
+		expectedETag       string
+	}{
+		{
+			name:     "Success",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			existingUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     100000,
+						PaychecksPerYear: 26,
+					},
+				},
+				CreatedAt: "2023-01-01T00:00:00Z",
+				UpdatedAt: "2023-01-02T00:00:00Z",
+			},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     120000,
+						PaychecksPerYear: 24,
+					},
+				},
+				Version:   1,
+				CreatedAt: "2023-01-01T00:00:00Z",
+			},
+			expectedETag: `"1"`,
+		},
+		{
+			name:     "Matching If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Headers: map[string]string{
+					"if-match": `"2"`,
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			existingUser: &models.User{
+				UserID:    "user123",
+				Version:   2,
+				CreatedAt: "2023-01-01T00:00:00Z",
+				UpdatedAt: "2023-01-02T00:00:00Z",
+			},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     120000,
+						PaychecksPerYear: 24,
+					},
+				},
+				Version:   3,
+				CreatedAt: "2023-01-01T00:00:00Z",
+			},
+			expectedETag: `"3"`,
+		},
+		{
+			name:     "Stale If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Headers: map[string]string{
+					"If-Match": `"1"`,
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			existingUser: &models.User{
+				UserID:    "user123",
+				Version:   2,
+				CreatedAt: "2023-01-01T00:00:00Z",
+				UpdatedAt: "2023-01-02T00:00:00Z",
+			},
+			mockError:          nil,
+			expectedStatusCode: 412,
+			expectedBody:       `{"error":"Precondition failed"}`,
+		},
+		{
+			name:     "Weak If-Match",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Headers: map[string]string{
+					"If-Match": `W/"2"`,
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 412,
+			expectedBody:       `{"error":"Precondition failed"}`,
+		},
+		{
+			name:     "Missing User ID",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{},
+				Body:           `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Missing path parameter: userId"}`,
+		},
+		{
+			name:     "Invalid Settings",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":0}}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"finance.paychecksPerYear","message":"must be greater than zero"}]}`,
+		},
+		{
+			name:     "Tax Profile",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24},"taxProfile":{"filingStatus":"married_filing_jointly","ordinaryIncomeRate":22,"longTermCapitalGainsRate":15,"stateRate":4.5,"niit":false}}`,
+			},
+			existingUser: &models.User{
+				UserID:    "user123",
+				CreatedAt: "2023-01-01T00:00:00Z",
+				UpdatedAt: "2023-01-02T00:00:00Z",
+			},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     120000,
+						PaychecksPerYear: 24,
+					},
+					TaxProfile: &models.TaxProfile{
+						FilingStatus:             models.FilingStatusMarriedFilingJointly,
+						OrdinaryIncomeRate:       22,
+						LongTermCapitalGainsRate: 15,
+						StateRate:                4.5,
+					},
+				},
+				Version:   1,
+				CreatedAt: "2023-01-01T00:00:00Z",
+			},
+			expectedETag: `"1"`,
+		},
+		{
+			name:     "Invalid Tax Profile",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24},"taxProfile":{"filingStatus":"single","ordinaryIncomeRate":240,"longTermCapitalGainsRate":15}}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Validation failed","fields":[{"field":"taxProfile.ordinaryIncomeRate","message":"must be between 0 and 100"}]}`,
+		},
+		{
+			name:     "Finance Only Keeps Other Settings",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			existingUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     100000,
+						PaychecksPerYear: 26,
+						PaySchedule:      &models.PaySchedule{Frequency: models.PayFrequencyBiweekly, AnchorDate: "2023-01-06"},
+					},
+					Retirement: models.UserRetirementSettings{EmployerMatchRate: 50, EmployerMatchLimit: 6},
+					TaxProfile: &models.TaxProfile{
+						FilingStatus:             models.FilingStatusSingle,
+						OrdinaryIncomeRate:       24,
+						LongTermCapitalGainsRate: 15,
+					},
+					Notifications: &models.NotificationSettings{Email: "user@example.com", HoldingPeriod: true},
+				},
+				Version:   4,
+				CreatedAt: "2023-01-01T00:00:00Z",
+				UpdatedAt: "2023-01-02T00:00:00Z",
+			},
+			mockError:          nil,
+			expectedStatusCode: 200,
+			expectedUser: &models.User{
+				UserID: "user123",
+				Settings: models.UserSettings{
+					Finance: models.UserFinanceSettings{
+						AnnualSalary:     120000,
+						PaychecksPerYear: 24,
+						PaySchedule:      &models.PaySchedule{Frequency: models.PayFrequencyBiweekly, AnchorDate: "2023-01-06"},
+					},
+					Retirement: models.UserRetirementSettings{EmployerMatchRate: 50, EmployerMatchLimit: 6},
+					TaxProfile: &models.TaxProfile{
+						FilingStatus:             models.FilingStatusSingle,
+						OrdinaryIncomeRate:       24,
+						LongTermCapitalGainsRate: 15,
+					},
+					Notifications: &models.NotificationSettings{Email: "user@example.com", HoldingPeriod: true},
+				},
+				Version:   5,
+				CreatedAt: "2023-01-01T00:00:00Z",
+			},
+			expectedETag: `"5"`,
+		},
+		{
+			name:     "No Fields",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"No fields to update"}`,
+		},
+		{
+			name:     "Invalid Request Body",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{invalid json}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 400,
+			expectedBody:       `{"error":"Invalid request body"}`,
+		},
+		{
+			name:     "Other User",
+			callerID: "user456",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			mockError:          nil,
+			expectedStatusCode: 403,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			name:     "Database Error",
+			callerID: "user123",
+			request: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"userId": "user123",
+				},
+				Body: `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`,
+			},
+			mockError:          errors.New("database error"),
+			expectedStatusCode: 500,
+			expectedBody:       `{"error":"Failed to retrieve user"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryRepo := db.NewMemoryUserRepository()
+			if tc.existingUser != nil {
+				memoryRepo.PutUser(*tc.existingUser)
+			}
+
+			var userRepo db.UserRepository = memoryRepo
+			if tc.mockError != nil {
+				userRepo = &failingUserRepository{err: tc.mockError}
+			}
+
+			handlerFn := UpdateUserSettings(userRepo)
+
+			response, err := handlerFn(callerContext(tc.callerID), tc.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, response.StatusCode)
+			assert.Equal(t, tc.expectedETag, response.Headers["ETag"])
+
+			if tc.expectedStatusCode == 200 {
+				var updatedUser models.User
+				err := json.Unmarshal([]byte(response.Body), &updatedUser)
+				assert.NoError(t, err)
+				assert.NotEqual(t, tc.existingUser.UpdatedAt, updatedUser.UpdatedAt)
+
+				updatedUser.UpdatedAt = ""
+				assert.Equal(t, tc.expectedUser, &updatedUser)
+
+				storedUser, err := memoryRepo.GetUser("user123")
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedUser.Settings, storedUser.Settings)
+			} else {
+				assert.Equal(t, tc.expectedBody, response.Body)
+			}
+		})
+	}
+}
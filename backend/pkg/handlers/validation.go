@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type validationErrorResponse struct {
+	Error  string                  `json:"error"`
+	Fields models.ValidationErrors `json:"fields"`
+}
+
+func validationError(errs models.ValidationErrors) (events.APIGatewayProxyResponse, error) {
+	return utils.APIResponse(400, validationErrorResponse{
+		Error:  "Validation failed",
+		Fields: errs,
+	})
+}
@@ -0,0 +1,112 @@
+// Package ical writes all-day events as an RFC 5545 iCalendar feed that
+// calendar apps can subscribe to.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	productID = "-//fife//ESPP milestones//EN"
+
+	dateLayout  = "20060102"
+	stampLayout = "20060102T150405Z"
+
+	// maxLineOctets is the longest a content line may be before it is folded.
+	maxLineOctets = 75
+)
+
+// Event is an all-day event. UID must stay the same across feeds so that
+// calendar apps update the event instead of adding a copy.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write writes the calendar with every event stamped at stamp, which should
+// be the time the feed was generated.
+func Write(w io.Writer, calendar Calendar, stamp time.Time) error {
+	writer := bufio.NewWriter(w)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + productID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(calendar.Name),
+	}
+
+	for _, event := range calendar.Events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeText(event.UID),
+			"DTSTAMP:"+stamp.UTC().Format(stampLayout),
+			"DTSTART;VALUE=DATE:"+event.Date.Format(dateLayout),
+			"DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format(dateLayout),
+			"SUMMARY:"+escapeText(event.Summary),
+			"DESCRIPTION:"+escapeText(event.Description),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := writer.WriteString(foldLine(line)); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// escapeText escapes a TEXT value, which cannot hold raw commas, semicolons,
+// backslashes or line breaks.
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
+
+// foldLine ends the line with CRLF and splits it so no line is longer than
+// 75 octets. Continuation lines start with a space, which counts toward their
+// length, and multi-byte characters are never split.
+func foldLine(line string) string {
+	var folded strings.Builder
+
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+
+	folded.WriteString(line)
+	folded.WriteString("\r\n")
+
+	return folded.String()
+}
@@ -0,0 +1,96 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	calendar := Calendar{
+		Name: "fife ESPP",
+		Events: []Event{
+			{
+				UID:         "lot123-qualifying@fife",
+				Date:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Summary:     "NKE lot qualifying",
+				Description: "Shares: 10\nPrice: $85.00, discounted; see plan",
+			},
+		},
+	}
+
+	var body bytes.Buffer
+	err := Write(&body, calendar, time.Date(2024, 7, 1, 12, 30, 0, 0, time.FixedZone("EDT", -4*60*60)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//fife//ESPP milestones//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:fife ESPP",
+		"BEGIN:VEVENT",
+		"UID:lot123-qualifying@fife",
+		"DTSTAMP:20240701T163000Z",
+		"DTSTART;VALUE=DATE:20250101",
+		"DTEND;VALUE=DATE:20250102",
+		"SUMMARY:NKE lot qualifying",
+		`DESCRIPTION:Shares: 10\nPrice: $85.00\, discounted\; see plan`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), body.String())
+}
+
+func TestWriteNoEvents(t *testing.T) {
+	var body bytes.Buffer
+	err := Write(&body, Calendar{Name: "fife ESPP"}, time.Now())
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body.String(), "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(body.String(), "X-WR-CALNAME:fife ESPP\r\nEND:VCALENDAR\r\n"))
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne\nf`, escapeText("a\\b;c,d\r\ne\nf"))
+}
+
+func TestFoldLine(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{
+			name:     "short line",
+			line:     "SUMMARY:short",
+			expected: "SUMMARY:short\r\n",
+		},
+		{
+			name:     "exactly 75 octets",
+			line:     strings.Repeat("a", 75),
+			expected: strings.Repeat("a", 75) + "\r\n",
+		},
+		{
+			name:     "long line",
+			line:     strings.Repeat("a", 160),
+			expected: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n " + strings.Repeat("a", 11) + "\r\n",
+		},
+		{
+			name:     "multi-byte character at the fold",
+			line:     strings.Repeat("a", 74) + "é" + "b",
+			expected: strings.Repeat("a", 74) + "\r\n éb\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, foldLine(tc.line))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package jobs holds the work of scheduled Lambdas, which run on a timer
+// rather than in response to API requests.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/espp"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/notify"
+)
+
+// HoldingPeriodReport counts what one run of the holding period job did.
+// Users only counts users who turned the notifications on.
+type HoldingPeriodReport struct {
+	Users    int `json:"users"`
+	Notified int `json:"notified"`
+	Failed   int `json:"failed"`
+}
+
+// crossedMilestone is a milestone one of the user's lots reached since the
+// last run.
+type crossedMilestone struct {
+	espp.Milestone
+	lot    *models.EsppLot
+	unsold float64
+}
+
+// NotifyHoldingPeriods tells each user who asked for it which of their lots
+// with unsold shares became long-term or qualifying since the job last ran for
+// them, up to and including today. A user's checkpoint only moves forward once
+// they have been notified, so a failed user is retried on the next run.
+func NotifyHoldingPeriods(ctx context.Context, userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, notifier notify.Notifier, now time.Time) (HoldingPeriodReport, error) {
+	report := HoldingPeriodReport{}
+
+	users, err := userRepo.ListUsers()
+	if err != nil {
+		return report, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	year, month, day := now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	for _, user := range users {
+		preferences := user.Settings.Notifications
+		if preferences == nil || !preferences.HoldingPeriod || preferences.Email == "" {
+			continue
+		}
+		report.Users++
+
+		notified, err := notifyUserHoldingPeriods(ctx, user, userRepo, lotRepo, saleRepo, notifier, today)
+		if err != nil {
+			slog.Error("Failed to send holding period notification", slog.String("userId", user.UserID), slog.Any("error", err))
+			report.Failed++
+			continue
+		}
+		if notified {
+			report.Notified++
+		}
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("holding period notifications failed for %d of %d users", report.Failed, report.Users)
+	}
+
+	return report, nil
+}
+
+func notifyUserHoldingPeriods(ctx context.Context, user *models.User, userRepo db.UserRepository, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, notifier notify.Notifier, today time.Time) (bool, error) {
+	checkpoints := models.NotificationCheckpoints{}
+	if user.NotificationCheckpoints != nil {
+		checkpoints = *user.NotificationCheckpoints
+	}
+
+	since := lastCovered(checkpoints.HoldingPeriod, today)
+	if !since.Before(today) {
+		return false, nil
+	}
+
+	crossed, err := crossedMilestones(user.UserID, lotRepo, saleRepo, since, today)
+	if err != nil {
+		return false, err
+	}
+
+	if len(crossed) > 0 {
+		message := holdingPeriodMessage(user.Settings.Notifications.Email, crossed)
+		if err := notifier.Notify(ctx, message); err != nil {
+			return false, fmt.Errorf("failed to notify: %w", err)
+		}
+	}
+
+	checkpoints.HoldingPeriod = espp.FormatDate(today)
+	found, err := userRepo.SetNotificationCheckpoints(user.UserID, checkpoints)
+	if err != nil {
+		return false, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	// The user was deleted during the run, so there is no checkpoint to keep.
+	if !found {
+		slog.Info("Skipped checkpoint of deleted user", slog.String("userId", user.UserID))
+	}
+
+	return len(crossed) > 0, nil
+}
+
+// lastCovered returns the last day a previous run covered. Without a usable
+// checkpoint the run starts from yesterday, so turning notifications on does
+// not send reminders for milestones long past.
+func lastCovered(checkpoint string, today time.Time) time.Time {
+	if checkpoint != "" {
+		date, err := espp.ParseDate(checkpoint)
+		if err == nil {
+			return date
+		}
+		slog.Warn("Ignoring invalid holding period checkpoint", slog.String("checkpoint", checkpoint))
+	}
+
+	return today.AddDate(0, 0, -1)
+}
+
+// crossedMilestones returns the milestones after since and on or before today
+// of the user's lots that still have unsold shares, oldest first.
+func crossedMilestones(userID string, lotRepo db.EsppLotRepository, saleRepo db.EsppSaleRepository, since time.Time, today time.Time) ([]crossedMilestone, error) {
+	lots, err := lotRepo.GetEsppLotsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ESPP lots: %w", err)
+	}
+
+	crossed := []crossedMilestone{}
+	for _, lot := range lots {
+		milestones, err := espp.LotMilestones(lot)
+		if err != nil {
+			return nil, err
+		}
+
+		inWindow := []espp.Milestone{}
+		for _, milestone := range milestones {
+			if milestone.Date.After(since) && !milestone.Date.After(today) {
+				inWindow = append(inWindow, milestone)
+			}
+		}
+		if len(inWindow) == 0 {
+			continue
+		}
+
+		sales, err := saleRepo.GetEsppSalesByLotID(lot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve ESPP sales: %w", err)
+		}
+
+		unsold := lot.Shares - models.TotalSoldShares(sales)
+		if unsold <= 0 {
+			continue
+		}
+
+		for _, milestone := range inWindow {
+			crossed = append(crossed, crossedMilestone{Milestone: milestone, lot: lot, unsold: unsold})
+		}
+	}
+
+	sort.SliceStable(crossed, func(i, j int) bool {
+		return crossed[i].Date.Before(crossed[j].Date)
+	})
+
+	return crossed, nil
+}
+
+func holdingPeriodMessage(to string, crossed []crossedMilestone) notify.Message {
+	subject := "1 ESPP lot reached a holding period milestone"
+	if len(crossed) > 1 {
+		subject = fmt.Sprintf("%d ESPP lot holding period milestones reached", len(crossed))
+	}
+
+	var body strings.Builder
+	body.WriteString("Your ESPP lots reached these holding period milestones:\n")
+	for _, milestone := range crossed {
+		lot := milestone.lot
+		shares := strconv.FormatFloat(milestone.unsold, 'f', -1, 64)
+
+		switch milestone.Kind {
+		case espp.MilestoneLongTerm:
+			fmt.Fprintf(&body, "\n- %s lot bought on %s (%s unsold shares) became long-term on %s. Gains on a sale are now long-term, but the sale is still a disqualifying disposition.\n",
+				lot.DisplayName(), lot.PurchaseDate, shares, espp.FormatDate(milestone.Date))
+		case espp.MilestoneQualifying:
+			fmt.Fprintf(&body, "\n- %s lot bought on %s (%s unsold shares) became qualifying on %s. A sale is now a qualifying disposition.\n",
+				lot.DisplayName(), lot.PurchaseDate, shares, espp.FormatDate(milestone.Date))
+		}
+	}
+
+	return notify.Message{To: to, Subject: subject, Body: body.String()}
+}
@@ -0,0 +1,249 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	messages []notify.Message
+	failFor  string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, message notify.Message) error {
+	if message.To == n.failFor {
+		return errors.New("mail server down")
+	}
+	n.messages = append(n.messages, message)
+
+	return nil
+}
+
+type failingListUserRepository struct {
+	*db.MemoryUserRepository
+}
+
+func (r *failingListUserRepository) ListUsers() ([]*models.User, error) {
+	return nil, errors.New("database error")
+}
+
+// deletingUserRepository deletes every user right after listing them, as if
+// they deleted their account while the job was running.
+type deletingUserRepository struct {
+	*db.MemoryUserRepository
+}
+
+func (r *deletingUserRepository) ListUsers() ([]*models.User, error) {
+	users, err := r.MemoryUserRepository.ListUsers()
+	for _, user := range users {
+		_ = r.DeleteUser(user.UserID)
+	}
+
+	return users, err
+}
+
+type failingEsppLotRepository struct {
+	*db.MemoryEsppLotRepository
+	userID string
+}
+
+func (r *failingEsppLotRepository) GetEsppLotsByUserID(userID string) ([]*models.EsppLot, error) {
+	if userID == r.userID {
+		return nil, errors.New("database error")
+	}
+
+	return r.MemoryEsppLotRepository.GetEsppLotsByUserID(userID)
+}
+
+func notificationsOn(email string) models.UserSettings {
+	return models.UserSettings{Notifications: &models.NotificationSettings{Email: email, HoldingPeriod: true}}
+}
+
+func checkpoint(date string) *models.NotificationCheckpoints {
+	return &models.NotificationCheckpoints{HoldingPeriod: date}
+}
+
+func TestNotifyHoldingPeriods(t *testing.T) {
+	// Long-term on 2024-06-30 and qualifying on 2025-01-01.
+	nkeLot := models.EsppLot{ID: "nke", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", PurchasePrice: 85, Shares: 10, Ticker: "NKE"}
+	// Long-term and qualifying on 2024-06-30.
+	acmeLot := models.EsppLot{ID: "acme", UserID: "user123", GrantDate: "2022-06-30", PurchaseDate: "2023-06-30", PurchasePrice: 42.5, Shares: 5, Employer: "Acme"}
+	soldLot := models.EsppLot{ID: "sold", UserID: "user123", GrantDate: "2022-06-30", PurchaseDate: "2023-06-30", PurchasePrice: 42.5, Shares: 4}
+	sales := []models.EsppSale{
+		{ID: "sale1", LotID: "nke", UserID: "user123", Date: "2024-01-15", Price: 150, Shares: 2.5},
+		{ID: "sale2", LotID: "sold", UserID: "user123", Date: "2024-01-15", Price: 150, Shares: 4},
+	}
+
+	testCases := []struct {
+		name                string
+		now                 time.Time
+		users               []models.User
+		listError           bool
+		lotErrorFor         string
+		notifyErrorFor      string
+		expectedReport      HoldingPeriodReport
+		expectedError       string
+		expectedMessages    []notify.Message
+		expectedCheckpoints map[string]*models.NotificationCheckpoints
+	}{
+		{
+			name: "milestones since the checkpoint",
+			now:  time.Date(2024, 6, 30, 14, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-06-29")},
+			},
+			expectedReport: HoldingPeriodReport{Users: 1, Notified: 1},
+			expectedMessages: []notify.Message{
+				{
+					To:      "user@example.com",
+					Subject: "2 ESPP lot holding period milestones reached",
+					Body: "Your ESPP lots reached these holding period milestones:\n" +
+						"\n- NKE lot bought on 2023-06-30 (7.5 unsold shares) became long-term on 2024-06-30. Gains on a sale are now long-term, but the sale is still a disqualifying disposition.\n" +
+						"\n- Acme lot bought on 2023-06-30 (5 unsold shares) became qualifying on 2024-06-30. A sale is now a qualifying disposition.\n",
+				},
+			},
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2024-06-30")},
+		},
+		{
+			name: "catches up after missed runs",
+			now:  time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-12-31")},
+			},
+			expectedReport: HoldingPeriodReport{Users: 1, Notified: 1},
+			expectedMessages: []notify.Message{
+				{
+					To:      "user@example.com",
+					Subject: "1 ESPP lot reached a holding period milestone",
+					Body: "Your ESPP lots reached these holding period milestones:\n" +
+						"\n- NKE lot bought on 2023-06-30 (7.5 unsold shares) became qualifying on 2025-01-01. A sale is now a qualifying disposition.\n",
+				},
+			},
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2025-01-02")},
+		},
+		{
+			name: "first run only looks at today",
+			now:  time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com")},
+			},
+			expectedReport:      HoldingPeriodReport{Users: 1},
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2024-07-01")},
+		},
+		{
+			name: "already ran today",
+			now:  time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-06-30")},
+			},
+			expectedReport:      HoldingPeriodReport{Users: 1},
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2024-06-30")},
+		},
+		{
+			name: "notifications off",
+			now:  time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", NotificationCheckpoints: checkpoint("2024-06-29")},
+			},
+			expectedReport:      HoldingPeriodReport{},
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2024-06-29")},
+		},
+		{
+			name: "failed notification is retried next run",
+			now:  time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-06-29")},
+				{UserID: "user456", Settings: notificationsOn("other@example.com"), NotificationCheckpoints: checkpoint("2024-06-29")},
+			},
+			notifyErrorFor: "user@example.com",
+			expectedReport: HoldingPeriodReport{Users: 2, Failed: 1},
+			expectedError:  "holding period notifications failed for 1 of 2 users",
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{
+				"user123": checkpoint("2024-06-29"),
+				"user456": checkpoint("2024-06-30"),
+			},
+		},
+		{
+			name: "lot database error",
+			now:  time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			users: []models.User{
+				{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-06-29")},
+			},
+			lotErrorFor:         "user123",
+			expectedReport:      HoldingPeriodReport{Users: 1, Failed: 1},
+			expectedError:       "holding period notifications failed for 1 of 1 users",
+			expectedCheckpoints: map[string]*models.NotificationCheckpoints{"user123": checkpoint("2024-06-29")},
+		},
+		{
+			name:           "user database error",
+			now:            time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			listError:      true,
+			expectedReport: HoldingPeriodReport{},
+			expectedError:  "failed to list users: database error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memoryUserRepo := db.NewMemoryUserRepository()
+			for _, user := range tc.users {
+				memoryUserRepo.PutUser(user)
+			}
+			memoryLotRepo := db.NewMemoryEsppLotRepository()
+			for _, lot := range []models.EsppLot{nkeLot, acmeLot, soldLot} {
+				memoryLotRepo.PutEsppLot(lot)
+			}
+			memorySaleRepo := db.NewMemoryEsppSaleRepository()
+			for _, sale := range sales {
+				memorySaleRepo.PutEsppSale(sale)
+			}
+
+			var userRepo db.UserRepository = memoryUserRepo
+			if tc.listError {
+				userRepo = &failingListUserRepository{MemoryUserRepository: memoryUserRepo}
+			}
+			var lotRepo db.EsppLotRepository = memoryLotRepo
+			if tc.lotErrorFor != "" {
+				lotRepo = &failingEsppLotRepository{MemoryEsppLotRepository: memoryLotRepo, userID: tc.lotErrorFor}
+			}
+			notifier := &recordingNotifier{failFor: tc.notifyErrorFor}
+
+			report, err := NotifyHoldingPeriods(context.Background(), userRepo, lotRepo, memorySaleRepo, notifier, tc.now)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedReport, report)
+			assert.Equal(t, tc.expectedMessages, notifier.messages)
+
+			for userID, expected := range tc.expectedCheckpoints {
+				user, err := memoryUserRepo.GetUser(userID)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, user.NotificationCheckpoints)
+			}
+		})
+	}
+}
+
+func TestNotifyHoldingPeriodsDeletedUser(t *testing.T) {
+	userRepo := db.NewMemoryUserRepository()
+	userRepo.PutUser(models.User{UserID: "user123", Settings: notificationsOn("user@example.com"), NotificationCheckpoints: checkpoint("2024-06-29")})
+
+	report, err := NotifyHoldingPeriods(context.Background(), &deletingUserRepository{MemoryUserRepository: userRepo}, db.NewMemoryEsppLotRepository(), db.NewMemoryEsppSaleRepository(), &recordingNotifier{}, time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, HoldingPeriodReport{Users: 1}, report)
+
+	user, err := userRepo.GetUser("user123")
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+}
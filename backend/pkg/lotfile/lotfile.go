@@ -0,0 +1,290 @@
+// Package lotfile reads and writes ESPP lots in the flat column layout used by
+// spreadsheet uploads, one lot per CSV row or JSON object.
+package lotfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+const (
+	ColumnGrantDate       = "grantDate"
+	ColumnPurchaseDate    = "purchaseDate"
+	ColumnOfferStartPrice = "offerStartPrice"
+	ColumnOfferEndPrice   = "offerEndPrice"
+	ColumnPurchasePrice   = "purchasePrice"
+	ColumnShares          = "shares"
+	ColumnTicker          = "ticker"
+	ColumnEmployer        = "employer"
+	ColumnPlanID          = "planId"
+)
+
+// Columns lists the lot columns in the order they are written.
+var Columns = []string{
+	ColumnGrantDate,
+	ColumnPurchaseDate,
+	ColumnOfferStartPrice,
+	ColumnOfferEndPrice,
+	ColumnPurchasePrice,
+	ColumnShares,
+	ColumnTicker,
+	ColumnEmployer,
+	ColumnPlanID,
+}
+
+// optionalColumns may be left out of a CSV header, so files written before
+// lots had a ticker or plan still import.
+var optionalColumns = map[string]bool{
+	ColumnTicker:   true,
+	ColumnEmployer: true,
+	ColumnPlanID:   true,
+}
+
+// textColumns hold free text, which is guarded against running as a
+// spreadsheet formula when written to CSV.
+var textColumns = []string{ColumnTicker, ColumnEmployer, ColumnPlanID}
+
+// formulaPrefixes are the characters that make a spreadsheet read a cell as a
+// formula.
+const formulaPrefixes = "=+-@"
+
+var (
+	ErrEmptyFile    = errors.New("file has no header row")
+	ErrNotJSONArray = errors.New("expected a JSON array of lots")
+)
+
+// Row is one parsed lot. Errors holds the per-field problems found while
+// parsing or validating it; the row is only usable when Errors is empty.
+type Row struct {
+	Input  models.EsppLotInput
+	Errors models.ValidationErrors
+}
+
+func ParseCSV(data []byte) ([]Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	if err := checkColumns(header); err != nil {
+		return nil, err
+	}
+
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		values := map[string]string{}
+		for i, column := range header {
+			if i < len(record) {
+				values[column] = strings.TrimSpace(record[i])
+			}
+		}
+		for _, column := range textColumns {
+			if value, ok := values[column]; ok {
+				values[column] = unescapeFormula(value)
+			}
+		}
+
+		rows = append(rows, parseRow(values, nil))
+	}
+}
+
+// ParseJSON reads an array of objects keyed by column name. Numbers may be
+// given either as JSON numbers or as strings, matching what CSV uploads produce.
+func ParseJSON(data []byte) ([]Row, error) {
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, ErrNotJSONArray
+	}
+
+	rows := make([]Row, 0, len(records))
+	for _, record := range records {
+		values := map[string]string{}
+		var fieldErrs models.ValidationErrors
+
+		for _, column := range Columns {
+			raw, ok := record[column]
+			if !ok {
+				continue
+			}
+
+			value, err := jsonScalar(raw)
+			if err != nil {
+				fieldErrs = append(fieldErrs, models.FieldError{Field: column, Message: "must be a string or number"})
+				continue
+			}
+			values[column] = value
+		}
+
+		rows = append(rows, parseRow(values, fieldErrs))
+	}
+
+	return rows, nil
+}
+
+func checkColumns(header []string) error {
+	present := map[string]bool{}
+	for _, column := range header {
+		present[column] = true
+	}
+
+	for _, column := range Columns {
+		if !present[column] && !optionalColumns[column] {
+			return fmt.Errorf("missing column: %s", column)
+		}
+	}
+
+	return nil
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func jsonScalar(raw json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text), nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return "", err
+	}
+
+	return number.String(), nil
+}
+
+// parseRow converts the column values into a lot input. errs carries problems
+// the caller already found, and those columns are not validated again.
+func parseRow(values map[string]string, errs models.ValidationErrors) Row {
+	parseNumber := func(column string) float64 {
+		value := values[column]
+		if value == "" {
+			return 0
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, models.FieldError{Field: column, Message: "must be a number"})
+			return 0
+		}
+
+		return number
+	}
+
+	input := models.EsppLotInput{
+		GrantDate:       values[ColumnGrantDate],
+		PurchaseDate:    values[ColumnPurchaseDate],
+		OfferStartPrice: parseNumber(ColumnOfferStartPrice),
+		OfferEndPrice:   parseNumber(ColumnOfferEndPrice),
+		PurchasePrice:   parseNumber(ColumnPurchasePrice),
+		Shares:          parseNumber(ColumnShares),
+		Ticker:          values[ColumnTicker],
+		Employer:        values[ColumnEmployer],
+		PlanID:          values[ColumnPlanID],
+	}
+
+	// Values that failed to parse read as zero, so only report the validation
+	// errors for the columns that parsed cleanly.
+	failed := map[string]bool{}
+	for _, fieldErr := range errs {
+		failed[fieldErr.Field] = true
+	}
+	for _, fieldErr := range input.Validate() {
+		if !failed[fieldErr.Field] {
+			errs = append(errs, fieldErr)
+		}
+	}
+
+	return Row{Input: input, Errors: errs}
+}
+
+// WriteCSV writes the lots with a header row in Columns order. Text that a
+// spreadsheet would run as a formula is prefixed with a quote, which ParseCSV
+// strips again, so the output can be read back with ParseCSV.
+func WriteCSV(w io.Writer, lots []*models.EsppLot) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(Columns); err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		record := []string{
+			lot.GrantDate,
+			lot.PurchaseDate,
+			formatNumber(lot.OfferStartPrice),
+			formatNumber(lot.OfferEndPrice),
+			formatNumber(lot.PurchasePrice),
+			formatNumber(lot.Shares),
+			escapeFormula(lot.Ticker),
+			escapeFormula(lot.Employer),
+			escapeFormula(lot.PlanID),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// escapeFormula prefixes text that starts like a formula with a quote, so
+// spreadsheets show it as text instead of running it.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// unescapeFormula removes the quote escapeFormula adds.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+
+	return value
+}
@@ -0,0 +1,239 @@
+package lotfile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var validInput = models.EsppLotInput{
+	GrantDate:       "2023-01-01",
+	PurchaseDate:    "2023-06-30",
+	OfferStartPrice: 100.0,
+	OfferEndPrice:   120.0,
+	PurchasePrice:   85.0,
+	Shares:          10.5,
+}
+
+func TestParseCSV(t *testing.T) {
+	testCases := []struct {
+		name          string
+		data          string
+		expectedRows  []Row
+		expectedError string
+	}{
+		{
+			name: "valid rows",
+			data: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares\n" +
+				"2023-01-01, 2023-06-30, 100, 120, 85, 10.5\n" +
+				"\n",
+			expectedRows: []Row{{Input: validInput}},
+		},
+		{
+			name: "columns in any order with extras",
+			data: "\ufeffshares,id,purchasePrice,offerEndPrice,offerStartPrice,purchaseDate,grantDate\r\n" +
+				"10.5,upload-0,85,120,100,2023-06-30,2023-01-01\r\n",
+			expectedRows: []Row{{Input: validInput}},
+		},
+		{
+			name: "ticker and employer",
+			data: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares,ticker,employer\n" +
+				"2023-01-01,2023-06-30,100,120,85,10.5,nke,Nike\n",
+			expectedRows: []Row{{Input: func() models.EsppLotInput {
+				input := validInput
+				input.Ticker = "nke"
+				input.Employer = "Nike"
+				return input
+			}()}},
+		},
+		{
+			name: "invalid row",
+			data: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares\n" +
+				"banana,2023-06-30,abc,120,85,-1\n",
+			expectedRows: []Row{
+				{
+					Input: models.EsppLotInput{
+						GrantDate:     "banana",
+						PurchaseDate:  "2023-06-30",
+						OfferEndPrice: 120.0,
+						PurchasePrice: 85.0,
+						Shares:        -1,
+					},
+					Errors: models.ValidationErrors{
+						{Field: "offerStartPrice", Message: "must be a number"},
+						{Field: "grantDate", Message: "must be a date in YYYY-MM-DD format"},
+						{Field: "shares", Message: "must be greater than zero"},
+					},
+				},
+			},
+		},
+		{
+			name: "escaped formula",
+			data: "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares,employer\n" +
+				"2023-01-01,2023-06-30,100,120,85,10.5,'+Acme\n",
+			expectedRows: []Row{{Input: func() models.EsppLotInput {
+				input := validInput
+				input.Employer = "+Acme"
+				return input
+			}()}},
+		},
+		{
+			name:          "missing column",
+			data:          "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice\n",
+			expectedError: "missing column: shares",
+		},
+		{
+			name:          "empty file",
+			data:          "",
+			expectedError: ErrEmptyFile.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := ParseCSV([]byte(tc.data))
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRows, rows)
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	testCases := []struct {
+		name          string
+		data          string
+		expectedRows  []Row
+		expectedError bool
+	}{
+		{
+			name: "numbers and strings",
+			data: `[
+				{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10.5},
+				{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":"100","offerEndPrice":"120","purchasePrice":"85","shares":"10.5"}
+			]`,
+			expectedRows: []Row{{Input: validInput}, {Input: validInput}},
+		},
+		{
+			name: "exported lot",
+			data: `[{"id":"lot123","userId":"user123","grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10.5,"ticker":"NKE","planId":"plan123","version":2}]`,
+			expectedRows: []Row{{Input: func() models.EsppLotInput {
+				input := validInput
+				input.Ticker = "NKE"
+				input.PlanID = "plan123"
+				return input
+			}()}},
+		},
+		{
+			name: "wrong value type",
+			data: `[{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":[1]}]`,
+			expectedRows: []Row{
+				{
+					Input: models.EsppLotInput{
+						GrantDate:       "2023-01-01",
+						PurchaseDate:    "2023-06-30",
+						OfferStartPrice: 100.0,
+						OfferEndPrice:   120.0,
+						PurchasePrice:   85.0,
+					},
+					Errors: models.ValidationErrors{
+						{Field: "shares", Message: "must be a string or number"},
+					},
+				},
+			},
+		},
+		{
+			name:          "not an array",
+			data:          `{"grantDate":"2023-01-01"}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := ParseJSON([]byte(tc.data))
+
+			if tc.expectedError {
+				assert.Equal(t, ErrNotJSONArray, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRows, rows)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	lots := []*models.EsppLot{
+		{
+			ID:              "lot123",
+			UserID:          "user123",
+			GrantDate:       "2023-01-01",
+			PurchaseDate:    "2023-06-30",
+			OfferStartPrice: 100.0,
+			OfferEndPrice:   120.0,
+			PurchasePrice:   85.0,
+			Shares:          10.5,
+		},
+		{
+			ID:              "lot456",
+			UserID:          "user123",
+			GrantDate:       "2023-07-01",
+			PurchaseDate:    "2023-12-31",
+			OfferStartPrice: 120.25,
+			OfferEndPrice:   140.0,
+			PurchasePrice:   102.2125,
+			Shares:          96.5303,
+			Ticker:          "NKE",
+			Employer:        "Nike, Inc.",
+			PlanID:          "plan123",
+		},
+		{
+			ID:              "lot789",
+			UserID:          "user123",
+			GrantDate:       "2024-01-01",
+			PurchaseDate:    "2024-06-30",
+			OfferStartPrice: 100.0,
+			OfferEndPrice:   120.0,
+			PurchasePrice:   85.0,
+			Shares:          10.5,
+			Employer:        "=HYPERLINK(\"https://example.com\")",
+			PlanID:          "@plan",
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, lots)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares,ticker,employer,planId\n"+
+		"2023-01-01,2023-06-30,100,120,85,10.5,,,\n"+
+		"2023-07-01,2023-12-31,120.25,140,102.2125,96.5303,NKE,\"Nike, Inc.\",plan123\n"+
+		"2024-01-01,2024-06-30,100,120,85,10.5,,\"'=HYPERLINK(\"\"https://example.com\"\")\",'@plan\n", buf.String())
+
+	rows, err := ParseCSV(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, rows, len(lots))
+	for i, row := range rows {
+		assert.Empty(t, row.Errors)
+		assert.Equal(t, models.EsppLotInput{
+			GrantDate:       lots[i].GrantDate,
+			PurchaseDate:    lots[i].PurchaseDate,
+			OfferStartPrice: lots[i].OfferStartPrice,
+			OfferEndPrice:   lots[i].OfferEndPrice,
+			PurchasePrice:   lots[i].PurchasePrice,
+			Shares:          lots[i].Shares,
+			Ticker:          lots[i].Ticker,
+			Employer:        lots[i].Employer,
+			PlanID:          lots[i].PlanID,
+		}, row.Input)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+// ArchiveVersion is the version of the UserArchive layout. It changes only
+// when a field is renamed or removed, or its meaning changes, so older
+// archives are never read as something they are not. Adding a resource to the
+// archive keeps the version.
+const ArchiveVersion = 1
+
+// ArchiveMode says how a restore treats data that is already stored.
+type ArchiveMode string
+
+const (
+	// ArchiveModeMerge writes the archive over what is stored, replacing items
+	// with the same ID and keeping the rest.
+	ArchiveModeMerge ArchiveMode = "merge"
+	// ArchiveModeReplace removes the user's stored data before writing the
+	// archive, so afterwards they own exactly what it holds.
+	ArchiveModeReplace ArchiveMode = "replace"
+)
+
+// UserArchive is everything stored for a user, for backups and privacy
+// requests. User is nil when the user never saved their settings. Secrets
+// like the calendar token hash are left out.
+type UserArchive struct {
+	Version    int         `json:"version"`
+	ExportedAt string      `json:"exportedAt"`
+	User       *User       `json:"user"`
+	EsppPlans  []*EsppPlan `json:"esppPlans"`
+	EsppLots   []*EsppLot  `json:"esppLots"`
+	EsppSales  []*EsppSale `json:"esppSales"`
+}
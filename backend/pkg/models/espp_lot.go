@@ -1,6 +1,8 @@
 package models
 
 import (
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/ljhurst/fife/pkg/utils"
 )
@@ -13,6 +15,9 @@ type EsppLotInput struct {
 	OfferEndPrice   float64 `json:"offerEndPrice" dynamodbav:"offerEndPrice"`
 	PurchasePrice   float64 `json:"purchasePrice" dynamodbav:"purchasePrice"`
 	Shares          float64 `json:"shares" dynamodbav:"shares"`
+	Ticker          string  `json:"ticker,omitempty" dynamodbav:"ticker,omitempty"`
+	Employer        string  `json:"employer,omitempty" dynamodbav:"employer,omitempty"`
+	PlanID          string  `json:"planId,omitempty" dynamodbav:"planId,omitempty"`
 }
 
 type EsppLot struct {
@@ -24,8 +29,14 @@ type EsppLot struct {
 	OfferEndPrice   float64 `json:"offerEndPrice" dynamodbav:"offerEndPrice"`
 	PurchasePrice   float64 `json:"purchasePrice" dynamodbav:"purchasePrice"`
 	Shares          float64 `json:"shares" dynamodbav:"shares"`
-	CreatedAt       string  `json:"createdAt" dynamodbav:"createdAt"`
-	UpdatedAt       string  `json:"updatedAt" dynamodbav:"updatedAt"`
+	Ticker          string  `json:"ticker,omitempty" dynamodbav:"ticker,omitempty"`
+	Employer        string  `json:"employer,omitempty" dynamodbav:"employer,omitempty"`
+	PlanID          string  `json:"planId,omitempty" dynamodbav:"planId,omitempty"`
+	// Version goes up by one with every update, so clients can tell when their
+	// copy is stale. Lots saved before it was added read as 0.
+	Version   int64  `json:"version" dynamodbav:"version"`
+	CreatedAt string `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt string `json:"updatedAt" dynamodbav:"updatedAt"`
 }
 
 func NewEsppLot(input EsppLotInput) *EsppLot {
@@ -40,7 +51,105 @@ func NewEsppLot(input EsppLotInput) *EsppLot {
 		OfferEndPrice:   input.OfferEndPrice,
 		PurchasePrice:   input.PurchasePrice,
 		Shares:          input.Shares,
+		Ticker:          NormalizeTicker(input.Ticker),
+		Employer:        strings.TrimSpace(input.Employer),
+		PlanID:          input.PlanID,
+		Version:         1,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 }
+
+// EsppLotUpdate holds the fields of a partial lot update. Nil fields are left
+// unchanged.
+type EsppLotUpdate struct {
+	GrantDate       *string  `json:"grantDate,omitempty"`
+	PurchaseDate    *string  `json:"purchaseDate,omitempty"`
+	OfferStartPrice *float64 `json:"offerStartPrice,omitempty"`
+	OfferEndPrice   *float64 `json:"offerEndPrice,omitempty"`
+	PurchasePrice   *float64 `json:"purchasePrice,omitempty"`
+	Shares          *float64 `json:"shares,omitempty"`
+	Ticker          *string  `json:"ticker,omitempty"`
+	Employer        *string  `json:"employer,omitempty"`
+	PlanID          *string  `json:"planId,omitempty"`
+}
+
+func (u EsppLotUpdate) IsEmpty() bool {
+	return u.GrantDate == nil &&
+		u.PurchaseDate == nil &&
+		u.OfferStartPrice == nil &&
+		u.OfferEndPrice == nil &&
+		u.PurchasePrice == nil &&
+		u.Shares == nil &&
+		u.Ticker == nil &&
+		u.Employer == nil &&
+		u.PlanID == nil
+}
+
+// Apply copies the set fields of the update onto the lot.
+func (u EsppLotUpdate) Apply(lot *EsppLot) {
+	if u.GrantDate != nil {
+		lot.GrantDate = *u.GrantDate
+	}
+	if u.PurchaseDate != nil {
+		lot.PurchaseDate = *u.PurchaseDate
+	}
+	if u.OfferStartPrice != nil {
+		lot.OfferStartPrice = *u.OfferStartPrice
+	}
+	if u.OfferEndPrice != nil {
+		lot.OfferEndPrice = *u.OfferEndPrice
+	}
+	if u.PurchasePrice != nil {
+		lot.PurchasePrice = *u.PurchasePrice
+	}
+	if u.Shares != nil {
+		lot.Shares = *u.Shares
+	}
+	if u.Ticker != nil {
+		lot.Ticker = NormalizeTicker(*u.Ticker)
+	}
+	if u.Employer != nil {
+		lot.Employer = strings.TrimSpace(*u.Employer)
+	}
+	if u.PlanID != nil {
+		lot.PlanID = *u.PlanID
+	}
+}
+
+// DisplayName names the lot's stock in messages to the user: the ticker, else
+// the employer, else just "ESPP".
+func (l *EsppLot) DisplayName() string {
+	if l.Ticker != "" {
+		return NormalizeTicker(l.Ticker)
+	}
+	if l.Employer != "" {
+		return l.Employer
+	}
+
+	return "ESPP"
+}
+
+// NormalizeTicker upper-cases a ticker symbol so lookups and filters match
+// however it was typed.
+func NormalizeTicker(ticker string) string {
+	return strings.ToUpper(strings.TrimSpace(ticker))
+}
+
+// EsppLotListOptions narrows and pages a user's lot listing. Empty date bounds
+// are open-ended, an empty ticker matches every lot, and an empty cursor starts
+// from the first page.
+type EsppLotListOptions struct {
+	UserID           string
+	Limit            int
+	Cursor           string
+	PurchaseDateFrom string
+	PurchaseDateTo   string
+	Ticker           string
+	Descending       bool
+}
+
+type EsppLotPage struct {
+	Items      []*EsppLot `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
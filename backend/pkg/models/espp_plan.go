@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+// EsppResetRule says what happens to an offering when the price falls below
+// the offering start price at a purchase.
+type EsppResetRule string
+
+const (
+	// EsppResetRuleNone keeps the offering start price for the whole offering.
+	EsppResetRuleNone EsppResetRule = "none"
+	// EsppResetRuleLowerPrice restarts the offering at the lower price, so
+	// later purchases look back to the reset price.
+	EsppResetRuleLowerPrice EsppResetRule = "lower_price"
+)
+
+// EsppPlanInput describes the rules of an employer's ESPP. DiscountPercent is
+// in percent like the other settings, and a MaxSharesPerPurchase of zero means
+// the plan has no share cap.
+type EsppPlanInput struct {
+	UserID               string        `json:"userId" dynamodbav:"userId"`
+	Name                 string        `json:"name" dynamodbav:"name"`
+	Employer             string        `json:"employer,omitempty" dynamodbav:"employer,omitempty"`
+	DiscountPercent      float64       `json:"discountPercent" dynamodbav:"discountPercent"`
+	Lookback             bool          `json:"lookback" dynamodbav:"lookback"`
+	OfferingPeriodMonths int           `json:"offeringPeriodMonths" dynamodbav:"offeringPeriodMonths"`
+	PurchasePeriodMonths int           `json:"purchasePeriodMonths" dynamodbav:"purchasePeriodMonths"`
+	ResetRule            EsppResetRule `json:"resetRule" dynamodbav:"resetRule"`
+	MaxSharesPerPurchase float64       `json:"maxSharesPerPurchase,omitempty" dynamodbav:"maxSharesPerPurchase,omitempty"`
+}
+
+type EsppPlan struct {
+	ID                   string        `json:"id" dynamodbav:"id"`
+	UserID               string        `json:"userId" dynamodbav:"userId"`
+	Name                 string        `json:"name" dynamodbav:"name"`
+	Employer             string        `json:"employer,omitempty" dynamodbav:"employer,omitempty"`
+	DiscountPercent      float64       `json:"discountPercent" dynamodbav:"discountPercent"`
+	Lookback             bool          `json:"lookback" dynamodbav:"lookback"`
+	OfferingPeriodMonths int           `json:"offeringPeriodMonths" dynamodbav:"offeringPeriodMonths"`
+	PurchasePeriodMonths int           `json:"purchasePeriodMonths" dynamodbav:"purchasePeriodMonths"`
+	ResetRule            EsppResetRule `json:"resetRule" dynamodbav:"resetRule"`
+	MaxSharesPerPurchase float64       `json:"maxSharesPerPurchase,omitempty" dynamodbav:"maxSharesPerPurchase,omitempty"`
+	CreatedAt            string        `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt            string        `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+func NewEsppPlan(input EsppPlanInput) *EsppPlan {
+	now := utils.GetCurrentTimeUTC()
+
+	plan := &EsppPlan{
+		ID:        uuid.New().String(),
+		UserID:    input.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	input.Apply(plan)
+
+	return plan
+}
+
+// Apply replaces the rules of the plan with the input, leaving its identity
+// and owner alone.
+func (i EsppPlanInput) Apply(plan *EsppPlan) {
+	plan.Name = strings.TrimSpace(i.Name)
+	plan.Employer = strings.TrimSpace(i.Employer)
+	plan.DiscountPercent = i.DiscountPercent
+	plan.Lookback = i.Lookback
+	plan.OfferingPeriodMonths = i.OfferingPeriodMonths
+	plan.PurchasePeriodMonths = i.PurchasePeriodMonths
+	plan.ResetRule = i.ResetRule
+	if plan.ResetRule == "" {
+		plan.ResetRule = EsppResetRuleNone
+	}
+	plan.MaxSharesPerPurchase = i.MaxSharesPerPurchase
+}
+
+// MatchesEmployer reports whether the plan was set up for the employer,
+// ignoring case and surrounding spaces.
+func (p EsppPlan) MatchesEmployer(employer string) bool {
+	employer = strings.TrimSpace(employer)
+	return p.Employer != "" && strings.EqualFold(p.Employer, employer)
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/ljhurst/fife/pkg/utils"
+)
+
+type EsppSaleInput struct {
+	LotID  string  `json:"lotId" dynamodbav:"lotId"`
+	UserID string  `json:"userId" dynamodbav:"userId"`
+	Date   string  `json:"date" dynamodbav:"date"`
+	Price  float64 `json:"price" dynamodbav:"price"`
+	Shares float64 `json:"shares" dynamodbav:"shares"`
+}
+
+type EsppSale struct {
+	ID        string  `json:"id" dynamodbav:"id"`
+	LotID     string  `json:"lotId" dynamodbav:"lotId"`
+	UserID    string  `json:"userId" dynamodbav:"userId"`
+	Date      string  `json:"date" dynamodbav:"date"`
+	Price     float64 `json:"price" dynamodbav:"price"`
+	Shares    float64 `json:"shares" dynamodbav:"shares"`
+	CreatedAt string  `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt string  `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// EsppSellPlanInput asks which lots to sell to reach a target of either Shares
+// or Cash in gross proceeds, selling at Price on Date. Ticker limits the lots
+// to one stock.
+type EsppSellPlanInput struct {
+	Shares float64 `json:"shares,omitempty"`
+	Cash   float64 `json:"cash,omitempty"`
+	Date   string  `json:"date"`
+	Price  float64 `json:"price"`
+	Ticker string  `json:"ticker,omitempty"`
+}
+
+func NewEsppSale(input EsppSaleInput) *EsppSale {
+	now := utils.GetCurrentTimeUTC()
+
+	return &EsppSale{
+		ID:        uuid.New().String(),
+		LotID:     input.LotID,
+		UserID:    input.UserID,
+		Date:      input.Date,
+		Price:     input.Price,
+		Shares:    input.Shares,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TotalSoldShares(sales []*EsppSale) float64 {
+	total := 0.0
+	for _, sale := range sales {
+		total += sale.Shares
+	}
+
+	return total
+}
@@ -0,0 +1,8 @@
+package models
+
+// ClosingPrice is the closing price of a ticker on one trading day.
+type ClosingPrice struct {
+	Ticker string  `json:"ticker" dynamodbav:"ticker"`
+	Date   string  `json:"date" dynamodbav:"date"`
+	Close  float64 `json:"close" dynamodbav:"close"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Quote is the most recent known price of a ticker. Date is the trading day
+// the price is from and UpdatedAt is when it was fetched.
+type Quote struct {
+	Ticker    string  `json:"ticker" dynamodbav:"ticker"`
+	Price     float64 `json:"price" dynamodbav:"price"`
+	Date      string  `json:"date" dynamodbav:"date"`
+	UpdatedAt string  `json:"updatedAt" dynamodbav:"updatedAt"`
+}
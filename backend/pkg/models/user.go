@@ -1,17 +1,170 @@
 package models
 
+import "github.com/ljhurst/fife/pkg/utils"
+
+const (
+	PayFrequencyWeekly      = "weekly"
+	PayFrequencyBiweekly    = "biweekly"
+	PayFrequencySemimonthly = "semimonthly"
+	PayFrequencyMonthly     = "monthly"
+)
+
+// PaySchedule pins a pay frequency to one known payday. Weekly and biweekly
+// paydays repeat from AnchorDate; monthly and semimonthly paydays fall on the
+// same day of the month as AnchorDate.
+type PaySchedule struct {
+	Frequency  string `json:"frequency" dynamodbav:"frequency"`
+	AnchorDate string `json:"anchorDate" dynamodbav:"anchorDate"`
+}
+
 type UserFinanceSettings struct {
-	AnnualSalary     float64 `json:"annualSalary" dynamodbav:"annualSalary"`
-	PaychecksPerYear int     `json:"paychecksPerYear" dynamodbav:"paychecksPerYear"`
+	AnnualSalary     float64      `json:"annualSalary" dynamodbav:"annualSalary"`
+	PaychecksPerYear int          `json:"paychecksPerYear" dynamodbav:"paychecksPerYear"`
+	PaySchedule      *PaySchedule `json:"paySchedule,omitempty" dynamodbav:"paySchedule,omitempty"`
+}
+
+// UserRetirementSettings describes the user's 401k plan. Match and Roth values
+// are percents: a 50% match on contributions up to 6% of pay is
+// EmployerMatchRate 50 and EmployerMatchLimit 6.
+type UserRetirementSettings struct {
+	BirthDate          string  `json:"birthDate,omitempty" dynamodbav:"birthDate,omitempty"`
+	EmployerMatchRate  float64 `json:"employerMatchRate" dynamodbav:"employerMatchRate"`
+	EmployerMatchLimit float64 `json:"employerMatchLimit" dynamodbav:"employerMatchLimit"`
+	RothPercent        float64 `json:"rothPercent" dynamodbav:"rothPercent"`
+}
+
+const (
+	FilingStatusSingle                  = "single"
+	FilingStatusMarriedFilingJointly    = "married_filing_jointly"
+	FilingStatusMarriedFilingSeparately = "married_filing_separately"
+	FilingStatusHeadOfHousehold         = "head_of_household"
+)
+
+// TaxProfile holds the rates used for the user's tax estimates. Rates are
+// percents, so a 24% bracket is OrdinaryIncomeRate 24. NIIT adds the 3.8% net
+// investment income tax to capital gains.
+type TaxProfile struct {
+	FilingStatus             string  `json:"filingStatus" dynamodbav:"filingStatus"`
+	OrdinaryIncomeRate       float64 `json:"ordinaryIncomeRate" dynamodbav:"ordinaryIncomeRate"`
+	LongTermCapitalGainsRate float64 `json:"longTermCapitalGainsRate" dynamodbav:"longTermCapitalGainsRate"`
+	StateRate                float64 `json:"stateRate" dynamodbav:"stateRate"`
+	NIIT                     bool    `json:"niit" dynamodbav:"niit"`
+}
+
+// NotificationSettings chooses the reminders the user receives and the address
+// they are sent to. HoldingPeriod sends a reminder when a lot becomes
+// long-term or qualifying.
+type NotificationSettings struct {
+	Email         string `json:"email" dynamodbav:"email"`
+	HoldingPeriod bool   `json:"holdingPeriod" dynamodbav:"holdingPeriod"`
 }
 
 type UserSettings struct {
-	Finance UserFinanceSettings `json:"finance" dynamodbav:"finance"`
+	Finance       UserFinanceSettings    `json:"finance" dynamodbav:"finance"`
+	Retirement    UserRetirementSettings `json:"retirement" dynamodbav:"retirement"`
+	TaxProfile    *TaxProfile            `json:"taxProfile,omitempty" dynamodbav:"taxProfile,omitempty"`
+	Notifications *NotificationSettings  `json:"notifications,omitempty" dynamodbav:"notifications,omitempty"`
+}
+
+// UserSettingsUpdate holds the settings sent with a user update. Each part is
+// replaced when it is sent and keeps its stored value when it is left out, so
+// a client that only knows some of the settings does not clear the rest.
+type UserSettingsUpdate struct {
+	Finance       *UserFinanceSettingsUpdate `json:"finance,omitempty"`
+	Retirement    *UserRetirementSettings    `json:"retirement,omitempty"`
+	TaxProfile    *TaxProfile                `json:"taxProfile,omitempty"`
+	Notifications *NotificationSettings      `json:"notifications,omitempty"`
+}
+
+type UserFinanceSettingsUpdate struct {
+	AnnualSalary     *float64     `json:"annualSalary,omitempty"`
+	PaychecksPerYear *int         `json:"paychecksPerYear,omitempty"`
+	PaySchedule      *PaySchedule `json:"paySchedule,omitempty"`
+}
+
+func (u UserSettingsUpdate) IsEmpty() bool {
+	return u.Finance == nil &&
+		u.Retirement == nil &&
+		u.TaxProfile == nil &&
+		u.Notifications == nil
+}
+
+// Apply copies the sent parts of the update onto the settings.
+func (u UserSettingsUpdate) Apply(settings *UserSettings) {
+	if finance := u.Finance; finance != nil {
+		if finance.AnnualSalary != nil {
+			settings.Finance.AnnualSalary = *finance.AnnualSalary
+		}
+		if finance.PaychecksPerYear != nil {
+			settings.Finance.PaychecksPerYear = *finance.PaychecksPerYear
+		}
+		if finance.PaySchedule != nil {
+			settings.Finance.PaySchedule = finance.PaySchedule
+		}
+	}
+	if u.Retirement != nil {
+		settings.Retirement = *u.Retirement
+	}
+	if u.TaxProfile != nil {
+		settings.TaxProfile = u.TaxProfile
+	}
+	if u.Notifications != nil {
+		settings.Notifications = u.Notifications
+	}
+}
+
+// NotificationCheckpoints record the last date each scheduled notification
+// job covered for the user, so the next run only looks at later dates. They
+// are kept apart from UserSettings so saving settings never resets them.
+type NotificationCheckpoints struct {
+	HoldingPeriod string `json:"holdingPeriod,omitempty" dynamodbav:"holdingPeriod,omitempty"`
 }
 
 type User struct {
-	UserID    string       `json:"userId" dynamodbav:"userId"`
-	Settings  UserSettings `json:"settings" dynamodbav:"settings"`
-	CreatedAt string       `json:"createdAt" dynamodbav:"createdAt"`
-	UpdatedAt string       `json:"updatedAt" dynamodbav:"updatedAt"`
+	UserID   string       `json:"userId" dynamodbav:"userId"`
+	Settings UserSettings `json:"settings" dynamodbav:"settings"`
+	// CalendarTokenHash is the SHA-256 of the token that unlocks the user's
+	// calendar feed. The token itself is only shown once, when it is created.
+	CalendarTokenHash       string                   `json:"-" dynamodbav:"calendarTokenHash,omitempty"`
+	NotificationCheckpoints *NotificationCheckpoints `json:"-" dynamodbav:"notificationCheckpoints,omitempty"`
+	// Version goes up by one with every change to the settings, so clients can
+	// tell when their copy is stale. Users saved before it was added read as 0.
+	Version   int64  `json:"version" dynamodbav:"version"`
+	CreatedAt string `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt string `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// Retirement401kPlanInput asks for a contribution plan over the rest of a
+// year. Zero values fall back to the current year, every paycheck in the year,
+// and the IRS limit.
+type Retirement401kPlanInput struct {
+	Year               int     `json:"year"`
+	ContributionsSoFar float64 `json:"contributionsSoFar"`
+	PaychecksRemaining int     `json:"paychecksRemaining"`
+	ContributionLimit  float64 `json:"contributionLimit"`
+}
+
+// DefaultPaychecksPerYear assumes a biweekly paycheck until the user sets
+// their own schedule.
+const DefaultPaychecksPerYear = 26
+
+// NewUser starts a user with default settings. The email, when known, is where
+// notifications go once the user turns them on.
+func NewUser(userID string, email string) *User {
+	now := utils.GetCurrentTimeUTC()
+
+	user := &User{
+		UserID: userID,
+		Settings: UserSettings{
+			Finance: UserFinanceSettings{PaychecksPerYear: DefaultPaychecksPerYear},
+		},
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if email != "" {
+		user.Settings.Notifications = &NotificationSettings{Email: email}
+	}
+
+	return user
 }
@@ -0,0 +1,443 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format of every calendar date stored on lots and sales.
+const DateLayout = "2006-01-02"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every field that failed validation so callers can
+// report them all at once.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationErrors) add(field string, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// addNested records errs from validating a nested value under its field, like
+// user.settings.finance.annualSalary.
+func (e *ValidationErrors) addNested(parent string, errs ValidationErrors) {
+	for _, fieldErr := range errs {
+		e.add(parent+"."+fieldErr.Field, fieldErr.Message)
+	}
+}
+
+// checkDate records an error when value is not a YYYY-MM-DD date and returns
+// the parsed date otherwise.
+func (e *ValidationErrors) checkDate(field string, value string) (time.Time, bool) {
+	if value == "" {
+		e.add(field, "is required")
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		e.add(field, "must be a date in YYYY-MM-DD format")
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+func (e *ValidationErrors) checkPositive(field string, value float64) bool {
+	if value <= 0 {
+		e.add(field, "must be greater than zero")
+		return false
+	}
+
+	return true
+}
+
+func (e *ValidationErrors) checkPercent(field string, value float64) bool {
+	if value < 0 || value > 100 {
+		e.add(field, "must be between 0 and 100")
+		return false
+	}
+
+	return true
+}
+
+// tickerPattern matches exchange symbols such as NKE, BRK.B, and RDS-A.
+var tickerPattern = regexp.MustCompile(`^[A-Z][A-Z0-9.\-]{0,9}$`)
+
+// IsValidTicker reports whether ticker is a well-formed stock symbol once
+// normalized.
+func IsValidTicker(ticker string) bool {
+	return tickerPattern.MatchString(NormalizeTicker(ticker))
+}
+
+const maxEmployerLength = 100
+
+// checkSecurity validates the optional ticker and employer of a lot.
+func (e *ValidationErrors) checkSecurity(ticker string, employer string) {
+	if NormalizeTicker(ticker) != "" && !IsValidTicker(ticker) {
+		e.add("ticker", "must be a stock symbol of up to 10 letters, digits, dots, or dashes")
+	}
+
+	if len(strings.TrimSpace(employer)) > maxEmployerLength {
+		e.add("employer", fmt.Sprintf("must be at most %d characters", maxEmployerLength))
+	}
+}
+
+func (i EsppLotInput) Validate() ValidationErrors {
+	errs := validateEsppLotFields(i.GrantDate, i.PurchaseDate, i.OfferStartPrice, i.OfferEndPrice, i.PurchasePrice, i.Shares)
+	errs.checkSecurity(i.Ticker, i.Employer)
+
+	return errs
+}
+
+// Validate checks the lot that results from applying the update, so rules
+// spanning several fields still hold after a partial update.
+func (u EsppLotUpdate) Validate(lot EsppLot) ValidationErrors {
+	u.Apply(&lot)
+
+	errs := validateEsppLotFields(lot.GrantDate, lot.PurchaseDate, lot.OfferStartPrice, lot.OfferEndPrice, lot.PurchasePrice, lot.Shares)
+	errs.checkSecurity(lot.Ticker, lot.Employer)
+
+	return errs
+}
+
+func validateEsppLotFields(grantDate string, purchaseDate string, offerStartPrice float64, offerEndPrice float64, purchasePrice float64, shares float64) ValidationErrors {
+	var errs ValidationErrors
+
+	grant, grantOK := errs.checkDate("grantDate", grantDate)
+	purchase, purchaseOK := errs.checkDate("purchaseDate", purchaseDate)
+	if grantOK && purchaseOK && purchase.Before(grant) {
+		errs.add("purchaseDate", "must not be before grantDate")
+	}
+
+	errs.checkPositive("offerStartPrice", offerStartPrice)
+	endOK := errs.checkPositive("offerEndPrice", offerEndPrice)
+	if errs.checkPositive("purchasePrice", purchasePrice) && endOK && purchasePrice > offerEndPrice {
+		errs.add("purchasePrice", "must not be greater than offerEndPrice")
+	}
+
+	errs.checkPositive("shares", shares)
+
+	return errs
+}
+
+// Section 423 caps the discount at 15% and offerings at 27 months.
+const (
+	maxEsppDiscountPercent      = 15
+	maxEsppOfferingPeriodMonths = 27
+	maxEsppPlanNameLength       = 100
+)
+
+func (i EsppPlanInput) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	name := strings.TrimSpace(i.Name)
+	if name == "" {
+		errs.add("name", "is required")
+	} else if len(name) > maxEsppPlanNameLength {
+		errs.add("name", fmt.Sprintf("must be at most %d characters", maxEsppPlanNameLength))
+	}
+
+	if len(strings.TrimSpace(i.Employer)) > maxEmployerLength {
+		errs.add("employer", fmt.Sprintf("must be at most %d characters", maxEmployerLength))
+	}
+
+	if i.DiscountPercent < 0 || i.DiscountPercent > maxEsppDiscountPercent {
+		errs.add("discountPercent", fmt.Sprintf("must be between 0 and %d", maxEsppDiscountPercent))
+	}
+
+	offeringOK := true
+	if i.OfferingPeriodMonths < 1 || i.OfferingPeriodMonths > maxEsppOfferingPeriodMonths {
+		errs.add("offeringPeriodMonths", fmt.Sprintf("must be between 1 and %d", maxEsppOfferingPeriodMonths))
+		offeringOK = false
+	}
+
+	if i.PurchasePeriodMonths < 1 {
+		errs.add("purchasePeriodMonths", "must be greater than zero")
+	} else if offeringOK && i.PurchasePeriodMonths > i.OfferingPeriodMonths {
+		errs.add("purchasePeriodMonths", "must not be greater than offeringPeriodMonths")
+	}
+
+	switch i.ResetRule {
+	case "", EsppResetRuleNone, EsppResetRuleLowerPrice:
+	default:
+		errs.add("resetRule", "must be one of none, lower_price")
+	}
+
+	if i.MaxSharesPerPurchase < 0 {
+		errs.add("maxSharesPerPurchase", "must not be negative")
+	}
+
+	return errs
+}
+
+// ValidateLot checks a lot against the plan it was bought under: the purchase
+// must fall within one offering of the grant date and stay under the share cap.
+func (p EsppPlan) ValidateLot(lot EsppLot) ValidationErrors {
+	var errs ValidationErrors
+
+	grant, grantErr := time.Parse(DateLayout, lot.GrantDate)
+	purchase, purchaseErr := time.Parse(DateLayout, lot.PurchaseDate)
+	if grantErr == nil && purchaseErr == nil && p.OfferingPeriodMonths > 0 &&
+		purchase.After(grant.AddDate(0, p.OfferingPeriodMonths, 0)) {
+		errs.add("purchaseDate", fmt.Sprintf("must be within the plan's %d month offering period", p.OfferingPeriodMonths))
+	}
+
+	if p.MaxSharesPerPurchase > 0 && lot.Shares > p.MaxSharesPerPurchase {
+		errs.add("shares", fmt.Sprintf("must not be greater than the plan's cap of %g shares", p.MaxSharesPerPurchase))
+	}
+
+	return errs
+}
+
+// ValidateSale checks a sale against the lot it sells from: shares cannot be
+// sold before they were bought.
+func (l EsppLot) ValidateSale(sale EsppSaleInput) ValidationErrors {
+	var errs ValidationErrors
+
+	date, dateErr := time.Parse(DateLayout, sale.Date)
+	purchase, purchaseErr := time.Parse(DateLayout, l.PurchaseDate)
+	if dateErr == nil && purchaseErr == nil && date.Before(purchase) {
+		errs.add("date", "must not be before the lot's purchaseDate")
+	}
+
+	return errs
+}
+
+func (i EsppSaleInput) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	errs.checkDate("date", i.Date)
+	errs.checkPositive("price", i.Price)
+	errs.checkPositive("shares", i.Shares)
+
+	return errs
+}
+
+func (i EsppSellPlanInput) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	switch {
+	case i.Shares == 0 && i.Cash == 0:
+		errs.add("shares", "either shares or cash is required")
+	case i.Shares != 0 && i.Cash != 0:
+		errs.add("cash", "must not be set along with shares")
+	case i.Shares != 0:
+		errs.checkPositive("shares", i.Shares)
+	default:
+		errs.checkPositive("cash", i.Cash)
+	}
+
+	errs.checkDate("date", i.Date)
+	errs.checkPositive("price", i.Price)
+
+	errs.checkSecurity(i.Ticker, "")
+
+	return errs
+}
+
+func (s UserSettings) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if s.Finance.AnnualSalary < 0 {
+		errs.add("finance.annualSalary", "must not be negative")
+	}
+
+	if s.Finance.PaychecksPerYear <= 0 {
+		errs.add("finance.paychecksPerYear", "must be greater than zero")
+	}
+
+	if schedule := s.Finance.PaySchedule; schedule != nil {
+		switch schedule.Frequency {
+		case PayFrequencyWeekly, PayFrequencyBiweekly, PayFrequencySemimonthly, PayFrequencyMonthly:
+		default:
+			errs.add("finance.paySchedule.frequency", "must be one of weekly, biweekly, semimonthly, monthly")
+		}
+		errs.checkDate("finance.paySchedule.anchorDate", schedule.AnchorDate)
+	}
+
+	if s.Retirement.BirthDate != "" {
+		errs.checkDate("retirement.birthDate", s.Retirement.BirthDate)
+	}
+	errs.checkPercent("retirement.employerMatchRate", s.Retirement.EmployerMatchRate)
+	errs.checkPercent("retirement.employerMatchLimit", s.Retirement.EmployerMatchLimit)
+	errs.checkPercent("retirement.rothPercent", s.Retirement.RothPercent)
+
+	if profile := s.TaxProfile; profile != nil {
+		switch profile.FilingStatus {
+		case FilingStatusSingle, FilingStatusMarriedFilingJointly, FilingStatusMarriedFilingSeparately, FilingStatusHeadOfHousehold:
+		default:
+			errs.add("taxProfile.filingStatus", "must be one of single, married_filing_jointly, married_filing_separately, head_of_household")
+		}
+		errs.checkPercent("taxProfile.ordinaryIncomeRate", profile.OrdinaryIncomeRate)
+		errs.checkPercent("taxProfile.longTermCapitalGainsRate", profile.LongTermCapitalGainsRate)
+		errs.checkPercent("taxProfile.stateRate", profile.StateRate)
+	}
+
+	if notifications := s.Notifications; notifications != nil {
+		if notifications.Email != "" {
+			if address, err := mail.ParseAddress(notifications.Email); err != nil || address.Address != notifications.Email {
+				errs.add("notifications.email", "must be an email address")
+			}
+		} else if notifications.HoldingPeriod {
+			errs.add("notifications.email", "is required to receive notifications")
+		}
+	}
+
+	return errs
+}
+
+func (i Retirement401kPlanInput) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if i.Year < 0 {
+		errs.add("year", "must not be negative")
+	}
+
+	if i.ContributionsSoFar < 0 {
+		errs.add("contributionsSoFar", "must not be negative")
+	}
+
+	if i.PaychecksRemaining < 0 {
+		errs.add("paychecksRemaining", "must not be negative")
+	}
+
+	if i.ContributionLimit < 0 {
+		errs.add("contributionLimit", "must not be negative")
+	}
+
+	return errs
+}
+
+// Validate checks an archive before it is restored for userID. Every item
+// must carry its ID and belong to the user, and lots and sales may only refer
+// to plans and lots in the same archive, so it restores the same way whatever
+// is already stored.
+func (a UserArchive) Validate(userID string) ValidationErrors {
+	var errs ValidationErrors
+
+	if a.Version != ArchiveVersion {
+		errs.add("version", fmt.Sprintf("must be %d", ArchiveVersion))
+		return errs
+	}
+
+	if a.User != nil {
+		if a.User.UserID != userID {
+			errs.add("user.userId", "must match the user")
+		}
+		errs.addNested("user.settings", a.User.Settings.Validate())
+	}
+
+	plans := map[string]*EsppPlan{}
+	for i, plan := range a.EsppPlans {
+		field := fmt.Sprintf("esppPlans[%d]", i)
+		if plan == nil {
+			errs.add(field, "is required")
+			continue
+		}
+
+		if errs.checkArchiveItem(field, plan.ID, plan.UserID, userID, plans[plan.ID] != nil) {
+			plans[plan.ID] = plan
+		}
+
+		input := EsppPlanInput{
+			Name:                 plan.Name,
+			Employer:             plan.Employer,
+			DiscountPercent:      plan.DiscountPercent,
+			Lookback:             plan.Lookback,
+			OfferingPeriodMonths: plan.OfferingPeriodMonths,
+			PurchasePeriodMonths: plan.PurchasePeriodMonths,
+			ResetRule:            plan.ResetRule,
+			MaxSharesPerPurchase: plan.MaxSharesPerPurchase,
+		}
+		errs.addNested(field, input.Validate())
+	}
+
+	lots := map[string]*EsppLot{}
+	for i, lot := range a.EsppLots {
+		field := fmt.Sprintf("esppLots[%d]", i)
+		if lot == nil {
+			errs.add(field, "is required")
+			continue
+		}
+
+		if errs.checkArchiveItem(field, lot.ID, lot.UserID, userID, lots[lot.ID] != nil) {
+			lots[lot.ID] = lot
+		}
+
+		lotErrs := validateEsppLotFields(lot.GrantDate, lot.PurchaseDate, lot.OfferStartPrice, lot.OfferEndPrice, lot.PurchasePrice, lot.Shares)
+		lotErrs.checkSecurity(lot.Ticker, lot.Employer)
+		if lot.PlanID != "" {
+			if plan, ok := plans[lot.PlanID]; ok {
+				lotErrs = append(lotErrs, plan.ValidateLot(*lot)...)
+			} else {
+				lotErrs.add("planId", "must refer to a plan in the archive")
+			}
+		}
+		errs.addNested(field, lotErrs)
+	}
+
+	sales := map[string]bool{}
+	soldShares := map[string]float64{}
+	for i, sale := range a.EsppSales {
+		field := fmt.Sprintf("esppSales[%d]", i)
+		if sale == nil {
+			errs.add(field, "is required")
+			continue
+		}
+
+		if errs.checkArchiveItem(field, sale.ID, sale.UserID, userID, sales[sale.ID]) {
+			sales[sale.ID] = true
+		}
+
+		input := EsppSaleInput{Date: sale.Date, Price: sale.Price, Shares: sale.Shares}
+		saleErrs := input.Validate()
+		if lot, ok := lots[sale.LotID]; !ok {
+			saleErrs.add("lotId", "must refer to a lot in the archive")
+		} else {
+			saleErrs = append(saleErrs, lot.ValidateSale(input)...)
+			soldShares[sale.LotID] += sale.Shares
+			if soldShares[sale.LotID] > lot.Shares {
+				saleErrs.add("shares", "must not bring the shares sold above the lot's shares")
+			}
+		}
+		errs.addNested(field, saleErrs)
+	}
+
+	return errs
+}
+
+// checkArchiveItem records an error when an archived item has no ID, repeats
+// an ID from earlier in the archive, or belongs to another user. It returns
+// whether the ID is new, so later items can refer to it.
+func (e *ValidationErrors) checkArchiveItem(field string, id string, ownerID string, userID string, seen bool) bool {
+	isNew := true
+	if id == "" {
+		e.add(field+".id", "is required")
+		isNew = false
+	} else if seen {
+		e.add(field+".id", "must be unique")
+		isNew = false
+	}
+
+	if ownerID != userID {
+		e.add(field+".userId", "must match the user")
+	}
+
+	return isNew
+}
@@ -0,0 +1,326 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEsppLotInputValidate(t *testing.T) {
+	validInput := EsppLotInput{
+		UserID:          "user123",
+		GrantDate:       "2023-01-01",
+		PurchaseDate:    "2023-06-30",
+		OfferStartPrice: 100.0,
+		OfferEndPrice:   120.0,
+		PurchasePrice:   85.0,
+		Shares:          10.0,
+	}
+
+	testCases := []struct {
+		name           string
+		modify         func(input *EsppLotInput)
+		expectedErrors ValidationErrors
+	}{
+		{
+			name:           "valid input",
+			modify:         func(input *EsppLotInput) {},
+			expectedErrors: nil,
+		},
+		{
+			name:   "negative shares",
+			modify: func(input *EsppLotInput) { input.Shares = -1 },
+			expectedErrors: ValidationErrors{
+				{Field: "shares", Message: "must be greater than zero"},
+			},
+		},
+		{
+			name:   "purchase price above offer end price",
+			modify: func(input *EsppLotInput) { input.PurchasePrice = 130 },
+			expectedErrors: ValidationErrors{
+				{Field: "purchasePrice", Message: "must not be greater than offerEndPrice"},
+			},
+		},
+		{
+			name:   "purchase date before grant date",
+			modify: func(input *EsppLotInput) { input.PurchaseDate = "2022-12-31" },
+			expectedErrors: ValidationErrors{
+				{Field: "purchaseDate", Message: "must not be before grantDate"},
+			},
+		},
+		{
+			name:   "malformed grant date",
+			modify: func(input *EsppLotInput) { input.GrantDate = "banana" },
+			expectedErrors: ValidationErrors{
+				{Field: "grantDate", Message: "must be a date in YYYY-MM-DD format"},
+			},
+		},
+		{
+			name: "lowercase ticker and employer",
+			modify: func(input *EsppLotInput) {
+				input.Ticker = " brk.b "
+				input.Employer = "Berkshire Hathaway"
+			},
+			expectedErrors: nil,
+		},
+		{
+			name:   "malformed ticker",
+			modify: func(input *EsppLotInput) { input.Ticker = "NIKE INC" },
+			expectedErrors: ValidationErrors{
+				{Field: "ticker", Message: "must be a stock symbol of up to 10 letters, digits, dots, or dashes"},
+			},
+		},
+		{
+			name:   "empty input",
+			modify: func(input *EsppLotInput) { *input = EsppLotInput{} },
+			expectedErrors: ValidationErrors{
+				{Field: "grantDate", Message: "is required"},
+				{Field: "purchaseDate", Message: "is required"},
+				{Field: "offerStartPrice", Message: "must be greater than zero"},
+				{Field: "offerEndPrice", Message: "must be greater than zero"},
+				{Field: "purchasePrice", Message: "must be greater than zero"},
+				{Field: "shares", Message: "must be greater than zero"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := validInput
+			tc.modify(&input)
+
+			assert.Equal(t, tc.expectedErrors, input.Validate())
+		})
+	}
+}
+
+func TestEsppLotUpdateValidate(t *testing.T) {
+	lot := EsppLot{
+		ID:              "lot123",
+		UserID:          "user123",
+		GrantDate:       "2023-01-01",
+		PurchaseDate:    "2023-06-30",
+		OfferStartPrice: 100.0,
+		OfferEndPrice:   120.0,
+		PurchasePrice:   85.0,
+		Shares:          10.0,
+	}
+
+	shares := 12.5
+	assert.Nil(t, EsppLotUpdate{Shares: &shares}.Validate(lot))
+
+	grantDate := "2023-07-01"
+	assert.Equal(t, ValidationErrors{
+		{Field: "purchaseDate", Message: "must not be before grantDate"},
+	}, EsppLotUpdate{GrantDate: &grantDate}.Validate(lot))
+
+	assert.Equal(t, "2023-01-01", lot.GrantDate)
+
+	ticker := "$NKE"
+	assert.Equal(t, ValidationErrors{
+		{Field: "ticker", Message: "must be a stock symbol of up to 10 letters, digits, dots, or dashes"},
+	}, EsppLotUpdate{Ticker: &ticker}.Validate(lot))
+}
+
+func TestEsppPlanInputValidate(t *testing.T) {
+	valid := EsppPlanInput{Name: "Acme ESPP", DiscountPercent: 15, Lookback: true, OfferingPeriodMonths: 24, PurchasePeriodMonths: 6}
+	assert.Nil(t, valid.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "name", Message: "is required"},
+		{Field: "discountPercent", Message: "must be between 0 and 15"},
+		{Field: "offeringPeriodMonths", Message: "must be between 1 and 27"},
+		{Field: "purchasePeriodMonths", Message: "must be greater than zero"},
+		{Field: "resetRule", Message: "must be one of none, lower_price"},
+		{Field: "maxSharesPerPurchase", Message: "must not be negative"},
+	}, EsppPlanInput{Name: " ", DiscountPercent: 20, ResetRule: "sometimes", MaxSharesPerPurchase: -1}.Validate())
+
+	longPurchasePeriod := valid
+	longPurchasePeriod.PurchasePeriodMonths = 27
+	assert.Equal(t, ValidationErrors{
+		{Field: "purchasePeriodMonths", Message: "must not be greater than offeringPeriodMonths"},
+	}, longPurchasePeriod.Validate())
+}
+
+func TestEsppPlanValidateLot(t *testing.T) {
+	plan := EsppPlan{OfferingPeriodMonths: 6, MaxSharesPerPurchase: 20}
+
+	assert.Nil(t, plan.ValidateLot(EsppLot{GrantDate: "2023-01-01", PurchaseDate: "2023-07-01", Shares: 20}))
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "purchaseDate", Message: "must be within the plan's 6 month offering period"},
+		{Field: "shares", Message: "must not be greater than the plan's cap of 20 shares"},
+	}, plan.ValidateLot(EsppLot{GrantDate: "2023-01-01", PurchaseDate: "2023-07-02", Shares: 20.5}))
+
+	assert.Nil(t, EsppPlan{}.ValidateLot(EsppLot{GrantDate: "2023-01-01", PurchaseDate: "2025-01-01", Shares: 1000}))
+}
+
+func TestEsppLotValidateSale(t *testing.T) {
+	lot := EsppLot{PurchaseDate: "2023-06-30"}
+
+	assert.Nil(t, lot.ValidateSale(EsppSaleInput{Date: "2023-06-30"}))
+	assert.Nil(t, lot.ValidateSale(EsppSaleInput{Date: "not a date"}))
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "date", Message: "must not be before the lot's purchaseDate"},
+	}, lot.ValidateSale(EsppSaleInput{Date: "2023-06-29"}))
+}
+
+func TestEsppSaleInputValidate(t *testing.T) {
+	assert.Nil(t, EsppSaleInput{Date: "2024-07-01", Price: 150, Shares: 4}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "date", Message: "must be a date in YYYY-MM-DD format"},
+		{Field: "price", Message: "must be greater than zero"},
+		{Field: "shares", Message: "must be greater than zero"},
+	}, EsppSaleInput{Date: "07/01/2024", Price: 0, Shares: -1}.Validate())
+}
+
+func TestEsppSellPlanInputValidate(t *testing.T) {
+	assert.Nil(t, EsppSellPlanInput{Shares: 10, Date: "2024-07-01", Price: 150}.Validate())
+	assert.Nil(t, EsppSellPlanInput{Cash: 1500, Date: "2024-07-01", Price: 150, Ticker: "nke"}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "shares", Message: "either shares or cash is required"},
+		{Field: "date", Message: "is required"},
+		{Field: "price", Message: "must be greater than zero"},
+	}, EsppSellPlanInput{}.Validate())
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "cash", Message: "must be greater than zero"},
+		{Field: "ticker", Message: "must be a stock symbol of up to 10 letters, digits, dots, or dashes"},
+	}, EsppSellPlanInput{Cash: -5, Date: "2024-07-01", Price: 150, Ticker: "$NKE"}.Validate())
+}
+
+func TestUserSettingsValidate(t *testing.T) {
+	valid := UserSettings{Finance: UserFinanceSettings{AnnualSalary: 100000, PaychecksPerYear: 26}}
+	assert.Nil(t, valid.Validate())
+
+	invalid := UserSettings{Finance: UserFinanceSettings{AnnualSalary: -1, PaychecksPerYear: 0}}
+	assert.Equal(t, ValidationErrors{
+		{Field: "finance.annualSalary", Message: "must not be negative"},
+		{Field: "finance.paychecksPerYear", Message: "must be greater than zero"},
+	}, invalid.Validate())
+
+	invalidSchedule := valid
+	invalidSchedule.Finance.PaySchedule = &PaySchedule{Frequency: "daily"}
+	assert.Equal(t, ValidationErrors{
+		{Field: "finance.paySchedule.frequency", Message: "must be one of weekly, biweekly, semimonthly, monthly"},
+		{Field: "finance.paySchedule.anchorDate", Message: "is required"},
+	}, invalidSchedule.Validate())
+
+	invalidTaxProfile := valid
+	invalidTaxProfile.TaxProfile = &TaxProfile{FilingStatus: "joint", OrdinaryIncomeRate: 24, LongTermCapitalGainsRate: 150, StateRate: -1}
+	assert.Equal(t, ValidationErrors{
+		{Field: "taxProfile.filingStatus", Message: "must be one of single, married_filing_jointly, married_filing_separately, head_of_household"},
+		{Field: "taxProfile.longTermCapitalGainsRate", Message: "must be between 0 and 100"},
+		{Field: "taxProfile.stateRate", Message: "must be between 0 and 100"},
+	}, invalidTaxProfile.Validate())
+
+	invalidRetirement := valid
+	invalidRetirement.Retirement = UserRetirementSettings{
+		BirthDate:          "1/2/1970",
+		EmployerMatchRate:  150,
+		EmployerMatchLimit: 6,
+		RothPercent:        -10,
+	}
+	assert.Equal(t, ValidationErrors{
+		{Field: "retirement.birthDate", Message: "must be a date in YYYY-MM-DD format"},
+		{Field: "retirement.employerMatchRate", Message: "must be between 0 and 100"},
+		{Field: "retirement.rothPercent", Message: "must be between 0 and 100"},
+	}, invalidRetirement.Validate())
+
+	withNotifications := valid
+	withNotifications.Notifications = &NotificationSettings{Email: "user@example.com", HoldingPeriod: true}
+	assert.Nil(t, withNotifications.Validate())
+
+	withNotifications.Notifications = &NotificationSettings{Email: "Jane <user@example.com>", HoldingPeriod: true}
+	assert.Equal(t, ValidationErrors{
+		{Field: "notifications.email", Message: "must be an email address"},
+	}, withNotifications.Validate())
+
+	withNotifications.Notifications = &NotificationSettings{HoldingPeriod: true}
+	assert.Equal(t, ValidationErrors{
+		{Field: "notifications.email", Message: "is required to receive notifications"},
+	}, withNotifications.Validate())
+
+	withNotifications.Notifications = &NotificationSettings{}
+	assert.Nil(t, withNotifications.Validate())
+}
+
+func TestUserArchiveValidate(t *testing.T) {
+	valid := UserArchive{
+		Version: ArchiveVersion,
+		User:    &User{UserID: "user123", Settings: UserSettings{Finance: UserFinanceSettings{PaychecksPerYear: 26}}},
+		EsppPlans: []*EsppPlan{
+			{ID: "plan1", UserID: "user123", Name: "Acme ESPP", DiscountPercent: 15, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6},
+		},
+		EsppLots: []*EsppLot{
+			{ID: "lot1", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 10, PlanID: "plan1"},
+		},
+		EsppSales: []*EsppSale{
+			{ID: "sale1", LotID: "lot1", UserID: "user123", Date: "2024-01-02", Price: 130, Shares: 4},
+		},
+	}
+	assert.Nil(t, valid.Validate("user123"))
+	assert.Nil(t, UserArchive{Version: ArchiveVersion}.Validate("user123"))
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "version", Message: "must be 1"},
+	}, UserArchive{Version: 2, EsppLots: []*EsppLot{nil}}.Validate("user123"))
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "user.userId", Message: "must match the user"},
+		{Field: "esppPlans[0].userId", Message: "must match the user"},
+		{Field: "esppLots[0].userId", Message: "must match the user"},
+		{Field: "esppSales[0].userId", Message: "must match the user"},
+	}, valid.Validate("user456"))
+
+	invalid := UserArchive{
+		Version: ArchiveVersion,
+		User:    &User{UserID: "user123"},
+		EsppPlans: []*EsppPlan{
+			{UserID: "user123", Name: "Acme ESPP", DiscountPercent: 20, OfferingPeriodMonths: 6, PurchasePeriodMonths: 6},
+		},
+		EsppLots: []*EsppLot{
+			{ID: "lot1", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 10, PlanID: "missing"},
+			{ID: "lot1", UserID: "user123", GrantDate: "2023-01-01", PurchaseDate: "2023-06-30", OfferStartPrice: 100, OfferEndPrice: 120, PurchasePrice: 85, Shares: 0},
+			nil,
+		},
+		EsppSales: []*EsppSale{
+			{ID: "sale1", LotID: "lot1", UserID: "user123", Date: "2024-01-02", Price: 130, Shares: 4},
+			{ID: "sale2", LotID: "lot1", UserID: "user123", Date: "2024-01-02", Price: 130, Shares: 7},
+			{ID: "sale3", LotID: "lot9", UserID: "user123", Date: "2024-01-02", Price: 130, Shares: 4},
+		},
+	}
+	assert.Equal(t, ValidationErrors{
+		{Field: "user.settings.finance.paychecksPerYear", Message: "must be greater than zero"},
+		{Field: "esppPlans[0].id", Message: "is required"},
+		{Field: "esppPlans[0].discountPercent", Message: "must be between 0 and 15"},
+		{Field: "esppLots[0].planId", Message: "must refer to a plan in the archive"},
+		{Field: "esppLots[1].id", Message: "must be unique"},
+		{Field: "esppLots[1].shares", Message: "must be greater than zero"},
+		{Field: "esppLots[2]", Message: "is required"},
+		{Field: "esppSales[1].shares", Message: "must not bring the shares sold above the lot's shares"},
+		{Field: "esppSales[2].lotId", Message: "must refer to a lot in the archive"},
+	}, invalid.Validate("user123"))
+}
+
+func TestRetirement401kPlanInputValidate(t *testing.T) {
+	assert.Nil(t, Retirement401kPlanInput{}.Validate())
+
+	invalid := Retirement401kPlanInput{Year: 2026, ContributionsSoFar: -1, PaychecksRemaining: -2}
+	assert.Equal(t, ValidationErrors{
+		{Field: "contributionsSoFar", Message: "must not be negative"},
+		{Field: "paychecksRemaining", Message: "must not be negative"},
+	}, invalid.Validate())
+}
+
+func TestValidationErrorsError(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "shares", Message: "must be greater than zero"},
+		{Field: "date", Message: "is required"},
+	}
+
+	assert.Equal(t, "shares: must be greater than zero; date: is required", errs.Error())
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogNotifier writes notifications to the log instead of delivering them,
+// which is useful locally and before a mail server is set up.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogNotifier logs to logger, or to the default logger when it is nil.
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, message Message) error {
+	n.logger.InfoContext(ctx, "Notification",
+		slog.String("to", message.To),
+		slog.String("subject", message.Subject),
+		slog.String("body", message.Body),
+	)
+
+	return nil
+}
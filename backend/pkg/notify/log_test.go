@@ -0,0 +1,20 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogNotifier(t *testing.T) {
+	var output bytes.Buffer
+	notifier := NewLogNotifier(slog.New(slog.NewTextHandler(&output, nil)))
+
+	err := notifier.Notify(context.Background(), Message{To: "user@example.com", Subject: "Lots are qualifying", Body: "NKE"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), `msg=Notification to=user@example.com subject="Lots are qualifying" body=NKE`)
+}
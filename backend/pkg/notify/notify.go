@@ -0,0 +1,63 @@
+// Package notify delivers reminders to users by email, or to the log when no
+// mail server is configured.
+package notify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Message is a plain-text notification for one recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NewNotifierFromEnv sends mail through the server at SMTP_HOST from the
+// NOTIFY_FROM address. Without SMTP_HOST, notifications are only logged.
+func NewNotifierFromEnv() Notifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return NewLogNotifier(nil)
+	}
+
+	from := os.Getenv("NOTIFY_FROM")
+	if from == "" {
+		return misconfiguredNotifier{err: errors.New("NOTIFY_FROM must be set when SMTP_HOST is set")}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = DefaultSMTPPort
+	}
+
+	return NewSMTPNotifier(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	})
+}
+
+// misconfiguredNotifier fails every notification so a bad deployment is
+// reported by each run instead of silently dropping mail.
+type misconfiguredNotifier struct {
+	err error
+}
+
+func (n misconfiguredNotifier) Notify(_ context.Context, _ Message) error {
+	return n.err
+}
+
+// headerValue keeps a value on one header line so it cannot add headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNotifierFromEnv(t *testing.T) {
+	t.Setenv("SMTP_HOST", "")
+	assert.IsType(t, &LogNotifier{}, NewNotifierFromEnv())
+
+	t.Setenv("SMTP_HOST", "mail.example.com")
+	t.Setenv("NOTIFY_FROM", "")
+	assert.ErrorContains(t, NewNotifierFromEnv().Notify(context.Background(), Message{}), "NOTIFY_FROM must be set")
+
+	t.Setenv("NOTIFY_FROM", "fife@example.com")
+	t.Setenv("SMTP_PORT", "")
+	notifier, ok := NewNotifierFromEnv().(*SMTPNotifier)
+	assert.True(t, ok)
+	assert.Equal(t, SMTPConfig{Host: "mail.example.com", Port: DefaultSMTPPort, From: "fife@example.com"}, notifier.config)
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// DefaultSMTPPort is the mail submission port, which expects STARTTLS.
+const DefaultSMTPPort = "587"
+
+type SMTPConfig struct {
+	Host string
+	Port string
+	// Username and Password are optional. Without them mail is sent without
+	// authenticating, which suits a local SMTP stand-in.
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier sends each notification as a plain-text email. The connection
+// is upgraded with STARTTLS whenever the server offers it.
+type SMTPNotifier struct {
+	config SMTPConfig
+	dialer *net.Dialer
+	now    func() time.Time
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		config: config,
+		dialer: &net.Dialer{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, message Message) error {
+	conn, err := n.dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.config.Host, n.config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+
+	// The deadline bounds the whole conversation, since net/smtp does not
+	// take a context.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start mail session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate with mail server: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return fmt.Errorf("mail server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("mail server rejected recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(n.buildEmail(message)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("mail server rejected message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildEmail formats the message as an RFC 5322 email with CRLF line endings.
+func (n *SMTPNotifier) buildEmail(message Message) []byte {
+	var email bytes.Buffer
+
+	headers := [][2]string{
+		{"From", headerValue(n.config.From)},
+		{"To", headerValue(message.To)},
+		{"Subject", mime.QEncoding.Encode("utf-8", headerValue(message.Subject))},
+		{"Date", n.now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&email, "%s: %s\r\n", header[0], header[1])
+	}
+
+	email.WriteString("\r\n")
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	email.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	email.WriteString("\r\n")
+
+	return email.Bytes()
+}
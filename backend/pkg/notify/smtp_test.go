@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpStandIn is just enough of an SMTP server to accept mail and record it.
+type smtpStandIn struct {
+	listener   net.Listener
+	rejectRcpt bool
+	received   chan receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &smtpStandIn{listener: listener, received: make(chan receivedMail, 1)}
+	go server.serve()
+
+	return server
+}
+
+func (s *smtpStandIn) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "fife@example.com"}
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+	mail := receivedMail{}
+	reply("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			mail.from = angleAddress(line)
+			reply("250 OK")
+		case "RCPT":
+			if s.rejectRcpt {
+				reply("550 No such user")
+				continue
+			}
+			mail.to = append(mail.to, angleAddress(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.data = string(data)
+			s.received <- mail
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// angleAddress returns the address in a MAIL or RCPT command, which is
+// followed by optional parameters.
+func angleAddress(line string) string {
+	_, address, _ := strings.Cut(line, "<")
+	address, _, _ = strings.Cut(address, ">")
+	return address
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newSMTPStandIn(t)
+
+	notifier := NewSMTPNotifier(server.config())
+	notifier.now = func() time.Time { return time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC) }
+
+	err := notifier.Notify(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Lots are qualifying\r\nBcc: victim@example.com",
+		Body:    "Line one\n.\nLine three",
+	})
+	assert.NoError(t, err)
+
+	mail := <-server.received
+	assert.Equal(t, "fife@example.com", mail.from)
+	assert.Equal(t, []string{"user@example.com"}, mail.to)
+
+	header, body, _ := strings.Cut(mail.data, "\n\n")
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(header + "\n\n")))
+	fields, err := reader.ReadMIMEHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "fife@example.com", fields.Get("From"))
+	assert.Equal(t, "user@example.com", fields.Get("To"))
+	assert.Equal(t, "Lots are qualifying  Bcc: victim@example.com", fields.Get("Subject"))
+	assert.Empty(t, fields.Get("Bcc"))
+	assert.Equal(t, "Mon, 01 Jul 2024 08:00:00 +0000", fields.Get("Date"))
+	assert.Equal(t, "text/plain; charset=utf-8", fields.Get("Content-Type"))
+	assert.Equal(t, "Line one\n.\nLine three\n", body)
+}
+
+func TestSMTPNotifierRejectedRecipient(t *testing.T) {
+	server := newSMTPStandIn(t)
+	server.rejectRcpt = true
+
+	err := NewSMTPNotifier(server.config()).Notify(context.Background(), Message{To: "nobody@example.com", Subject: "Hi", Body: "Hi"})
+
+	assert.ErrorContains(t, err, "rejected recipient")
+}
+
+func TestSMTPNotifierConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	err = NewSMTPNotifier(SMTPConfig{Host: host, Port: port, From: "fife@example.com"}).Notify(context.Background(), Message{To: "user@example.com"})
+
+	assert.ErrorContains(t, err, "failed to connect to mail server")
+}
+
+func TestBuildEmailEncodesSubject(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{From: "fife@example.com"})
+
+	email := string(notifier.buildEmail(Message{To: "user@example.com", Subject: "Qualifying — NKE", Body: "Hi"}))
+
+	assert.Contains(t, email, "Subject: =?utf-8?q?Qualifying_=E2=80=94_NKE?=\r\n")
+	assert.True(t, strings.HasSuffix(email, "\r\n\r\nHi\r\n"))
+}
@@ -0,0 +1,140 @@
+// Package paycheck lists the exact paydays a pay schedule produces.
+package paycheck
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+const semimonthlyGapDays = 15
+
+var ErrInvalidSchedule = errors.New("invalid pay schedule")
+
+// DatesInYear returns every payday in year, in order.
+//
+// Weekly and biweekly paydays step from the anchor date in either direction.
+// Monthly paydays fall on the anchor's day of the month, moved to the last day
+// of shorter months; an anchor on the last day of its month means the last
+// day of every month. Semimonthly paydays fall on the anchor's day and the day
+// 15 days from it; an anchor on the last day of its month means the 15th and
+// the last day.
+func DatesInYear(schedule models.PaySchedule, year int) ([]time.Time, error) {
+	anchor, err := time.Parse(models.DateLayout, schedule.AnchorDate)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+
+	switch schedule.Frequency {
+	case models.PayFrequencyWeekly:
+		return everyNDays(anchor, 7, year), nil
+	case models.PayFrequencyBiweekly:
+		return everyNDays(anchor, 14, year), nil
+	case models.PayFrequencyMonthly:
+		return monthly(anchor, year), nil
+	case models.PayFrequencySemimonthly:
+		return semimonthly(anchor, year), nil
+	default:
+		return nil, ErrInvalidSchedule
+	}
+}
+
+// Remaining returns the paydays from the given date, inclusive, to the end of
+// its year.
+func Remaining(schedule models.PaySchedule, from time.Time) ([]time.Time, error) {
+	dates, err := DatesInYear(schedule, from.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	from = dateOnly(from)
+	for i, date := range dates {
+		if !date.Before(from) {
+			return dates[i:], nil
+		}
+	}
+
+	return []time.Time{}, nil
+}
+
+func everyNDays(anchor time.Time, days int, year int) []time.Time {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	// Step whole periods from the anchor to the first payday on or after
+	// January 1. Integer division already rounds up when the anchor is later.
+	offset := daysBetween(anchor, start)
+	periods := offset / days
+	if offset > 0 && offset%days != 0 {
+		periods++
+	}
+
+	dates := []time.Time{}
+	for date := anchor.AddDate(0, 0, periods*days); date.Before(end); date = date.AddDate(0, 0, days) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
+
+func monthly(anchor time.Time, year int) []time.Time {
+	dates := make([]time.Time, 0, 12)
+	for month := time.January; month <= time.December; month++ {
+		if isLastDayOfMonth(anchor) {
+			dates = append(dates, lastDayOfMonth(year, month))
+		} else {
+			dates = append(dates, dayOfMonth(year, month, anchor.Day()))
+		}
+	}
+
+	return dates
+}
+
+func semimonthly(anchor time.Time, year int) []time.Time {
+	first := anchor.Day()
+	if first > semimonthlyGapDays {
+		first -= semimonthlyGapDays
+	}
+	if isLastDayOfMonth(anchor) {
+		first = semimonthlyGapDays
+	}
+
+	dates := make([]time.Time, 0, 24)
+	for month := time.January; month <= time.December; month++ {
+		dates = append(dates, dayOfMonth(year, month, first))
+		if isLastDayOfMonth(anchor) {
+			dates = append(dates, lastDayOfMonth(year, month))
+		} else {
+			dates = append(dates, dayOfMonth(year, month, first+semimonthlyGapDays))
+		}
+	}
+
+	return dates
+}
+
+// dayOfMonth moves days past the end of a short month back to its last day.
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	last := lastDayOfMonth(year, month)
+	if day > last.Day() {
+		return last
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func lastDayOfMonth(year int, month time.Month) time.Time {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+func isLastDayOfMonth(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(dateOnly(to).Sub(dateOnly(from)).Hours() / 24)
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,138 @@
+package paycheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatesInYear(t *testing.T) {
+	testCases := []struct {
+		name          string
+		schedule      models.PaySchedule
+		year          int
+		expectedCount int
+		expectedFirst string
+		expectedLast  string
+		expectedDates []string
+	}{
+		{
+			name:          "weekly anchored in a previous year",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencyWeekly, AnchorDate: "2024-01-05"},
+			year:          2026,
+			expectedCount: 52,
+			expectedFirst: "2026-01-02",
+			expectedLast:  "2026-12-25",
+		},
+		{
+			name:          "biweekly with 27 paydays",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencyBiweekly, AnchorDate: "2026-01-01"},
+			year:          2026,
+			expectedCount: 27,
+			expectedFirst: "2026-01-01",
+			expectedLast:  "2026-12-31",
+		},
+		{
+			name:          "biweekly anchored in a later year",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencyBiweekly, AnchorDate: "2027-01-08"},
+			year:          2026,
+			expectedCount: 26,
+			expectedFirst: "2026-01-09",
+			expectedLast:  "2026-12-25",
+		},
+		{
+			name:          "monthly on the 31st",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencyMonthly, AnchorDate: "2026-01-31"},
+			year:          2028,
+			expectedCount: 12,
+			expectedFirst: "2028-01-31",
+			expectedLast:  "2028-12-31",
+			expectedDates: []string{"2028-01-31", "2028-02-29", "2028-03-31", "2028-04-30", "2028-05-31", "2028-06-30", "2028-07-31", "2028-08-31", "2028-09-30", "2028-10-31", "2028-11-30", "2028-12-31"},
+		},
+		{
+			name:          "monthly on the 30th",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencyMonthly, AnchorDate: "2026-03-30"},
+			year:          2026,
+			expectedCount: 12,
+			expectedFirst: "2026-01-30",
+			expectedLast:  "2026-12-30",
+		},
+		{
+			name:          "semimonthly on the 15th and last day",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencySemimonthly, AnchorDate: "2026-02-28"},
+			year:          2026,
+			expectedCount: 24,
+			expectedFirst: "2026-01-15",
+			expectedLast:  "2026-12-31",
+		},
+		{
+			name:          "semimonthly on the 1st and 16th",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencySemimonthly, AnchorDate: "2026-05-16"},
+			year:          2026,
+			expectedCount: 24,
+			expectedFirst: "2026-01-01",
+			expectedLast:  "2026-12-16",
+		},
+		{
+			name:          "semimonthly on the 14th and 29th",
+			schedule:      models.PaySchedule{Frequency: models.PayFrequencySemimonthly, AnchorDate: "2026-01-14"},
+			year:          2026,
+			expectedCount: 24,
+			expectedFirst: "2026-01-14",
+			expectedLast:  "2026-12-29",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dates, err := DatesInYear(tc.schedule, tc.year)
+
+			assert.NoError(t, err)
+			assert.Len(t, dates, tc.expectedCount)
+			assert.Equal(t, tc.expectedFirst, dates[0].Format(models.DateLayout))
+			assert.Equal(t, tc.expectedLast, dates[len(dates)-1].Format(models.DateLayout))
+
+			if tc.expectedDates != nil {
+				assert.Equal(t, tc.expectedDates, formatDates(dates))
+			}
+		})
+	}
+}
+
+func TestDatesInYearSemimonthlyFebruary(t *testing.T) {
+	dates, err := DatesInYear(models.PaySchedule{Frequency: models.PayFrequencySemimonthly, AnchorDate: "2026-01-14"}, 2026)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-02-14", "2026-02-28"}, formatDates(dates[2:4]))
+}
+
+func TestDatesInYearInvalidSchedule(t *testing.T) {
+	_, err := DatesInYear(models.PaySchedule{Frequency: "daily", AnchorDate: "2026-01-01"}, 2026)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = DatesInYear(models.PaySchedule{Frequency: models.PayFrequencyWeekly, AnchorDate: "01/01/2026"}, 2026)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestRemaining(t *testing.T) {
+	schedule := models.PaySchedule{Frequency: models.PayFrequencySemimonthly, AnchorDate: "2026-01-15"}
+
+	dates, err := Remaining(schedule, time.Date(2026, time.November, 30, 18, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-11-30", "2026-12-15", "2026-12-30"}, formatDates(dates))
+
+	dates, err = Remaining(models.PaySchedule{Frequency: models.PayFrequencyMonthly, AnchorDate: "2026-01-01"}, time.Date(2026, time.December, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Empty(t, dates)
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, date := range dates {
+		formatted[i] = date.Format(models.DateLayout)
+	}
+
+	return formatted
+}
@@ -0,0 +1,71 @@
+package quotes
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// DefaultMaxAge is how long a cached quote is served before it is refetched.
+const DefaultMaxAge = 15 * time.Minute
+
+// CachedProvider serves quotes from the cache table and refreshes them from
+// source once they are older than maxAge. When source fails or has no quote,
+// the stale cached quote is still the latest known price and is returned.
+type CachedProvider struct {
+	cache  db.QuoteRepository
+	source PriceProvider
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewCachedProvider wraps source with cache. A nil source serves only cached
+// quotes.
+func NewCachedProvider(cache db.QuoteRepository, source PriceProvider, maxAge time.Duration) *CachedProvider {
+	return &CachedProvider{cache: cache, source: source, maxAge: maxAge, now: time.Now}
+}
+
+func (p *CachedProvider) LatestQuote(ticker string) (*models.Quote, error) {
+	ticker = models.NormalizeTicker(ticker)
+
+	cached, err := p.cache.GetQuote(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.source == nil || (cached != nil && p.isFresh(cached)) {
+		return cached, nil
+	}
+
+	quote, err := p.source.LatestQuote(ticker)
+	if err != nil {
+		if cached != nil {
+			slog.Warn("Failed to refresh quote, serving cached quote", slog.String("ticker", ticker), slog.Any("error", err))
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if quote == nil {
+		return cached, nil
+	}
+
+	quote.Ticker = ticker
+	quote.UpdatedAt = p.now().UTC().Format(time.RFC3339)
+	if err := p.cache.PutQuote(*quote); err != nil {
+		slog.Warn("Failed to cache quote", slog.String("ticker", ticker), slog.Any("error", err))
+	}
+
+	return quote, nil
+}
+
+func (p *CachedProvider) isFresh(quote *models.Quote) bool {
+	updatedAt, err := time.Parse(time.RFC3339, quote.UpdatedAt)
+	if err != nil {
+		return false
+	}
+
+	return p.now().Sub(updatedAt) < p.maxAge
+}
@@ -0,0 +1,112 @@
+package quotes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache() *db.MemoryQuoteRepository {
+	return db.NewMemoryQuoteRepository()
+}
+
+// countingProvider records lookups so tests can tell cache hits from misses.
+type countingProvider struct {
+	quote *models.Quote
+	err   error
+	calls int
+}
+
+func (p *countingProvider) LatestQuote(_ string) (*models.Quote, error) {
+	p.calls++
+	if p.quote == nil {
+		return nil, p.err
+	}
+
+	quote := *p.quote
+	return &quote, p.err
+}
+
+type failingQuoteRepository struct {
+	err error
+}
+
+func (r *failingQuoteRepository) GetQuote(_ string) (*models.Quote, error) {
+	return nil, r.err
+}
+
+func (r *failingQuoteRepository) PutQuote(_ models.Quote) error {
+	return r.err
+}
+
+func TestCachedProvider(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	cache := newTestCache()
+	source := &countingProvider{quote: &models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"}}
+
+	provider := NewCachedProvider(cache, source, DefaultMaxAge)
+	provider.now = func() time.Time { return now }
+
+	quote, err := provider.LatestQuote("nke")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16", UpdatedAt: "2026-10-16T15:00:00Z"}, quote)
+	assert.Equal(t, 1, source.calls)
+
+	cached, _ := cache.GetQuote("NKE")
+	assert.Equal(t, quote, cached)
+
+	now = now.Add(10 * time.Minute)
+	_, err = provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.calls, "fresh quotes are served from the cache")
+
+	now = now.Add(10 * time.Minute)
+	source.quote.Price = 98.25
+	quote, err = provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 98.25, quote.Price)
+	assert.Equal(t, "2026-10-16T15:20:00Z", quote.UpdatedAt)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestCachedProviderServesStaleQuotes(t *testing.T) {
+	cache := newTestCache()
+	assert.NoError(t, cache.PutQuote(models.Quote{Ticker: "NKE", Price: 97.5, UpdatedAt: "2026-10-01T15:00:00Z"}))
+
+	provider := NewCachedProvider(cache, &countingProvider{err: errors.New("quote service down")}, DefaultMaxAge)
+	quote, err := provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 97.5, quote.Price)
+
+	provider = NewCachedProvider(cache, &countingProvider{}, DefaultMaxAge)
+	quote, err = provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 97.5, quote.Price)
+
+	_, err = provider.LatestQuote("AAPL")
+	assert.NoError(t, err)
+
+	provider = NewCachedProvider(cache, &countingProvider{err: errors.New("quote service down")}, DefaultMaxAge)
+	_, err = provider.LatestQuote("AAPL")
+	assert.EqualError(t, err, "quote service down")
+}
+
+func TestCachedProviderCacheErrors(t *testing.T) {
+	provider := NewCachedProvider(&failingQuoteRepository{err: errors.New("dynamodb error")}, &countingProvider{}, DefaultMaxAge)
+
+	_, err := provider.LatestQuote("NKE")
+	assert.EqualError(t, err, "dynamodb error")
+}
+
+func TestCachedProviderWithoutSource(t *testing.T) {
+	cache := newTestCache()
+	provider := NewCachedProvider(cache, nil, DefaultMaxAge)
+
+	quote, err := provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Nil(t, quote)
+}
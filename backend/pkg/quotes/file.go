@@ -0,0 +1,56 @@
+package quotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// FileProvider serves a fixed set of quotes, for local development and for
+// running offline.
+type FileProvider struct {
+	quotes map[string]models.Quote
+}
+
+func NewFileProvider(quotes []models.Quote) *FileProvider {
+	provider := &FileProvider{quotes: map[string]models.Quote{}}
+	for _, quote := range quotes {
+		quote.Ticker = models.NormalizeTicker(quote.Ticker)
+		provider.quotes[quote.Ticker] = quote
+	}
+
+	return provider
+}
+
+// LoadFileProvider reads a JSON array of quotes such as
+// [{"ticker":"NKE","price":97.5,"date":"2026-10-16"}].
+func LoadFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []models.Quote
+	if err := json.Unmarshal(data, &quotes); err != nil {
+		return nil, fmt.Errorf("invalid quotes file: %w", err)
+	}
+
+	for _, quote := range quotes {
+		if !models.IsValidTicker(quote.Ticker) || quote.Price <= 0 {
+			return nil, fmt.Errorf("invalid quotes file: bad quote for ticker %q", quote.Ticker)
+		}
+	}
+
+	return NewFileProvider(quotes), nil
+}
+
+func (p *FileProvider) LatestQuote(ticker string) (*models.Quote, error) {
+	quote, ok := p.quotes[models.NormalizeTicker(ticker)]
+	if !ok {
+		return nil, nil
+	}
+
+	return &quote, nil
+}
@@ -0,0 +1,67 @@
+package quotes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeQuotesFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "quotes.json")
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+
+	return path
+}
+
+func TestLoadFileProvider(t *testing.T) {
+	path := writeQuotesFile(t, `[{"ticker":"nke","price":97.5,"date":"2026-10-16"},{"ticker":"BRK.B","price":480}]`)
+
+	provider, err := LoadFileProvider(path)
+	assert.NoError(t, err)
+
+	quote, err := provider.LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"}, quote)
+
+	quote, err = provider.LatestQuote("brk.b")
+	assert.NoError(t, err)
+	assert.Equal(t, 480.0, quote.Price)
+
+	quote, err = provider.LatestQuote("AAPL")
+	assert.NoError(t, err)
+	assert.Nil(t, quote)
+}
+
+func TestLoadFileProviderErrors(t *testing.T) {
+	_, err := LoadFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	_, err = LoadFileProvider(writeQuotesFile(t, `{"NKE":97.5}`))
+	assert.ErrorContains(t, err, "invalid quotes file")
+
+	_, err = LoadFileProvider(writeQuotesFile(t, `[{"ticker":"NKE","price":0}]`))
+	assert.ErrorContains(t, err, `bad quote for ticker "NKE"`)
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	cache := newTestCache()
+
+	t.Setenv("QUOTES_FILE", writeQuotesFile(t, `[{"ticker":"NKE","price":97.5,"date":"2026-10-16"}]`))
+	quote, err := NewProviderFromEnv(cache).LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 97.5, quote.Price)
+
+	t.Setenv("QUOTES_FILE", "")
+	quote, err = NewProviderFromEnv(cache).LatestQuote("NKE")
+	assert.NoError(t, err)
+	assert.Equal(t, 97.5, quote.Price, "unconfigured providers still serve cached quotes")
+
+	t.Setenv("QUOTES_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	_, err = NewProviderFromEnv(newTestCache()).LatestQuote("NKE")
+	assert.ErrorContains(t, err, "failed to load QUOTES_FILE")
+}
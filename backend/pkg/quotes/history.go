@@ -0,0 +1,182 @@
+package quotes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+var (
+	ErrEmptyHistory   = errors.New("file has no header row")
+	ErrHistoryColumns = errors.New("expected Date and Close columns")
+)
+
+// historyDateLayouts are the date formats used by common broker and exchange
+// price history exports.
+var historyDateLayouts = []string{models.DateLayout, "01/02/2006", "1/2/2006"}
+
+// ParseHistoryCSV reads daily closes for ticker from a CSV with Date and Close
+// columns, such as a Yahoo Finance or Nasdaq export. Column names are matched
+// case-insensitively, "Close/Last" is accepted, and prices may carry a "$".
+// A date may repeat only with the same close, and is returned once.
+func ParseHistoryCSV(ticker string, data []byte) ([]models.ClosingPrice, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyHistory
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dateColumn, closeColumn := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "date":
+			dateColumn = i
+		case "close", "close/last":
+			closeColumn = i
+		}
+	}
+	if dateColumn < 0 || closeColumn < 0 {
+		return nil, ErrHistoryColumns
+	}
+
+	ticker = models.NormalizeTicker(ticker)
+	prices := []models.ClosingPrice{}
+	type dateRow struct {
+		row   int
+		close float64
+	}
+	seen := map[string]dateRow{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return prices, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) <= max(dateColumn, closeColumn) {
+			return nil, fmt.Errorf("row %d: missing date or close", row)
+		}
+
+		date, ok := parseHistoryDate(strings.TrimSpace(record[dateColumn]))
+		if !ok {
+			return nil, fmt.Errorf("row %d: invalid date %q", row, record[dateColumn])
+		}
+
+		closeValue := strings.TrimPrefix(strings.TrimSpace(record[closeColumn]), "$")
+		closePrice, err := strconv.ParseFloat(closeValue, 64)
+		if err != nil || math.IsNaN(closePrice) || math.IsInf(closePrice, 0) || closePrice <= 0 {
+			return nil, fmt.Errorf("row %d: invalid close %q", row, record[closeColumn])
+		}
+
+		if earlier, ok := seen[date]; ok {
+			if earlier.close != closePrice {
+				return nil, fmt.Errorf("row %d: close for %s conflicts with row %d", row, date, earlier.row)
+			}
+			continue
+		}
+
+		seen[date] = dateRow{row: row, close: closePrice}
+		prices = append(prices, models.ClosingPrice{Ticker: ticker, Date: date, Close: closePrice})
+	}
+}
+
+func parseHistoryDate(value string) (string, bool) {
+	for _, layout := range historyDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format(models.DateLayout), true
+		}
+	}
+
+	return "", false
+}
+
+// maxCloseLookback bounds how far before a date a recorded close is still
+// taken as the close for that date, enough to span a long holiday weekend.
+const maxCloseLookback = 4 * 24 * time.Hour
+
+// closeTolerance is how far an entered price may be from the recorded close
+// before it is flagged, allowing for rounding to the cent.
+const closeTolerance = 0.005
+
+// BackfillOfferPrices fills in a missing offer start or end price with the
+// recorded close on the grant or purchase date of a lot with a ticker. Entered
+// prices that disagree with the recorded close are left as they are and
+// returned as warnings.
+func BackfillOfferPrices(history db.PriceHistoryRepository, input *models.EsppLotInput) (models.ValidationErrors, error) {
+	ticker := models.NormalizeTicker(input.Ticker)
+	if !models.IsValidTicker(ticker) {
+		return nil, nil
+	}
+
+	offers := []struct {
+		field string
+		date  string
+		price *float64
+	}{
+		{field: "offerStartPrice", date: input.GrantDate, price: &input.OfferStartPrice},
+		{field: "offerEndPrice", date: input.PurchaseDate, price: &input.OfferEndPrice},
+	}
+
+	var warnings models.ValidationErrors
+	for _, offer := range offers {
+		closePrice, err := closeOn(history, ticker, offer.date)
+		if err != nil {
+			return nil, err
+		}
+		if closePrice == nil {
+			continue
+		}
+
+		if *offer.price == 0 {
+			*offer.price = closePrice.Close
+			continue
+		}
+
+		if math.Abs(*offer.price-closePrice.Close) > closeTolerance {
+			warnings = append(warnings, models.FieldError{
+				Field:   offer.field,
+				Message: fmt.Sprintf("does not match the recorded close of %.2f on %s", closePrice.Close, closePrice.Date),
+			})
+		}
+	}
+
+	return warnings, nil
+}
+
+// closeOn returns the close for date, or the last close shortly before it when
+// the market was closed that day. Unparseable dates are left to validation.
+func closeOn(history db.PriceHistoryRepository, ticker string, date string) (*models.ClosingPrice, error) {
+	day, err := time.Parse(models.DateLayout, date)
+	if err != nil {
+		return nil, nil
+	}
+
+	closePrice, err := history.GetClosingPriceOnOrBefore(ticker, date)
+	if err != nil || closePrice == nil {
+		return nil, err
+	}
+
+	closeDay, err := time.Parse(models.DateLayout, closePrice.Date)
+	if err != nil || day.Sub(closeDay) > maxCloseLookback {
+		return nil, nil
+	}
+
+	return closePrice, nil
+}
@@ -0,0 +1,200 @@
+package quotes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHistoryCSV(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           string
+		expectedPrices []models.ClosingPrice
+		expectedError  string
+	}{
+		{
+			name: "yahoo export",
+			data: "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+				"2023-03-30,118.00,120.00,117.50,119.50,118.20,100\n" +
+				"2023-03-31,119.60,121.00,119.00,120.10,118.80,100\n",
+			expectedPrices: []models.ClosingPrice{
+				{Ticker: "NKE", Date: "2023-03-30", Close: 119.5},
+				{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+			},
+		},
+		{
+			name: "nasdaq export",
+			data: "\ufeffDate,Close/Last,Volume,Open,High,Low\n" +
+				"03/31/2023,$120.10,100,$119.60,$121.00,$119.00\n",
+			expectedPrices: []models.ClosingPrice{
+				{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+			},
+		},
+		{
+			name: "repeated date",
+			data: "Date,Close\n" +
+				"2023-03-30,119.50\n" +
+				"2023-03-31,120.10\n" +
+				"03/30/2023,$119.50\n",
+			expectedPrices: []models.ClosingPrice{
+				{Ticker: "NKE", Date: "2023-03-30", Close: 119.5},
+				{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+			},
+		},
+		{
+			name:          "conflicting repeated date",
+			data:          "Date,Close\n2023-03-30,119.50\n2023-03-31,120.10\n03/30/2023,119.60\n",
+			expectedError: "row 3: close for 2023-03-30 conflicts with row 1",
+		},
+		{
+			name:          "empty file",
+			data:          "",
+			expectedError: "file has no header row",
+		},
+		{
+			name:          "missing close column",
+			data:          "Date,Open\n2023-03-31,119.60\n",
+			expectedError: "expected Date and Close columns",
+		},
+		{
+			name:          "invalid date",
+			data:          "Date,Close\nMarch 31,120.10\n",
+			expectedError: `row 1: invalid date "March 31"`,
+		},
+		{
+			name:          "invalid close",
+			data:          "Date,Close\n2023-03-30,119.50\n2023-03-31,null\n",
+			expectedError: `row 2: invalid close "null"`,
+		},
+		{
+			name:          "not a number close",
+			data:          "Date,Close\n2023-03-31,NaN\n",
+			expectedError: `row 1: invalid close "NaN"`,
+		},
+		{
+			name:          "infinite close",
+			data:          "Date,Close\n2023-03-31,Inf\n",
+			expectedError: `row 1: invalid close "Inf"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prices, err := ParseHistoryCSV("nke", []byte(tc.data))
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrices, prices)
+		})
+	}
+}
+
+type failingPriceHistoryRepository struct {
+	err error
+}
+
+func (r *failingPriceHistoryRepository) GetClosingPriceOnOrBefore(_ string, _ string) (*models.ClosingPrice, error) {
+	return nil, r.err
+}
+
+func (r *failingPriceHistoryRepository) BatchPutClosingPrices(_ []models.ClosingPrice) error {
+	return r.err
+}
+
+func TestBackfillOfferPrices(t *testing.T) {
+	history := db.NewMemoryPriceHistoryRepository()
+	assert.NoError(t, history.BatchPutClosingPrices([]models.ClosingPrice{
+		{Ticker: "NKE", Date: "2022-09-30", Close: 83.12},
+		{Ticker: "NKE", Date: "2023-03-31", Close: 120.1},
+	}))
+
+	lot := models.EsppLotInput{
+		GrantDate:     "2022-10-01",
+		PurchaseDate:  "2023-03-31",
+		PurchasePrice: 70.65,
+		Shares:        10,
+		Ticker:        "nke",
+	}
+
+	testCases := []struct {
+		name             string
+		modify           func(input *models.EsppLotInput)
+		expectedStart    float64
+		expectedEnd      float64
+		expectedWarnings models.ValidationErrors
+	}{
+		{
+			name: "missing prices filled from closes",
+			// The grant date is a Saturday, so Friday's close is used.
+			modify:        func(input *models.EsppLotInput) {},
+			expectedStart: 83.12,
+			expectedEnd:   120.1,
+		},
+		{
+			name: "entered prices matching closes",
+			modify: func(input *models.EsppLotInput) {
+				input.OfferStartPrice = 83.12
+				input.OfferEndPrice = 120.1
+			},
+			expectedStart: 83.12,
+			expectedEnd:   120.1,
+		},
+		{
+			name: "entered price disagreeing with close",
+			modify: func(input *models.EsppLotInput) {
+				input.OfferEndPrice = 121.1
+			},
+			expectedStart: 83.12,
+			expectedEnd:   121.1,
+			expectedWarnings: models.ValidationErrors{
+				{Field: "offerEndPrice", Message: "does not match the recorded close of 120.10 on 2023-03-31"},
+			},
+		},
+		{
+			name: "no close near the date",
+			modify: func(input *models.EsppLotInput) {
+				input.GrantDate = "2022-10-10"
+			},
+			expectedStart: 0,
+			expectedEnd:   120.1,
+		},
+		{
+			name:          "no ticker",
+			modify:        func(input *models.EsppLotInput) { input.Ticker = "" },
+			expectedStart: 0,
+			expectedEnd:   0,
+		},
+		{
+			name:          "invalid date left to validation",
+			modify:        func(input *models.EsppLotInput) { input.PurchaseDate = "banana" },
+			expectedStart: 83.12,
+			expectedEnd:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := lot
+			tc.modify(&input)
+
+			warnings, err := BackfillOfferPrices(history, &input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, warnings)
+			assert.Equal(t, tc.expectedStart, input.OfferStartPrice)
+			assert.Equal(t, tc.expectedEnd, input.OfferEndPrice)
+		})
+	}
+
+	input := lot
+	_, err := BackfillOfferPrices(&failingPriceHistoryRepository{err: errors.New("dynamodb error")}, &input)
+	assert.EqualError(t, err, "dynamodb error")
+}
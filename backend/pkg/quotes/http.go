@@ -0,0 +1,58 @@
+package quotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// HTTPProvider fetches quotes from a service that answers
+// GET {baseURL}/quote/{ticker} with a JSON quote and 404 for unknown tickers.
+// Pointing baseURL at another fife server, or a test stand-in, works too.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPProvider(baseURL string, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return &HTTPProvider{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (p *HTTPProvider) LatestQuote(ticker string) (*models.Quote, error) {
+	ticker = models.NormalizeTicker(ticker)
+
+	resp, err := p.client.Get(p.baseURL + "/quote/" + url.PathEscape(ticker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quote: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch quote: status %d", resp.StatusCode)
+	}
+
+	var quote models.Quote
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return nil, fmt.Errorf("invalid quote response: %w", err)
+	}
+
+	if quote.Price <= 0 {
+		return nil, fmt.Errorf("invalid quote response: price %v for %s", quote.Price, ticker)
+	}
+	quote.Ticker = ticker
+
+	return &quote, nil
+}
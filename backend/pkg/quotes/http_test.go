@@ -0,0 +1,56 @@
+package quotes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	var requestedPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/quote/NKE":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ticker":"NKE","price":97.5,"date":"2026-10-16"}`))
+		case "/quote/FREE":
+			_, _ = w.Write([]byte(`{"ticker":"FREE","price":0}`))
+		case "/quote/DOWN":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL+"/", nil)
+
+	quote, err := provider.LatestQuote("nke")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Quote{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"}, quote)
+
+	quote, err = provider.LatestQuote("AAPL")
+	assert.NoError(t, err)
+	assert.Nil(t, quote)
+
+	_, err = provider.LatestQuote("DOWN")
+	assert.ErrorContains(t, err, "status 502")
+
+	_, err = provider.LatestQuote("FREE")
+	assert.ErrorContains(t, err, "invalid quote response")
+
+	assert.Equal(t, []string{"/quote/NKE", "/quote/AAPL", "/quote/DOWN", "/quote/FREE"}, requestedPaths)
+}
+
+func TestHTTPProviderUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewHTTPProvider(server.URL, nil).LatestQuote("NKE")
+	assert.ErrorContains(t, err, "failed to fetch quote")
+}
@@ -0,0 +1,48 @@
+package quotes
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// PriceProvider looks up the latest known price of a ticker. Like the
+// repositories, it returns nil without an error when the ticker is unknown.
+type PriceProvider interface {
+	LatestQuote(ticker string) (*models.Quote, error)
+}
+
+// NewProviderFromEnv reads quotes from the JSON fixture at QUOTES_FILE or the
+// quote service at QUOTES_BASE_URL, caching them in cache. With neither set,
+// only quotes already in the cache are served.
+func NewProviderFromEnv(cache db.QuoteRepository) PriceProvider {
+	return NewCachedProvider(cache, sourceFromEnv(), DefaultMaxAge)
+}
+
+func sourceFromEnv() PriceProvider {
+	if path := os.Getenv("QUOTES_FILE"); path != "" {
+		provider, err := LoadFileProvider(path)
+		if err != nil {
+			return misconfiguredProvider{err: fmt.Errorf("failed to load QUOTES_FILE: %w", err)}
+		}
+		return provider
+	}
+
+	if baseURL := os.Getenv("QUOTES_BASE_URL"); baseURL != "" {
+		return NewHTTPProvider(baseURL, nil)
+	}
+
+	return nil
+}
+
+// misconfiguredProvider fails every lookup so a bad deployment surfaces as
+// errors rather than silently missing prices.
+type misconfiguredProvider struct {
+	err error
+}
+
+func (p misconfiguredProvider) LatestQuote(_ string) (*models.Quote, error) {
+	return nil, p.err
+}
@@ -0,0 +1,59 @@
+package retirement
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	CatchUpAge         = 50
+	SuperCatchUpMinAge = 60
+	SuperCatchUpMaxAge = 63
+)
+
+// Limits are the IRS employee elective deferral limits for one plan year.
+// SuperCatchUp replaces CatchUp for ages 60 through 63 and is zero for years
+// before it existed.
+type Limits struct {
+	Elective     float64 `json:"elective"`
+	CatchUp      float64 `json:"catchUp"`
+	SuperCatchUp float64 `json:"superCatchUp"`
+}
+
+var annualLimits = map[int]Limits{
+	2023: {Elective: 22500, CatchUp: 7500},
+	2024: {Elective: 23000, CatchUp: 7500},
+	2025: {Elective: 23500, CatchUp: 7500, SuperCatchUp: 11250},
+	2026: {Elective: 24500, CatchUp: 8000, SuperCatchUp: 11250},
+}
+
+var ErrUnknownYear = errors.New("no 401k limits for year")
+
+func LimitsForYear(year int) (Limits, error) {
+	limits, ok := annualLimits[year]
+	if !ok {
+		return Limits{}, fmt.Errorf("%w %d", ErrUnknownYear, year)
+	}
+
+	return limits, nil
+}
+
+// CatchUpFor returns the catch-up amount allowed for someone who reaches age
+// by the end of the plan year.
+func (l Limits) CatchUpFor(age int) float64 {
+	if age >= SuperCatchUpMinAge && age <= SuperCatchUpMaxAge && l.SuperCatchUp > 0 {
+		return l.SuperCatchUp
+	}
+	if age >= CatchUpAge {
+		return l.CatchUp
+	}
+
+	return 0
+}
+
+// AgeAtYearEnd is the age attained by December 31 of year, which is what
+// catch-up eligibility is based on.
+func AgeAtYearEnd(birthDate time.Time, year int) int {
+	return year - birthDate.Year()
+}
@@ -0,0 +1,166 @@
+// Package retirement plans 401k contributions across the paychecks left in a
+// year.
+package retirement
+
+import (
+	"errors"
+	"math"
+)
+
+const maxContributionPercent = 100
+
+var ErrInvalidPlanInput = errors.New("invalid 401k plan input")
+
+type PlanInput struct {
+	Year               int
+	Age                int
+	AnnualSalary       float64
+	PaychecksPerYear   int
+	PaychecksRemaining int
+	ContributionsSoFar float64
+	EmployerMatchRate  float64
+	EmployerMatchLimit float64
+	RothPercent        float64
+	// ContributionLimit overrides the IRS limit for the year, catch-up
+	// included, when greater than zero.
+	ContributionLimit float64
+}
+
+type PaycheckContribution struct {
+	Paycheck           int     `json:"paycheck"`
+	Percent            int     `json:"percent"`
+	RothPercent        int     `json:"rothPercent"`
+	TraditionalPercent int     `json:"traditionalPercent"`
+	Contribution       float64 `json:"contribution"`
+	Roth               float64 `json:"roth"`
+	Traditional        float64 `json:"traditional"`
+	EmployerMatch      float64 `json:"employerMatch"`
+}
+
+type Plan struct {
+	Year                  int                    `json:"year"`
+	ContributionLimit     float64                `json:"contributionLimit"`
+	CatchUp               float64                `json:"catchUp"`
+	RemainingContribution float64                `json:"remainingContribution"`
+	GrossPerPaycheck      float64                `json:"grossPerPaycheck"`
+	Paychecks             []PaycheckContribution `json:"paychecks"`
+	TotalContribution     float64                `json:"totalContribution"`
+	TotalEmployerMatch    float64                `json:"totalEmployerMatch"`
+	MissedEmployerMatch   float64                `json:"missedEmployerMatch"`
+}
+
+// BuildPlan picks a whole contribution percent for each remaining paycheck so
+// the year's contributions reach the limit. Percents are spread as evenly as
+// whole numbers allow, with the higher ones last, so no paycheck falls below
+// the employer match threshold from contributing too much too early. The plan
+// assumes payroll stops contributions once the limit is reached.
+func BuildPlan(input PlanInput) (*Plan, error) {
+	if input.AnnualSalary <= 0 || input.PaychecksPerYear <= 0 ||
+		input.PaychecksRemaining <= 0 || input.PaychecksRemaining > input.PaychecksPerYear {
+		return nil, ErrInvalidPlanInput
+	}
+
+	plan := &Plan{Year: input.Year}
+
+	if input.ContributionLimit > 0 {
+		plan.ContributionLimit = input.ContributionLimit
+	} else {
+		limits, err := LimitsForYear(input.Year)
+		if err != nil {
+			return nil, err
+		}
+		plan.CatchUp = limits.CatchUpFor(input.Age)
+		plan.ContributionLimit = limits.Elective + plan.CatchUp
+	}
+
+	grossCents := toCents(input.AnnualSalary / float64(input.PaychecksPerYear))
+	remainingCents := max(toCents(plan.ContributionLimit-input.ContributionsSoFar), 0)
+	matchThresholdCents := grossCents * input.EmployerMatchLimit / 100
+
+	percents := spreadPercents(grossCents, remainingCents, input.PaychecksRemaining)
+
+	plan.GrossPerPaycheck = fromCents(grossCents)
+	plan.RemainingContribution = fromCents(remainingCents)
+	plan.Paychecks = make([]PaycheckContribution, len(percents))
+
+	leftCents := remainingCents
+	for i, percent := range percents {
+		contributionCents := min(percentOf(grossCents, percent), leftCents)
+		leftCents -= contributionCents
+
+		rothPercent := int(math.Round(float64(percent) * input.RothPercent / 100))
+		rothCents := 0.0
+		if percent > 0 {
+			rothCents = math.Round(contributionCents * float64(rothPercent) / float64(percent))
+		}
+
+		matchCents := math.Round(min(contributionCents, matchThresholdCents) * input.EmployerMatchRate / 100)
+		potentialMatchCents := math.Round(matchThresholdCents * input.EmployerMatchRate / 100)
+
+		plan.Paychecks[i] = PaycheckContribution{
+			Paycheck:           i + 1,
+			Percent:            percent,
+			RothPercent:        rothPercent,
+			TraditionalPercent: percent - rothPercent,
+			Contribution:       fromCents(contributionCents),
+			Roth:               fromCents(rothCents),
+			Traditional:        fromCents(contributionCents - rothCents),
+			EmployerMatch:      fromCents(matchCents),
+		}
+
+		plan.TotalContribution += contributionCents
+		plan.TotalEmployerMatch += matchCents
+		plan.MissedEmployerMatch += potentialMatchCents - matchCents
+	}
+
+	plan.TotalContribution = fromCents(plan.TotalContribution)
+	plan.TotalEmployerMatch = fromCents(plan.TotalEmployerMatch)
+	plan.MissedEmployerMatch = fromCents(plan.MissedEmployerMatch)
+
+	return plan, nil
+}
+
+// spreadPercents returns one whole percent per paycheck: the largest percent
+// every paycheck can take without passing the remaining amount, bumped by one
+// on just enough trailing paychecks to reach it.
+func spreadPercents(grossCents float64, remainingCents float64, paychecks int) []int {
+	base := 0
+	for base < maxContributionPercent && float64(paychecks)*percentOf(grossCents, base+1) <= remainingCents {
+		base++
+	}
+
+	percents := make([]int, paychecks)
+	for i := range percents {
+		percents[i] = base
+	}
+
+	if base == maxContributionPercent {
+		return percents
+	}
+
+	shortfall := remainingCents - float64(paychecks)*percentOf(grossCents, base)
+	step := percentOf(grossCents, base+1) - percentOf(grossCents, base)
+	if shortfall <= 0 || step <= 0 {
+		return percents
+	}
+
+	bumped := min(int(math.Ceil(shortfall/step)), paychecks)
+	for i := paychecks - bumped; i < paychecks; i++ {
+		percents[i] = base + 1
+	}
+
+	return percents
+}
+
+// percentOf is a whole percent of pay rounded to the cent, as payroll does.
+func percentOf(grossCents float64, percent int) float64 {
+	return math.Round(grossCents * float64(percent) / 100)
+}
+
+func toCents(amount float64) float64 {
+	return math.Round(amount * 100)
+}
+
+func fromCents(cents float64) float64 {
+	return cents / 100
+}
@@ -0,0 +1,252 @@
+package retirement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPlan(t *testing.T) {
+	testCases := []struct {
+		name                      string
+		input                     PlanInput
+		expectedLimit             float64
+		expectedPercents          []int
+		expectedTotalContribution float64
+		expectedTotalMatch        float64
+		expectedMissedMatch       float64
+	}{
+		{
+			name: "whole year spread evenly with higher percents last",
+			input: PlanInput{
+				Year:               2026,
+				AnnualSalary:       130000,
+				PaychecksPerYear:   26,
+				PaychecksRemaining: 26,
+				EmployerMatchRate:  100,
+				EmployerMatchLimit: 6,
+			},
+			expectedLimit:             24500,
+			expectedPercents:          append(repeat(18, 4), repeat(19, 22)...),
+			expectedTotalContribution: 24500,
+			expectedTotalMatch:        7800,
+			expectedMissedMatch:       0,
+		},
+		{
+			name: "mid year with contributions so far",
+			input: PlanInput{
+				Year:               2026,
+				AnnualSalary:       120000,
+				PaychecksPerYear:   24,
+				PaychecksRemaining: 10,
+				ContributionsSoFar: 10000,
+				EmployerMatchRate:  50,
+				EmployerMatchLimit: 6,
+			},
+			expectedLimit:             24500,
+			expectedPercents:          []int{29, 29, 29, 29, 29, 29, 29, 29, 29, 29},
+			expectedTotalContribution: 14500,
+			expectedTotalMatch:        1500,
+			expectedMissedMatch:       0,
+		},
+		{
+			name: "catch-up contributions at 55",
+			input: PlanInput{
+				Year:               2026,
+				Age:                55,
+				AnnualSalary:       200000,
+				PaychecksPerYear:   26,
+				PaychecksRemaining: 26,
+			},
+			expectedLimit:             32500,
+			expectedPercents:          append(repeat(16, 19), repeat(17, 7)...),
+			expectedTotalContribution: 32500,
+		},
+		{
+			name: "limit binds before the match threshold",
+			input: PlanInput{
+				Year:               2026,
+				AnnualSalary:       100000,
+				PaychecksPerYear:   12,
+				PaychecksRemaining: 2,
+				ContributionsSoFar: 24000,
+				EmployerMatchRate:  100,
+				EmployerMatchLimit: 6,
+			},
+			expectedLimit:             24500,
+			expectedPercents:          []int{3, 3},
+			expectedTotalContribution: 500,
+			expectedTotalMatch:        500,
+			expectedMissedMatch:       500,
+		},
+		{
+			name: "limit already reached",
+			input: PlanInput{
+				Year:               2026,
+				AnnualSalary:       100000,
+				PaychecksPerYear:   12,
+				PaychecksRemaining: 2,
+				ContributionsSoFar: 25000,
+				EmployerMatchRate:  100,
+				EmployerMatchLimit: 4,
+			},
+			expectedLimit:             24500,
+			expectedPercents:          []int{0, 0},
+			expectedTotalContribution: 0,
+			expectedTotalMatch:        0,
+			expectedMissedMatch:       666.66,
+		},
+		{
+			name: "salary too low to reach the limit",
+			input: PlanInput{
+				Year:               2026,
+				AnnualSalary:       12000,
+				PaychecksPerYear:   12,
+				PaychecksRemaining: 3,
+			},
+			expectedLimit:             24500,
+			expectedPercents:          []int{100, 100, 100},
+			expectedTotalContribution: 3000,
+		},
+		{
+			name: "overridden limit",
+			input: PlanInput{
+				Year:               2030,
+				AnnualSalary:       120000,
+				PaychecksPerYear:   12,
+				PaychecksRemaining: 12,
+				ContributionLimit:  12000,
+			},
+			expectedLimit:             12000,
+			expectedPercents:          repeat(10, 12),
+			expectedTotalContribution: 12000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := BuildPlan(tc.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLimit, plan.ContributionLimit)
+
+			percents := []int{}
+			for _, paycheck := range plan.Paychecks {
+				percents = append(percents, paycheck.Percent)
+			}
+			assert.Equal(t, tc.expectedPercents, percents)
+
+			assert.InDelta(t, tc.expectedTotalContribution, plan.TotalContribution, 0.001)
+			assert.InDelta(t, tc.expectedTotalMatch, plan.TotalEmployerMatch, 0.001)
+			assert.InDelta(t, tc.expectedMissedMatch, plan.MissedEmployerMatch, 0.001)
+		})
+	}
+}
+
+func TestBuildPlanCapsFinalPaycheck(t *testing.T) {
+	plan, err := BuildPlan(PlanInput{
+		Year:               2026,
+		AnnualSalary:       50000,
+		PaychecksPerYear:   26,
+		PaychecksRemaining: 26,
+		EmployerMatchRate:  100,
+		EmployerMatchLimit: 6,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1923.08, plan.GrossPerPaycheck)
+	assert.InDelta(t, 24500, plan.TotalContribution, 0.001)
+	assert.Equal(t, 49, plan.Paychecks[25].Percent)
+	assert.Equal(t, 942.25, plan.Paychecks[25].Contribution)
+	assert.InDelta(t, 0, plan.MissedEmployerMatch, 0.001)
+}
+
+func TestBuildPlanRothSplit(t *testing.T) {
+	plan, err := BuildPlan(PlanInput{
+		Year:               2026,
+		AnnualSalary:       130000,
+		PaychecksPerYear:   26,
+		PaychecksRemaining: 26,
+		RothPercent:        25,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, PaycheckContribution{
+		Paycheck:           26,
+		Percent:            19,
+		RothPercent:        5,
+		TraditionalPercent: 14,
+		Contribution:       950,
+		Roth:               250,
+		Traditional:        700,
+	}, plan.Paychecks[25])
+}
+
+func TestBuildPlanInvalidInput(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         PlanInput
+		expectedError error
+	}{
+		{
+			name:          "no salary",
+			input:         PlanInput{Year: 2026, PaychecksPerYear: 26, PaychecksRemaining: 1},
+			expectedError: ErrInvalidPlanInput,
+		},
+		{
+			name:          "more paychecks remaining than per year",
+			input:         PlanInput{Year: 2026, AnnualSalary: 100000, PaychecksPerYear: 12, PaychecksRemaining: 13},
+			expectedError: ErrInvalidPlanInput,
+		},
+		{
+			name:          "no paychecks remaining",
+			input:         PlanInput{Year: 2026, AnnualSalary: 100000, PaychecksPerYear: 12},
+			expectedError: ErrInvalidPlanInput,
+		},
+		{
+			name:          "unknown year",
+			input:         PlanInput{Year: 2030, AnnualSalary: 100000, PaychecksPerYear: 12, PaychecksRemaining: 12},
+			expectedError: ErrUnknownYear,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := BuildPlan(tc.input)
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, plan)
+		})
+	}
+}
+
+func TestCatchUpFor(t *testing.T) {
+	limits2026, err := LimitsForYear(2026)
+	assert.NoError(t, err)
+	limits2024, err := LimitsForYear(2024)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0.0, limits2026.CatchUpFor(49))
+	assert.Equal(t, 8000.0, limits2026.CatchUpFor(50))
+	assert.Equal(t, 11250.0, limits2026.CatchUpFor(60))
+	assert.Equal(t, 11250.0, limits2026.CatchUpFor(63))
+	assert.Equal(t, 8000.0, limits2026.CatchUpFor(64))
+	assert.Equal(t, 7500.0, limits2024.CatchUpFor(61))
+}
+
+func TestAgeAtYearEnd(t *testing.T) {
+	birthDate := time.Date(1976, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 50, AgeAtYearEnd(birthDate, 2026))
+	assert.Equal(t, 49, AgeAtYearEnd(birthDate, 2025))
+}
+
+func repeat(percent int, count int) []int {
+	percents := make([]int, count)
+	for i := range percents {
+		percents[i] = percent
+	}
+
+	return percents
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/constants"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/handlers"
+	"github.com/ljhurst/fife/pkg/quotes"
+)
+
+type Deps struct {
+	Verifier         auth.Verifier
+	UserRepo         db.UserRepository
+	EsppLotRepo      db.EsppLotRepository
+	EsppSaleRepo     db.EsppSaleRepository
+	QuoteProvider    quotes.PriceProvider
+	PriceHistoryRepo db.PriceHistoryRepository
+	EsppPlanRepo     db.EsppPlanRepository
+}
+
+// NewRouter mounts every Lambda handler on the same routes API Gateway serves.
+func NewRouter(deps Deps) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("POST /espp/lot", authenticated(deps, handlers.CreateEsppLot(deps.EsppLotRepo, deps.PriceHistoryRepo, deps.EsppPlanRepo)))
+	mux.Handle("GET /espp/lot/{lotId}", authenticated(deps, handlers.GetEsppLot(deps.EsppLotRepo), constants.PathLotID))
+	mux.Handle("PUT /espp/lot/{lotId}", authenticated(deps, handlers.UpdateEsppLot(deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathLotID))
+	mux.Handle("PATCH /espp/lot/{lotId}", authenticated(deps, handlers.UpdateEsppLot(deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathLotID))
+	mux.Handle("DELETE /espp/lot/{lotId}", authenticated(deps, handlers.DeleteEsppLot(deps.EsppLotRepo, deps.EsppSaleRepo), constants.PathLotID))
+	mux.Handle("POST /espp/lot/{lotId}/sale", authenticated(deps, handlers.CreateEsppSale(deps.EsppLotRepo, deps.EsppSaleRepo), constants.PathLotID))
+	mux.Handle("GET /espp/lot/{lotId}/sale", authenticated(deps, handlers.ListEsppSales(deps.EsppLotRepo, deps.EsppSaleRepo), constants.PathLotID))
+	mux.Handle("DELETE /espp/lot/{lotId}/sale/{saleId}", authenticated(deps, handlers.DeleteEsppSale(deps.EsppLotRepo, deps.EsppSaleRepo), constants.PathLotID, constants.PathSaleID))
+	mux.Handle("POST /espp/plan", authenticated(deps, handlers.CreateEsppPlan(deps.EsppPlanRepo)))
+	mux.Handle("GET /espp/plan/{planId}", authenticated(deps, handlers.GetEsppPlan(deps.EsppPlanRepo), constants.PathPlanID))
+	mux.Handle("PUT /espp/plan/{planId}", authenticated(deps, handlers.UpdateEsppPlan(deps.EsppPlanRepo), constants.PathPlanID))
+	mux.Handle("DELETE /espp/plan/{planId}", authenticated(deps, handlers.DeleteEsppPlan(deps.EsppPlanRepo, deps.EsppLotRepo), constants.PathPlanID))
+	mux.Handle("GET /quote/{ticker}", authenticated(deps, handlers.GetQuote(deps.QuoteProvider), constants.PathTicker))
+	mux.Handle("POST /quote/{ticker}/history", authenticated(deps, handlers.ImportQuoteHistory(deps.PriceHistoryRepo), constants.PathTicker))
+	mux.Handle("GET /user/{userId}", authenticated(deps, handlers.GetUser(deps.UserRepo), constants.PathUserID))
+	mux.Handle("PUT /user/{userId}", authenticated(deps, handlers.UpdateUserSettings(deps.UserRepo), constants.PathUserID))
+	mux.Handle("DELETE /user/{userId}", authenticated(deps, handlers.DeleteUser(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/archive", authenticated(deps, handlers.ExportUserArchive(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("POST /user/{userId}/archive/restore", authenticated(deps, handlers.RestoreUserArchive(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/calendar.ics", adapt(handlers.GetUserCalendar(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo), constants.PathUserID))
+	mux.Handle("POST /user/{userId}/calendar-token", authenticated(deps, handlers.CreateUserCalendarToken(deps.UserRepo), constants.PathUserID))
+	mux.Handle("DELETE /user/{userId}/calendar-token", authenticated(deps, handlers.RevokeUserCalendarToken(deps.UserRepo), constants.PathUserID))
+	mux.Handle("POST /user/{userId}/401k/plan", authenticated(deps, handlers.PlanUser401k(deps.UserRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/espp-lot", authenticated(deps, handlers.ListUserEsppLots(deps.EsppLotRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/espp-lot/export", authenticated(deps, handlers.ExportUserEsppLots(deps.EsppLotRepo), constants.PathUserID))
+	mux.Handle("POST /user/{userId}/espp-lot/import", authenticated(deps, handlers.ImportUserEsppLots(deps.EsppLotRepo, deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/espp-lot/limit", authenticated(deps, handlers.GetUserEsppLotLimit(deps.EsppLotRepo, deps.EsppPlanRepo, deps.PriceHistoryRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/espp-lot/taxes", authenticated(deps, handlers.GetUserEsppLotTaxes(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo, deps.QuoteProvider), constants.PathUserID))
+	mux.Handle("POST /user/{userId}/espp/sell-plan", authenticated(deps, handlers.PlanUserEsppSale(deps.UserRepo, deps.EsppLotRepo, deps.EsppSaleRepo, deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/espp-plan", authenticated(deps, handlers.ListUserEsppPlans(deps.EsppPlanRepo), constants.PathUserID))
+	mux.Handle("GET /user/{userId}/paycheck/remaining", authenticated(deps, handlers.GetUserPaychecksRemaining(deps.UserRepo), constants.PathUserID))
+
+	return withCORS(mux)
+}
+
+func authenticated(deps Deps, handler handlers.Handler, pathParams ...string) http.Handler {
+	return adapt(auth.Authenticate(deps.Verifier, handler), pathParams...)
+}
+
+// adapt translates an HTTP request into the API Gateway proxy event the
+// handler expects and writes the proxy response back out.
+func adapt(handler handlers.Handler, pathParams ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := toProxyRequest(r, pathParams)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		response, err := handler(r.Context(), request)
+		if err != nil {
+			slog.Error("Handler failed", slog.String("path", r.URL.Path), slog.Any("error", err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeProxyResponse(w, response)
+	})
+}
+
+func toProxyRequest(r *http.Request, pathParams []string) (events.APIGatewayProxyRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+
+	request := events.APIGatewayProxyRequest{
+		Resource:                        r.Pattern,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         map[string]string{},
+		MultiValueHeaders:               map[string][]string{},
+		QueryStringParameters:           map[string]string{},
+		MultiValueQueryStringParameters: map[string][]string{},
+		PathParameters:                  map[string]string{},
+		Body:                            string(body),
+	}
+
+	for name, values := range r.Header {
+		request.Headers[name] = values[len(values)-1]
+		request.MultiValueHeaders[name] = values
+	}
+
+	for name, values := range r.URL.Query() {
+		request.QueryStringParameters[name] = values[len(values)-1]
+		request.MultiValueQueryStringParameters[name] = values
+	}
+
+	for _, param := range pathParams {
+		request.PathParameters[param] = r.PathValue(param)
+	}
+
+	return request, nil
+}
+
+func writeProxyResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+
+	for name, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			slog.Error("Failed to decode response body", slog.Any("error", err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		body = decoded
+	}
+
+	w.WriteHeader(response.StatusCode)
+	if _, err := w.Write(body); err != nil {
+		slog.Error("Failed to write response body", slog.Any("error", err))
+	}
+}
+
+// withCORS answers browser preflight requests the way API Gateway does, so the
+// frontend dev server can call the local API.
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,445 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ljhurst/fife/pkg/auth"
+	"github.com/ljhurst/fife/pkg/db"
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/ljhurst/fife/pkg/quotes"
+	"github.com/stretchr/testify/assert"
+)
+
+type testServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	verifier := auth.NewCognitoVerifier(auth.CognitoVerifierConfig{
+		Issuer: "local",
+		Keys:   auth.NewStaticKeySet(map[string]*rsa.PublicKey{"local": &key.PublicKey}),
+	})
+
+	srv := httptest.NewServer(NewRouter(Deps{
+		Verifier:     verifier,
+		UserRepo:     db.NewMemoryUserRepository(),
+		EsppLotRepo:  db.NewMemoryEsppLotRepository(),
+		EsppSaleRepo: db.NewMemoryEsppSaleRepository(),
+		QuoteProvider: quotes.NewFileProvider([]models.Quote{
+			{Ticker: "NKE", Price: 97.5, Date: "2026-10-16"},
+		}),
+		PriceHistoryRepo: db.NewMemoryPriceHistoryRepository(),
+		EsppPlanRepo:     db.NewMemoryEsppPlanRepository(),
+	}))
+	t.Cleanup(srv.Close)
+
+	return &testServer{Server: srv, key: key}
+}
+
+func (s *testServer) token(t *testing.T, userID string, groups ...string) string {
+	t.Helper()
+
+	token, err := auth.SignToken(s.key, "local", auth.Claims{
+		Subject:   userID,
+		Issuer:    "local",
+		TokenUse:  auth.TokenUseID,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Groups:    groups,
+	})
+	assert.NoError(t, err)
+
+	return token
+}
+
+func doRequest(t *testing.T, method string, url string, token string, body string) (int, string, http.Header) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	return resp.StatusCode, string(respBody), resp.Header
+}
+
+func TestEsppLotRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+	adminToken := srv.token(t, "admin123", auth.AdminGroup)
+
+	status, _, _ := doRequest(t, http.MethodPost, srv.URL+"/quote/nke/history", token, "Date,Close\n2022-12-30,100.00\n")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, body, _ := doRequest(t, http.MethodPost, srv.URL+"/quote/nke/history", adminToken, "Date,Close\n2022-12-30,100.00\n2023-06-30,120.00\n")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"ticker":"NKE","imported":2,"from":"2022-12-30","to":"2023-06-30"}`, body)
+
+	// The offer prices are filled in from the imported closes.
+	status, body, headers := doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{
+		"userId": "user123",
+		"grantDate": "2023-01-01",
+		"purchaseDate": "2023-06-30",
+		"purchasePrice": 85.0,
+		"shares": 10.0,
+		"ticker": "nke"
+	}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Contains(t, body, `"offerStartPrice":100,"offerEndPrice":120`)
+
+	var createdLot models.EsppLot
+	assert.NoError(t, json.Unmarshal([]byte(body), &createdLot))
+	assert.NotEmpty(t, createdLot.ID)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/espp/lot/"+createdLot.ID, token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"id":"`+createdLot.ID+`"`)
+
+	status, body, _ = doRequest(t, http.MethodPatch, srv.URL+"/espp/lot/"+createdLot.ID, token, `{"purchasePrice":80}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"purchasePrice":80`)
+	assert.Contains(t, body, `"shares":10`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot?ticker=NKE", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, createdLot.ID)
+	assert.Contains(t, body, `"ticker":"NKE"`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot?ticker=AAPL", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"items":[]}`, body)
+
+	status, body, _ = doRequest(t, http.MethodPost, srv.URL+"/espp/lot/"+createdLot.ID+"/sale", token, `{"date":"2024-07-01","price":150,"shares":4}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	var createdSale models.EsppSale
+	assert.NoError(t, json.Unmarshal([]byte(body), &createdSale))
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/espp/lot/"+createdLot.ID+"/sale", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, createdSale.ID)
+
+	status, body, _ = doRequest(t, http.MethodPost, srv.URL+"/user/user123/espp/sell-plan", token, `{"shares":2,"date":"2024-08-01","price":150}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"optimized":{"strategy":"optimized","lots":[{"lotId":"`+createdLot.ID+`","shares":2`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot/taxes?marketPrices=NKE:150", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"marketPrices":{"NKE":150}`)
+	assert.Contains(t, body, createdSale.ID)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/quote/nke", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"price":97.5`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot/taxes", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"marketPrices":{"NKE":97.5}`)
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/espp/lot/"+createdLot.ID+"/sale/"+createdSale.ID, token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/espp/lot/"+createdLot.ID, token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/espp/lot/"+createdLot.ID, token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, `{"error":"ESPP lot not found"}`, body)
+}
+
+func TestEsppPlanRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, body, _ := doRequest(t, http.MethodPost, srv.URL+"/espp/plan", token, `{"name":"Acme ESPP","employer":"Acme","discountPercent":10,"lookback":false,"offeringPeriodMonths":6,"purchasePeriodMonths":6}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	var createdPlan models.EsppPlan
+	assert.NoError(t, json.Unmarshal([]byte(body), &createdPlan))
+
+	status, body, _ = doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{
+		"grantDate": "2023-01-01",
+		"purchaseDate": "2023-06-30",
+		"offerStartPrice": 100.0,
+		"offerEndPrice": 120.0,
+		"purchasePrice": 108.0,
+		"shares": 10.0,
+		"employer": "Acme",
+		"planId": "`+createdPlan.ID+`"
+	}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	var createdLot models.EsppLot
+	assert.NoError(t, json.Unmarshal([]byte(body), &createdLot))
+
+	// Without a lookback the discount applies to the offering end price.
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot/taxes?marketPrice=150", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"planId":"`+createdPlan.ID+`","marketPrice":150,"purchaseMarketPrice":120`)
+
+	status, body, _ = doRequest(t, http.MethodPut, srv.URL+"/espp/plan/"+createdPlan.ID, token, `{"name":"Acme ESPP","discountPercent":15,"lookback":true,"offeringPeriodMonths":6,"purchasePeriodMonths":6}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"lookback":true`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-plan", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, createdPlan.ID)
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/espp/plan/"+createdPlan.ID, token, "")
+	assert.Equal(t, http.StatusConflict, status)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot/limit?employer=acme&grantDate=2024-01-01&purchaseDate=2024-06-28&offerStartPrice=100", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"years":[{"year":2023,"limit":25000,"accrued":1000,"remaining":24000,"excess":0},{"year":2024,"limit":25000,"accrued":0,"remaining":25000,"excess":0}]`)
+	assert.Contains(t, body, `"headroom":25000,"maxShares":250`)
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/espp/lot/"+createdLot.ID, token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/espp/plan/"+createdPlan.ID, token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = doRequest(t, http.MethodGet, srv.URL+"/espp/plan/"+createdPlan.ID, token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestImportExportRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, body, _ := doRequest(t, http.MethodPost, srv.URL+"/user/user123/espp-lot/import", token, "grantDate,purchaseDate,offerStartPrice,offerEndPrice,purchasePrice,shares\n2023-01-01,2023-06-30,100,120,85,10\n")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"succeeded":1`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"purchaseDate":"2023-06-30"`)
+
+	status, exported, headers := doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot/export?format=csv", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "text/csv; charset=utf-8", headers.Get("Content-Type"))
+
+	status, body, _ = doRequest(t, http.MethodPost, srv.URL+"/user/user123/espp-lot/import?dryRun=true", token, exported)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"succeeded":1,"failed":0`)
+}
+
+func TestArchiveRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, _, _ := doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, archive, headers := doRequest(t, http.MethodGet, srv.URL+"/user/user123/archive", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `attachment; filename="fife-archive.json"`, headers.Get("Content-Disposition"))
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body, _ := doRequest(t, http.MethodPost, srv.URL+"/user/user123/archive/restore?mode=replace", token, archive)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"restored":{"lots":1,"sales":0,"plans":0}`)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"purchaseDate":"2023-06-30"`)
+
+	status, _, _ = doRequest(t, http.MethodPost, srv.URL+"/user/user123/archive/restore", srv.token(t, "user456"), archive)
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestUserRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, _, _ := doRequest(t, http.MethodGet, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _, _ = doRequest(t, http.MethodPut, srv.URL+"/user/user123", token, `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body, _ := doRequest(t, http.MethodGet, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"finance":{"annualSalary":120000,"paychecksPerYear":24}`)
+
+	status, _, _ = doRequest(t, http.MethodPut, srv.URL+"/user/user123", token, `{"finance":{"annualSalary":120000,"paychecksPerYear":24,"paySchedule":{"frequency":"monthly","anchorDate":"2026-01-31"}}}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/paycheck/remaining?date=2026-11-01", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"dates":["2026-11-30","2026-12-31"]`)
+
+	status, body, _ = doRequest(t, http.MethodPost, srv.URL+"/user/user123/401k/plan", token, `{"year":2026,"paychecksRemaining":12}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"contributionLimit":24500`)
+
+	status, _, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123", srv.token(t, "user456"), "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, body, _ = doRequest(t, http.MethodDelete, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"userId":"user123","lots":1,"sales":0,"plans":0}`, body)
+
+	status, _, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestConditionalWrites(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	ifMatch := func(method string, url string, etag string, body string) (int, http.Header) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", etag)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode, resp.Header
+	}
+
+	status, _, headers := doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, `"1"`, headers.Get("ETag"))
+	assert.Equal(t, "ETag", headers.Get("Access-Control-Expose-Headers"))
+
+	var lots struct {
+		Items []models.EsppLot `json:"items"`
+	}
+	_, body, _ := doRequest(t, http.MethodGet, srv.URL+"/user/user123/espp-lot", token, "")
+	assert.NoError(t, json.Unmarshal([]byte(body), &lots))
+	lotURL := srv.URL + "/espp/lot/" + lots.Items[0].ID
+
+	status, headers = ifMatch(http.MethodPatch, lotURL, `"1"`, `{"shares":12}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `"2"`, headers.Get("ETag"))
+
+	status, _ = ifMatch(http.MethodPatch, lotURL, `"1"`, `{"shares":11}`)
+	assert.Equal(t, http.StatusPreconditionFailed, status)
+
+	status, _, _ = doRequest(t, http.MethodPut, srv.URL+"/user/user123", token, `{"finance":{"annualSalary":120000,"paychecksPerYear":24}}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, headers = doRequest(t, http.MethodGet, srv.URL+"/user/user123", token, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `"1"`, headers.Get("ETag"))
+
+	status, _ = ifMatch(http.MethodPut, srv.URL+"/user/user123", `"1"`, `{"finance":{"annualSalary":130000,"paychecksPerYear":24}}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = ifMatch(http.MethodPut, srv.URL+"/user/user123", `"1"`, `{"finance":{"annualSalary":140000,"paychecksPerYear":24}}`)
+	assert.Equal(t, http.StatusPreconditionFailed, status)
+
+	_, body, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123", token, "")
+	assert.Contains(t, body, `"annualSalary":130000`)
+}
+
+func TestCalendarRoutes(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, _, _ := doRequest(t, http.MethodPost, srv.URL+"/espp/lot", token, `{"grantDate":"2023-01-01","purchaseDate":"2023-06-30","offerStartPrice":100,"offerEndPrice":120,"purchasePrice":85,"shares":10}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	// Tokens are only issued to users who exist.
+	status, _, _ = doRequest(t, http.MethodPost, srv.URL+"/user/user123/calendar-token", token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _, _ = doRequest(t, http.MethodPut, srv.URL+"/user/user123", token, `{"finance":{"annualSalary":120000,"paychecksPerYear":26}}`)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body, _ := doRequest(t, http.MethodPost, srv.URL+"/user/user123/calendar-token", token, "")
+	assert.Equal(t, http.StatusCreated, status)
+
+	var created map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(body), &created))
+
+	// Calendar apps send no bearer token.
+	status, body, headers := doRequest(t, http.MethodGet, srv.URL+"/user/user123/calendar.ics?token="+created["token"], "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "text/calendar; charset=utf-8", headers.Get("Content-Type"))
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20250101\r\n")
+
+	status, _, _ = doRequest(t, http.MethodDelete, srv.URL+"/user/user123/calendar-token", token, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = doRequest(t, http.MethodGet, srv.URL+"/user/user123/calendar.ics?token="+created["token"], "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestRoutesRequireToken(t *testing.T) {
+	srv := newTestServer(t)
+
+	status, body, _ := doRequest(t, http.MethodGet, srv.URL+"/user/user123", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, `{"error":"Unauthorized"}`, body)
+}
+
+func TestUnknownRoute(t *testing.T) {
+	srv := newTestServer(t)
+	token := srv.token(t, "user123")
+
+	status, _, _ := doRequest(t, http.MethodPatch, srv.URL+"/espp/lot", token, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+
+	status, _, _ = doRequest(t, http.MethodGet, srv.URL+"/nope", token, "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestPreflight(t *testing.T) {
+	srv := newTestServer(t)
+
+	status, _, headers := doRequest(t, http.MethodOptions, srv.URL+"/user/user123", "", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, "*", headers.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, headers.Get("Access-Control-Allow-Methods"), "PUT")
+}
+
+func TestToProxyRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/user/user123/espp-lot?limit=5&limit=10", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.SetPathValue("userId", "user123")
+
+	request, err := toProxyRequest(req, []string{"userId"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, request.HTTPMethod)
+	assert.Equal(t, "/user/user123/espp-lot", request.Path)
+	assert.Equal(t, map[string]string{"userId": "user123"}, request.PathParameters)
+	assert.Equal(t, "10", request.QueryStringParameters["limit"])
+	assert.Equal(t, []string{"5", "10"}, request.MultiValueQueryStringParameters["limit"])
+	assert.Equal(t, "Bearer token", request.Headers["Authorization"])
+}
@@ -0,0 +1,170 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ljhurst/fife/pkg/models"
+)
+
+// Bracket taxes income above Floor at Rate, up to the next bracket's floor.
+type Bracket struct {
+	Floor float64 `json:"floor"`
+	Rate  float64 `json:"rate"`
+}
+
+// Table holds the federal figures for one tax year and filing status. Rates are
+// fractions and brackets are sorted by floor.
+type Table struct {
+	Year                 int       `json:"year"`
+	FilingStatus         string    `json:"filingStatus"`
+	StandardDeduction    float64   `json:"standardDeduction"`
+	Ordinary             []Bracket `json:"ordinary"`
+	LongTermCapitalGains []Bracket `json:"longTermCapitalGains"`
+	NIITThreshold        float64   `json:"niitThreshold"`
+}
+
+var ErrUnknownFilingStatus = errors.New("unknown filing status")
+
+// niitThresholds are set by statute and are not adjusted for inflation.
+var niitThresholds = map[string]float64{
+	models.FilingStatusSingle:                  200000,
+	models.FilingStatusMarriedFilingJointly:    250000,
+	models.FilingStatusMarriedFilingSeparately: 125000,
+	models.FilingStatusHeadOfHousehold:         200000,
+}
+
+func ordinaryBrackets(floors ...float64) []Bracket {
+	rates := []float64{0.10, 0.12, 0.22, 0.24, 0.32, 0.35, 0.37}
+
+	brackets := make([]Bracket, len(rates))
+	for i, rate := range rates {
+		brackets[i] = Bracket{Floor: floors[i], Rate: rate}
+	}
+
+	return brackets
+}
+
+func longTermCapitalGainsBrackets(fifteenPercentFloor float64, twentyPercentFloor float64) []Bracket {
+	return []Bracket{
+		{Floor: 0, Rate: 0},
+		{Floor: fifteenPercentFloor, Rate: 0.15},
+		{Floor: twentyPercentFloor, Rate: 0.20},
+	}
+}
+
+// tables are keyed by year, then filing status. Figures come from the IRS
+// inflation adjustment revenue procedures for each year, with the 2025
+// standard deductions as amended by Public Law 119-21.
+var tables = map[int]map[string]Table{
+	2023: {
+		models.FilingStatusSingle: {
+			StandardDeduction:    13850,
+			Ordinary:             ordinaryBrackets(0, 11000, 44725, 95375, 182100, 231250, 578125),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(44625, 492300),
+		},
+		models.FilingStatusMarriedFilingJointly: {
+			StandardDeduction:    27700,
+			Ordinary:             ordinaryBrackets(0, 22000, 89450, 190750, 364200, 462500, 693750),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(89250, 553850),
+		},
+		models.FilingStatusMarriedFilingSeparately: {
+			StandardDeduction:    13850,
+			Ordinary:             ordinaryBrackets(0, 11000, 44725, 95375, 182100, 231250, 346875),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(44625, 276900),
+		},
+		models.FilingStatusHeadOfHousehold: {
+			StandardDeduction:    20800,
+			Ordinary:             ordinaryBrackets(0, 15700, 59850, 95350, 182100, 231250, 578100),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(59750, 523050),
+		},
+	},
+	2024: {
+		models.FilingStatusSingle: {
+			StandardDeduction:    14600,
+			Ordinary:             ordinaryBrackets(0, 11600, 47150, 100525, 191950, 243725, 609350),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(47025, 518900),
+		},
+		models.FilingStatusMarriedFilingJointly: {
+			StandardDeduction:    29200,
+			Ordinary:             ordinaryBrackets(0, 23200, 94300, 201050, 383900, 487450, 731200),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(94050, 583750),
+		},
+		models.FilingStatusMarriedFilingSeparately: {
+			StandardDeduction:    14600,
+			Ordinary:             ordinaryBrackets(0, 11600, 47150, 100525, 191950, 243725, 365600),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(47025, 291850),
+		},
+		models.FilingStatusHeadOfHousehold: {
+			StandardDeduction:    21900,
+			Ordinary:             ordinaryBrackets(0, 16550, 63100, 100500, 191950, 243700, 609350),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(63000, 551350),
+		},
+	},
+	2025: {
+		models.FilingStatusSingle: {
+			StandardDeduction:    15750,
+			Ordinary:             ordinaryBrackets(0, 11925, 48475, 103350, 197300, 250525, 626350),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(48350, 533400),
+		},
+		models.FilingStatusMarriedFilingJointly: {
+			StandardDeduction:    31500,
+			Ordinary:             ordinaryBrackets(0, 23850, 96950, 206700, 394600, 501050, 751600),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(96700, 600050),
+		},
+		models.FilingStatusMarriedFilingSeparately: {
+			StandardDeduction:    15750,
+			Ordinary:             ordinaryBrackets(0, 11925, 48475, 103350, 197300, 250525, 375800),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(48350, 300000),
+		},
+		models.FilingStatusHeadOfHousehold: {
+			StandardDeduction:    23625,
+			Ordinary:             ordinaryBrackets(0, 17000, 64850, 103350, 197300, 250500, 626350),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(64750, 566700),
+		},
+	},
+	2026: {
+		models.FilingStatusSingle: {
+			StandardDeduction:    16100,
+			Ordinary:             ordinaryBrackets(0, 12400, 50400, 105700, 201775, 256225, 640600),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(49450, 545500),
+		},
+		models.FilingStatusMarriedFilingJointly: {
+			StandardDeduction:    32200,
+			Ordinary:             ordinaryBrackets(0, 24800, 100800, 211400, 403550, 512450, 768700),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(98900, 613700),
+		},
+		models.FilingStatusMarriedFilingSeparately: {
+			StandardDeduction:    16100,
+			Ordinary:             ordinaryBrackets(0, 12400, 50400, 105700, 201775, 256225, 384350),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(49450, 306850),
+		},
+		models.FilingStatusHeadOfHousehold: {
+			StandardDeduction:    24150,
+			Ordinary:             ordinaryBrackets(0, 17700, 67450, 105700, 201750, 256200, 640600),
+			LongTermCapitalGains: longTermCapitalGainsBrackets(66200, 579600),
+		},
+	},
+}
+
+const (
+	firstTableYear = 2023
+	lastTableYear  = 2026
+)
+
+// TableFor returns the table for year and filing status. Years outside the
+// known tables use the closest one, since brackets only move with inflation.
+func TableFor(year int, filingStatus string) (*Table, error) {
+	year = min(max(year, firstTableYear), lastTableYear)
+
+	table, ok := tables[year][filingStatus]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFilingStatus, filingStatus)
+	}
+
+	table.Year = year
+	table.FilingStatus = filingStatus
+	table.NIITThreshold = niitThresholds[filingStatus]
+
+	return &table, nil
+}
@@ -0,0 +1,138 @@
+// Package tax estimates federal income tax from bracket tables, so income that
+// crosses a bracket is taxed at every rate it touches.
+package tax
+
+import "github.com/ljhurst/fife/pkg/models"
+
+const (
+	NIITRate = 0.038
+
+	// Net capital losses beyond these amounts carry forward instead of
+	// offsetting ordinary income this year.
+	capitalLossLimit                  = 3000
+	capitalLossLimitMarriedSeparately = 1500
+)
+
+// Income splits income by how it is taxed. Short-term gains are taxed as
+// ordinary income but are netted against long-term losses first.
+type Income struct {
+	Ordinary              float64 `json:"ordinary"`
+	ShortTermCapitalGains float64 `json:"shortTermCapitalGains"`
+	LongTermCapitalGains  float64 `json:"longTermCapitalGains"`
+}
+
+func (i Income) Add(other Income) Income {
+	return Income{
+		Ordinary:              i.Ordinary + other.Ordinary,
+		ShortTermCapitalGains: i.ShortTermCapitalGains + other.ShortTermCapitalGains,
+		LongTermCapitalGains:  i.LongTermCapitalGains + other.LongTermCapitalGains,
+	}
+}
+
+// Breakdown attributes a tax amount to the kind of income that caused it.
+type Breakdown struct {
+	Ordinary              float64 `json:"ordinary"`
+	ShortTermCapitalGains float64 `json:"shortTermCapitalGains"`
+	LongTermCapitalGains  float64 `json:"longTermCapitalGains"`
+	Total                 float64 `json:"total"`
+}
+
+// Calculator prices income added on top of a base income, usually salary.
+// StateRate is a flat fraction applied to adjusted gross income, and NIIT adds
+// the net investment income tax above the filing status threshold.
+type Calculator struct {
+	Table     *Table
+	Base      Income
+	StateRate float64
+	NIIT      bool
+}
+
+// IncrementalTax is the extra tax owed from adding income on top of Base. Kinds
+// are added in the order a return stacks them: ordinary income, then
+// short-term gains, then long-term gains.
+func (c Calculator) IncrementalTax(added Income) Breakdown {
+	before := c.Tax(c.Base)
+
+	withOrdinary := c.Base.Add(Income{Ordinary: added.Ordinary})
+	afterOrdinary := c.Tax(withOrdinary)
+
+	withShortTerm := withOrdinary.Add(Income{ShortTermCapitalGains: added.ShortTermCapitalGains})
+	afterShortTerm := c.Tax(withShortTerm)
+
+	afterLongTerm := c.Tax(withShortTerm.Add(Income{LongTermCapitalGains: added.LongTermCapitalGains}))
+
+	return Breakdown{
+		Ordinary:              afterOrdinary - before,
+		ShortTermCapitalGains: afterShortTerm - afterOrdinary,
+		LongTermCapitalGains:  afterLongTerm - afterShortTerm,
+		Total:                 afterLongTerm - before,
+	}
+}
+
+// Tax is the total tax owed on income after the standard deduction.
+func (c Calculator) Tax(income Income) float64 {
+	shortTerm, longTerm, lossDeduction := c.netCapitalGains(income.ShortTermCapitalGains, income.LongTermCapitalGains)
+
+	ordinary := income.Ordinary + shortTerm - lossDeduction
+	adjustedGrossIncome := ordinary + longTerm
+
+	// The standard deduction reduces ordinary income first and only reaches
+	// long-term gains once ordinary income is used up.
+	taxableOrdinary := max(ordinary-c.Table.StandardDeduction, 0)
+	unusedDeduction := max(c.Table.StandardDeduction-max(ordinary, 0), 0)
+	taxableLongTerm := max(longTerm-unusedDeduction, 0)
+
+	total := bracketTax(c.Table.Ordinary, taxableOrdinary) +
+		bracketTax(c.Table.LongTermCapitalGains, taxableOrdinary+taxableLongTerm) -
+		bracketTax(c.Table.LongTermCapitalGains, taxableOrdinary)
+
+	if c.NIIT {
+		investmentIncome := shortTerm + longTerm
+		excess := max(adjustedGrossIncome-c.Table.NIITThreshold, 0)
+		total += NIITRate * min(investmentIncome, excess)
+	}
+
+	total += c.StateRate * max(adjustedGrossIncome, 0)
+
+	return total
+}
+
+// netCapitalGains nets short- and long-term results against each other. A net
+// loss is returned as a deduction against ordinary income, up to the limit.
+func (c Calculator) netCapitalGains(shortTerm float64, longTerm float64) (float64, float64, float64) {
+	if shortTerm >= 0 && longTerm >= 0 {
+		return shortTerm, longTerm, 0
+	}
+
+	net := shortTerm + longTerm
+	switch {
+	case net < 0:
+		limit := float64(capitalLossLimit)
+		if c.Table.FilingStatus == models.FilingStatusMarriedFilingSeparately {
+			limit = capitalLossLimitMarriedSeparately
+		}
+		return 0, 0, min(-net, limit)
+	case shortTerm < 0:
+		return 0, net, 0
+	default:
+		return net, 0, 0
+	}
+}
+
+// bracketTax applies each bracket's rate to the part of income inside it.
+func bracketTax(brackets []Bracket, income float64) float64 {
+	total := 0.0
+	for i, bracket := range brackets {
+		if income <= bracket.Floor {
+			break
+		}
+
+		top := income
+		if i+1 < len(brackets) {
+			top = min(income, brackets[i+1].Floor)
+		}
+		total += (top - bracket.Floor) * bracket.Rate
+	}
+
+	return total
+}
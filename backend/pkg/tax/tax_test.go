@@ -0,0 +1,156 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/ljhurst/fife/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func calculator(t *testing.T, year int, filingStatus string, salary float64) Calculator {
+	t.Helper()
+
+	table, err := TableFor(year, filingStatus)
+	assert.NoError(t, err)
+
+	return Calculator{Table: table, Base: Income{Ordinary: salary}}
+}
+
+func TestTax(t *testing.T) {
+	testCases := []struct {
+		name         string
+		filingStatus string
+		income       Income
+		expectedTax  float64
+	}{
+		{
+			name:         "below the standard deduction",
+			filingStatus: models.FilingStatusSingle,
+			income:       Income{Ordinary: 15000},
+			expectedTax:  0,
+		},
+		{
+			name:         "single ordinary income",
+			filingStatus: models.FilingStatusSingle,
+			income:       Income{Ordinary: 100000},
+			expectedTax:  13170,
+		},
+		{
+			name:         "married filing jointly ordinary income",
+			filingStatus: models.FilingStatusMarriedFilingJointly,
+			income:       Income{Ordinary: 100000},
+			expectedTax:  7640,
+		},
+		{
+			name:         "long-term gains use the unused deduction",
+			filingStatus: models.FilingStatusSingle,
+			income:       Income{Ordinary: 10000, LongTermCapitalGains: 20000},
+			expectedTax:  0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := calculator(t, 2026, tc.filingStatus, 0)
+
+			assert.InDelta(t, tc.expectedTax, c.Tax(tc.income), 0.005)
+		})
+	}
+}
+
+func TestIncrementalTax(t *testing.T) {
+	testCases := []struct {
+		name              string
+		salary            float64
+		niit              bool
+		stateRate         float64
+		added             Income
+		expectedBreakdown Breakdown
+	}{
+		{
+			name:              "ordinary income crossing into the 24% bracket",
+			salary:            100000,
+			added:             Income{Ordinary: 30000},
+			expectedBreakdown: Breakdown{Ordinary: 6764, Total: 6764},
+		},
+		{
+			name:              "long-term gains straddling the 0% bracket",
+			salary:            40000,
+			added:             Income{LongTermCapitalGains: 30000},
+			expectedBreakdown: Breakdown{LongTermCapitalGains: 667.5, Total: 667.5},
+		},
+		{
+			name:              "short-term loss limited to 3000",
+			salary:            100000,
+			added:             Income{ShortTermCapitalGains: -5000},
+			expectedBreakdown: Breakdown{ShortTermCapitalGains: -660, Total: -660},
+		},
+		{
+			name:              "long-term loss offsets short-term gain",
+			salary:            100000,
+			added:             Income{ShortTermCapitalGains: 4000, LongTermCapitalGains: -1000},
+			expectedBreakdown: Breakdown{ShortTermCapitalGains: 880, LongTermCapitalGains: -220, Total: 660},
+		},
+		{
+			name:              "NIIT above the threshold",
+			salary:            190000,
+			niit:              true,
+			added:             Income{LongTermCapitalGains: 20000},
+			expectedBreakdown: Breakdown{LongTermCapitalGains: 3380, Total: 3380},
+		},
+		{
+			name:              "state tax on every kind",
+			salary:            100000,
+			stateRate:         0.05,
+			added:             Income{Ordinary: 1000, LongTermCapitalGains: 1000},
+			expectedBreakdown: Breakdown{Ordinary: 270, LongTermCapitalGains: 200, Total: 470},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := calculator(t, 2026, models.FilingStatusSingle, tc.salary)
+			c.NIIT = tc.niit
+			c.StateRate = tc.stateRate
+
+			breakdown := c.IncrementalTax(tc.added)
+
+			assert.InDelta(t, tc.expectedBreakdown.Ordinary, breakdown.Ordinary, 0.005)
+			assert.InDelta(t, tc.expectedBreakdown.ShortTermCapitalGains, breakdown.ShortTermCapitalGains, 0.005)
+			assert.InDelta(t, tc.expectedBreakdown.LongTermCapitalGains, breakdown.LongTermCapitalGains, 0.005)
+			assert.InDelta(t, tc.expectedBreakdown.Total, breakdown.Total, 0.005)
+		})
+	}
+}
+
+func TestTableFor(t *testing.T) {
+	table, err := TableFor(2024, models.FilingStatusHeadOfHousehold)
+	assert.NoError(t, err)
+	assert.Equal(t, 2024, table.Year)
+	assert.Equal(t, 21900.0, table.StandardDeduction)
+	assert.Equal(t, 200000.0, table.NIITThreshold)
+
+	table, err = TableFor(2030, models.FilingStatusSingle)
+	assert.NoError(t, err)
+	assert.Equal(t, 2026, table.Year)
+
+	table, err = TableFor(2019, models.FilingStatusMarriedFilingSeparately)
+	assert.NoError(t, err)
+	assert.Equal(t, 2023, table.Year)
+
+	_, err = TableFor(2026, "joint")
+	assert.ErrorIs(t, err, ErrUnknownFilingStatus)
+}
+
+func TestTablesAreSorted(t *testing.T) {
+	for year, statuses := range tables {
+		for status, table := range statuses {
+			for _, brackets := range [][]Bracket{table.Ordinary, table.LongTermCapitalGains} {
+				for i := 1; i < len(brackets); i++ {
+					assert.Less(t, brackets[i-1].Floor, brackets[i].Floor, "%d %s", year, status)
+					assert.Less(t, brackets[i-1].Rate, brackets[i].Rate, "%d %s", year, status)
+				}
+			}
+		}
+	}
+}
@@ -17,3 +17,27 @@ func InvalidRequestBodyError() (events.APIGatewayProxyResponse, error) {
 		"error": "Invalid request body",
 	})
 }
+
+func UnauthorizedError() (events.APIGatewayProxyResponse, error) {
+	return APIResponse(401, map[string]string{
+		"error": "Unauthorized",
+	})
+}
+
+func ForbiddenError() (events.APIGatewayProxyResponse, error) {
+	return APIResponse(403, map[string]string{
+		"error": "Forbidden",
+	})
+}
+
+func InvalidQueryParameterError(paramName string) (events.APIGatewayProxyResponse, error) {
+	return APIResponse(400, map[string]string{
+		"error": fmt.Sprintf("Invalid query parameter: %s", paramName),
+	})
+}
+
+func PreconditionFailedError() (events.APIGatewayProxyResponse, error) {
+	return APIResponse(412, map[string]string{
+		"error": "Precondition failed",
+	})
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+
 	"github.com/aws/aws-lambda-go/events"
 )
 
@@ -20,3 +22,45 @@ func APIResponse(statusCode int, body interface{}) (events.APIGatewayProxyRespon
 		},
 	}, nil
 }
+
+// ETagResponse is APIResponse with an ETag header. The header is exposed to
+// browsers so the frontend can send it back in If-Match.
+func ETagResponse(statusCode int, etag string, body interface{}) (events.APIGatewayProxyResponse, error) {
+	response, err := APIResponse(statusCode, body)
+	if err != nil {
+		return response, err
+	}
+
+	response.Headers["ETag"] = etag
+	response.Headers["Access-Control-Expose-Headers"] = "ETag"
+
+	return response, nil
+}
+
+// AttachmentResponse returns body as a file download rather than JSON.
+func AttachmentResponse(contentType string, filename string, body []byte) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(body),
+		Headers: map[string]string{
+			"Content-Type":                  contentType,
+			"Content-Disposition":           fmt.Sprintf(`attachment; filename="%s"`, filename),
+			"Access-Control-Allow-Origin":   "*",
+			"Access-Control-Expose-Headers": "Content-Disposition",
+		},
+	}, nil
+}
+
+// FeedResponse returns body as-is for clients that poll a URL, like calendar
+// apps. The URL carries a secret, so shared caches must not keep the body.
+func FeedResponse(contentType string, body []byte) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(body),
+		Headers: map[string]string{
+			"Content-Type":                contentType,
+			"Cache-Control":               "private",
+			"Access-Control-Allow-Origin": "*",
+		},
+	}, nil
+}
@@ -1,3 +1,6 @@
 #!/bin/bash
 
 API_HOST="https://vxzzln3s2i.execute-api.us-east-1.amazonaws.com/prod/"
+
+# A Cognito ID or access token for the user the scripts act as
+API_TOKEN="${API_TOKEN:?Set API_TOKEN to a Cognito token}"
@@ -8,8 +8,8 @@ create_response=$(
         "$API_HOST/espp/lot" \
         -X POST \
         -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN" \
         -d '{
-            "userId": "12345",
             "grantDate": "2023-01-01",
             "purchaseDate": "2023-06-01",
             "offerStartPrice": 10.00,
@@ -29,18 +29,35 @@ get_response=$(
         -s \
         "$API_HOST/espp/lot/$lot_id" \
         -X GET \
-        -H "Content-Type: application/json"
+        -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN"
 )
 
 echo "Get ESPP Lot Response: $(echo "$get_response" | jq '.')"
 echo
 
+update_response=$(
+    curl \
+        -s \
+        "$API_HOST/espp/lot/$lot_id" \
+        -X PATCH \
+        -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN" \
+        -d '{
+            "purchasePrice": 12.75
+        }'
+)
+
+echo "Update ESPP Lot Response: $(echo "$update_response" | jq '.')"
+echo
+
 delete_response=$(
     curl \
         -s \
         "$API_HOST/espp/lot/$lot_id" \
         -X DELETE \
-        -H "Content-Type: application/json"
+        -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN"
 )
 
 echo "Delete ESPP Lot Response: $(echo "$delete_response" | jq '.')"
@@ -9,7 +9,8 @@ get_response=$(
         -s \
         "$API_HOST/user/$user_id" \
         -X GET \
-        -H "Content-Type: application/json"
+        -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN"
 )
 
 echo "Get User Response: $(echo "$get_response" | jq '.')"
@@ -20,7 +21,8 @@ espp_lot_list_response=$(
         -s \
         "$API_HOST/user/$user_id/espp-lot" \
         -X GET \
-        -H "Content-Type: application/json"
+        -H "Content-Type: application/json" \
+        -H "Authorization: Bearer $API_TOKEN"
 )
 
 echo "ESPP Lot List Response: $(echo "$espp_lot_list_response" | jq '.')"
@@ -0,0 +1,23 @@
+import { getIdToken } from '@/utils/auth';
+
+type ApiRequestInit = Omit<RequestInit, 'headers'> & {
+    headers?: Record<string, string>;
+};
+
+async function apiFetch(input: string | URL, init: ApiRequestInit = {}): Promise<Response> {
+    const idToken = await getIdToken();
+
+    if (!idToken) {
+        throw new Error('Not signed in');
+    }
+
+    return fetch(input, {
+        ...init,
+        headers: {
+            ...init.headers,
+            Authorization: `Bearer ${idToken}`,
+        },
+    });
+}
+
+export { apiFetch };
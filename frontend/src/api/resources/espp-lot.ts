@@ -1,9 +1,10 @@
 import { API_HOST, CACHE_BASE_USER_ESPP_LOT } from '@/api/constants';
+import { apiFetch } from '@/api/fetch';
 import { type ESPPPurchaseInput, type ESPPPurchaseRaw } from '@/domain/espp/espp-purchase-raw';
 import { deleteCachedItem, getCacheKey } from '@/utils/cache';
 
 async function create(userId: string, lot: ESPPPurchaseInput): Promise<ESPPPurchaseRaw> {
-    const response = await fetch(`${API_HOST}/espp/lot`, {
+    const response = await apiFetch(`${API_HOST}/espp/lot`, {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json',
@@ -32,7 +33,7 @@ async function create(userId: string, lot: ESPPPurchaseInput): Promise<ESPPPurch
 }
 
 async function remove(userId: string, lotId: string): Promise<void> {
-    const response = await fetch(`${API_HOST}/espp/lot/${lotId}`, {
+    const response = await apiFetch(`${API_HOST}/espp/lot/${lotId}`, {
         method: 'DELETE',
     });
 
@@ -45,7 +46,7 @@ async function remove(userId: string, lotId: string): Promise<void> {
 }
 
 async function get(lotId: string): Promise<ESPPPurchaseRaw> {
-    const response = await fetch(`${API_HOST}/espp/lot/${lotId}`);
+    const response = await apiFetch(`${API_HOST}/espp/lot/${lotId}`);
 
     if (response.status === 404) {
         throw new Error(`ESPP lot not found: ${lotId}`);
@@ -1,5 +1,6 @@
 import { API_HOST, CACHE_BASE_USER, CACHE_BASE_USER_ESPP_LOT } from '@/api/constants';
-import type { ESPPPurchaseRaw } from '@/domain/espp/espp-purchase-raw';
+import { apiFetch } from '@/api/fetch';
+import type { ESPPLotPageRaw, ESPPPurchaseRaw } from '@/domain/espp/espp-purchase-raw';
 import type { Settings, RawUserSettings, UserSettings } from '@/domain/user/user-settings';
 import { getCachedItem, setCachedItem, getCacheKey } from '@/utils/cache';
 
@@ -11,7 +12,7 @@ async function get(userId: string): Promise<UserSettings> {
         return parseRawUser(cachedUser);
     }
 
-    const response = await fetch(`${API_HOST}/user/${userId}`);
+    const response = await apiFetch(`${API_HOST}/user/${userId}`);
 
     if (response.status === 404) {
         throw new Error(`User not found: ${userId}`);
@@ -27,7 +28,7 @@ async function get(userId: string): Promise<UserSettings> {
 }
 
 async function update(userId: string, userSettings: Settings): Promise<UserSettings> {
-    const response = await fetch(`${API_HOST}/user/${userId}`, {
+    const response = await apiFetch(`${API_HOST}/user/${userId}`, {
         method: 'PUT',
         headers: {
             'Content-Type': 'application/json',
@@ -55,13 +56,26 @@ async function esppLotList(userId: string): Promise<ESPPPurchaseRaw[]> {
         return cachedLots;
     }
 
-    const response = await fetch(`${API_HOST}/user/${userId}/espp-lot`);
+    const rawLots: ESPPPurchaseRaw[] = [];
+    let cursor: string | undefined;
 
-    if (!response.ok) {
-        throw new Error(`Error fetching ESPP lots: ${response.statusText}`);
-    }
+    do {
+        const url = new URL(`${API_HOST}/user/${userId}/espp-lot`);
+        if (cursor) {
+            url.searchParams.set('cursor', cursor);
+        }
+
+        const response = await apiFetch(url);
+
+        if (!response.ok) {
+            throw new Error(`Error fetching ESPP lots: ${response.statusText}`);
+        }
+
+        const page = (await response.json()) as ESPPLotPageRaw;
 
-    const rawLots = await response.json();
+        rawLots.push(...page.items);
+        cursor = page.nextCursor;
+    } while (cursor);
 
     setCachedItem(cacheKey, rawLots);
 
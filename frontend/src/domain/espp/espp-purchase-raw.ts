@@ -9,6 +9,13 @@ interface ESPPPurchaseInput {
 
 interface ESPPPurchaseRaw extends ESPPPurchaseInput {
     id: string;
+    ticker?: string;
+    employer?: string;
+}
+
+interface ESPPLotPageRaw {
+    items: ESPPPurchaseRaw[];
+    nextCursor?: string;
 }
 
 const ESPP_PURCHASE_INPUT_REQUIRED_FIELDS = [
@@ -38,4 +45,4 @@ function isESPPPurchaseInputValid(purchase: ESPPPurchaseInput): boolean {
 }
 
 export { ESPP_PURCHASE_INPUT_REQUIRED_FIELDS, createESPPPurchaseInput, isESPPPurchaseInputValid };
-export type { ESPPPurchaseInput, ESPPPurchaseRaw };
+export type { ESPPLotPageRaw, ESPPPurchaseInput, ESPPPurchaseRaw };
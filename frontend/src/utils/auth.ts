@@ -44,6 +44,13 @@ async function getCurrentUser(): Promise<CurrentUser | null> {
     };
 }
 
+async function getIdToken(): Promise<string | null> {
+    const userManager = getUserManager();
+    const user = await userManager.getUser();
+
+    return user?.id_token ?? null;
+}
+
 async function isAuthenticated(): Promise<boolean> {
     const currentUser = await getCurrentUser();
 
@@ -61,4 +68,4 @@ async function signOutRedirect(): Promise<void> {
     window.location.href = `${COGNITO_DOMAIN}/logout?${clientIdParam}&${logoutUriParam}`;
 }
 
-export { getUserManager, getCurrentUser, isAuthenticated, signOutRedirect };
+export { getUserManager, getCurrentUser, getIdToken, isAuthenticated, signOutRedirect };
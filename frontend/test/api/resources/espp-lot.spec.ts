@@ -3,6 +3,7 @@ import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
 import { API_HOST } from '@/api/constants';
 import { create, remove, get } from '@/api/resources/espp-lot';
 import type { ESPPPurchaseRaw } from '@/domain/espp/espp-purchase-raw';
+import { getUserManager } from '@/utils/auth';
 
 describe('espp-lot', () => {
     const mockUserId = 'user-123';
@@ -32,6 +33,8 @@ describe('espp-lot', () => {
 
     beforeEach(() => {
         vi.resetAllMocks();
+
+        getUserManager().getUser = vi.fn().mockResolvedValue({ id_token: 'mock-id-token' });
     });
 
     afterEach(() => {
@@ -52,6 +55,7 @@ describe('espp-lot', () => {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
+                    Authorization: 'Bearer mock-id-token',
                 },
                 body: JSON.stringify({
                     userId: mockUserId,
@@ -89,6 +93,9 @@ describe('espp-lot', () => {
 
             expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/espp/lot/${mockLotId}`, {
                 method: 'DELETE',
+                headers: {
+                    Authorization: 'Bearer mock-id-token',
+                },
             });
         });
 
@@ -113,7 +120,11 @@ describe('espp-lot', () => {
 
             const result = await get(mockLotId);
 
-            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/espp/lot/${mockLotId}`);
+            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/espp/lot/${mockLotId}`, {
+                headers: {
+                    Authorization: 'Bearer mock-id-token',
+                },
+            });
             expect(result).toEqual(mockCreatedLot);
         });
 
@@ -3,6 +3,7 @@ import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
 import { API_HOST } from '@/api/constants';
 import { esppLotList, get, update } from '@/api/resources/user';
 import type { RawUserSettings, Settings } from '@/domain/user/user-settings';
+import { getUserManager } from '@/utils/auth';
 
 describe('user', () => {
     const mockUserId = 'user-123';
@@ -32,6 +33,8 @@ describe('user', () => {
 
     beforeEach(() => {
         vi.resetAllMocks();
+
+        getUserManager().getUser = vi.fn().mockResolvedValue({ id_token: 'mock-id-token' });
     });
 
     afterEach(() => {
@@ -45,7 +48,11 @@ describe('user', () => {
 
             const result = await get(mockUserId);
 
-            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/user/${mockUserId}`);
+            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/user/${mockUserId}`, {
+                headers: {
+                    Authorization: 'Bearer mock-id-token',
+                },
+            });
 
             expect(result).toEqual(mockParsedUserSettings);
 
@@ -123,6 +130,29 @@ describe('user', () => {
                 'Error updating user: Bad Request',
             );
         });
+
+        it('should send the ID token with the update', async () => {
+            global.fetch = vi.fn().mockResolvedValue(mockFetchResponse);
+
+            await update(mockUserId, mockSettings);
+
+            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/user/${mockUserId}`, {
+                method: 'PUT',
+                headers: {
+                    'Content-Type': 'application/json',
+                    Authorization: 'Bearer mock-id-token',
+                },
+                body: JSON.stringify(mockSettings),
+            });
+        });
+
+        it('should not call the API when signed out', async () => {
+            getUserManager().getUser = vi.fn().mockResolvedValue(null);
+            global.fetch = vi.fn();
+
+            await expect(update(mockUserId, mockSettings)).rejects.toThrow('Not signed in');
+            expect(global.fetch).not.toHaveBeenCalled();
+        });
     });
 
     describe('esppLotList', () => {
@@ -148,12 +178,19 @@ describe('user', () => {
         it('should fetch ESPP lots from API when not in cache', async () => {
             global.fetch = vi.fn().mockResolvedValue({
                 ok: true,
-                json: vi.fn().mockResolvedValue(mockESPPLots),
+                json: vi.fn().mockResolvedValue({ items: mockESPPLots }),
             });
 
             const result = await esppLotList(mockUserId);
 
-            expect(global.fetch).toHaveBeenCalledWith(`${API_HOST}/user/${mockUserId}/espp-lot`);
+            expect(vi.mocked(global.fetch).mock.calls[0][0].toString()).toBe(
+                `${API_HOST}/user/${mockUserId}/espp-lot`,
+            );
+            expect(global.fetch).toHaveBeenCalledWith(expect.any(URL), {
+                headers: {
+                    Authorization: 'Bearer mock-id-token',
+                },
+            });
             expect(result).toEqual(mockESPPLots);
 
             const cachedResult = await esppLotList(mockUserId);